@@ -3,9 +3,14 @@ package actionners
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"go.opentelemetry.io/otel/codes"
 
@@ -35,18 +40,32 @@ import (
 	k8sTcpdump "github.com/falcosecurity/falco-talon/actionners/kubernetes/tcpdump"
 	k8sTerminate "github.com/falcosecurity/falco-talon/actionners/kubernetes/terminate"
 	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/approvals"
+	"github.com/falcosecurity/falco-talon/internal/audit"
+	"github.com/falcosecurity/falco-talon/internal/budget"
 	talonContext "github.com/falcosecurity/falco-talon/internal/context"
+	"github.com/falcosecurity/falco-talon/internal/enforcement"
+	"github.com/falcosecurity/falco-talon/internal/escalation"
 	"github.com/falcosecurity/falco-talon/internal/events"
+	"github.com/falcosecurity/falco-talon/internal/history"
+	k8s "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
 	"github.com/falcosecurity/falco-talon/internal/nats"
 	"github.com/falcosecurity/falco-talon/internal/otlp/metrics"
+	"github.com/falcosecurity/falco-talon/internal/pause"
+	"github.com/falcosecurity/falco-talon/internal/protection"
 	"github.com/falcosecurity/falco-talon/internal/rules"
+	"github.com/falcosecurity/falco-talon/internal/schedules"
+	"github.com/falcosecurity/falco-talon/internal/suppressions"
 	"github.com/falcosecurity/falco-talon/notifiers"
 	"github.com/falcosecurity/falco-talon/utils"
 )
 
 type Actionner interface {
-	Init() error
+	Init(config *configuration.Configuration) error
 	Run(event *events.Event, action *rules.Action) (utils.LogLine, *models.Data, error)
+	// Plan resolves the targets of the action and returns the changes Run
+	// would make, without making them. It's used for the dry runs.
+	Plan(event *events.Event, action *rules.Action) (utils.LogLine, error)
 	CheckParameters(action *rules.Action) error
 	Checks(event *events.Event, action *rules.Action) error
 	Information() models.Information
@@ -56,19 +75,39 @@ type Actionner interface {
 type Actionners []Actionner
 
 var defaultActionners *Actionners
-var enabledActionners *Actionners
+
+// errPaused stops the chain of a paused action, without running its
+// 'on_failure' actions.
+var errPaused = errors.New("the action is paused")
+var enabledActionners atomic.Pointer[Actionners]
+
+const (
+	trueStr        string = "true"
+	falseStr       string = "false"
+	outputStr      string = "output"
+	eventStr       string = "event"
+	approvalStr    string = "approval"
+	scheduleStr    string = "schedule"
+	escalateStr    string = "escalation"
+	suppressionStr string = "suppression"
+	parallelStr    string = "parallel"
+)
+
+// resumption is the step from which the chain of a parked action is resumed.
+type resumption int
 
 const (
-	trueStr   string = "true"
-	falseStr  string = "false"
-	outputStr string = "output"
-	eventStr  string = "event"
+	notResumed resumption = iota
+	// afterDelay resumes a delayed action, its approval is still required.
+	afterDelay
+	// afterApproval resumes an approved action, it's run directly.
+	afterApproval
 )
 
 func init() {
 	defaultActionners = new(Actionners)
 	defaultActionners = ListDefaultActionners()
-	enabledActionners = new(Actionners)
+	enabledActionners.Store(new(Actionners))
 }
 
 func ListDefaultActionners() *Actionners {
@@ -97,15 +136,49 @@ func ListDefaultActionners() *Actionners {
 	return defaultActionners
 }
 
+// Registry is the actionners enabled for a configuration and its rules, with
+// the policies of the configuration, they're in use once published.
+type Registry struct {
+	actionners  *Actionners
+	protection  *protection.Policy
+	enforcement *enforcement.Policy
+	budgets     budget.Budgets
+}
+
+// Init initializes the actionners used by the current configuration and rules.
 func Init() error {
-	rules := rules.GetRules()
+	r, err := NewRegistry(configuration.GetConfiguration(), rules.GetRules())
+	if err != nil {
+		return err
+	}
+	r.Publish()
+	return nil
+}
+
+// NewRegistry initializes the actionners used by the rules and the policies,
+// with the configuration, without publishing them.
+func NewRegistry(config *configuration.Configuration, ruleList *[]*rules.Rule) (*Registry, error) {
+	var r Registry
+	var err error
+	if r.protection, err = protection.NewPolicy(config.Protected); err != nil {
+		utils.PrintLog(utils.ErrorStr, utils.LogLine{Message: "init", Error: err.Error(), Status: utils.FailureStr})
+		return nil, err
+	}
+	if r.budgets, err = budget.New(config.Budgets); err != nil {
+		utils.PrintLog(utils.ErrorStr, utils.LogLine{Message: "init", Error: err.Error(), Status: utils.FailureStr})
+		return nil, err
+	}
+	if r.enforcement, err = enforcement.NewPolicy(config.Enforcement); err != nil {
+		utils.PrintLog(utils.ErrorStr, utils.LogLine{Message: "init", Error: err.Error(), Status: utils.FailureStr})
+		return nil, err
+	}
 
 	categories := map[string]bool{}
 	enabledCategories := map[string]bool{}
 
 	// list actionner categories to init
-	for _, i := range *rules {
-		for _, j := range i.Actions {
+	for _, i := range *ruleList {
+		for _, j := range i.ListAllActions() {
 			categories[j.GetActionnerCategory()] = true
 		}
 	}
@@ -113,24 +186,33 @@ func Init() error {
 	for category := range categories {
 		for _, actionner := range *defaultActionners {
 			if category == actionner.Information().Category {
-				if err := actionner.Init(); err != nil {
+				if err := actionner.Init(config); err != nil {
 					utils.PrintLog(utils.ErrorStr, utils.LogLine{Message: "init", Error: err.Error(), Category: actionner.Information().Category, Status: utils.FailureStr})
-					return err
+					return nil, err
 				}
 				enabledCategories[category] = true
 			}
 		}
 	}
 
+	r.actionners = new(Actionners)
 	for i := range enabledCategories {
 		for _, j := range *defaultActionners {
 			if i == j.Information().Category {
-				enabledActionners.Add(j)
+				r.actionners.Add(j)
 			}
 		}
 	}
+	return &r, nil
+}
 
-	return nil
+// Publish makes the actionners and the policies of the registry the ones in
+// use.
+func (r *Registry) Publish() {
+	protection.Store(r.protection)
+	budget.Store(r.budgets)
+	enforcement.Store(r.enforcement)
+	enabledActionners.Store(r.actionners)
 }
 
 func (actionners *Actionners) Add(actionner ...Actionner) {
@@ -140,7 +222,7 @@ func (actionners *Actionners) Add(actionner ...Actionner) {
 }
 
 func ListActionners() *Actionners {
-	return enabledActionners
+	return enabledActionners.Load()
 }
 
 func (actionners Actionners) FindActionner(fullname string) Actionner {
@@ -159,27 +241,61 @@ func (actionners Actionners) FindActionner(fullname string) Actionner {
 	return nil
 }
 
-func runAction(mctx context.Context, rule *rules.Rule, action *rules.Action, event *events.Event) (err error) {
+func runAction(mctx context.Context, ruleSet *rules.RuleSet, rule *rules.Rule, action *rules.Action, event *events.Event) (log utils.LogLine, data *models.Data, err error) {
 	tracer := traces.GetTracer()
 
+	log = utils.LogLine{
+		Message:       "action",
+		Rule:          rule.GetName(),
+		Event:         event.Output,
+		Action:        action.GetName(),
+		Actionner:     action.GetActionner(),
+		TraceID:       event.TraceID,
+		Mode:          enforcement.Mode(rule, action),
+		Cluster:       k8s.ClusterOf(event),
+		RulesVersion:  fmt.Sprintf("%v", ruleSet.Version),
+		RulesChecksum: ruleSet.ShortChecksum(),
+	}
+
 	actionners := ListActionners()
 	if actionners == nil {
-		return nil
+		return log, data, nil
 	}
 
-	log := utils.LogLine{
-		Message:   "action",
-		Rule:      rule.GetName(),
-		Event:     event.Output,
-		Action:    action.GetName(),
-		Actionner: action.GetActionner(),
-		TraceID:   event.TraceID,
+	// the results are kept in the history, with the dry runs and the
+	// observed actions
+	var outputLog *utils.LogLine
+	defer func() {
+		history.Record(event, log, outputLog, log.Mode != enforcement.EnforceStr)
+	}()
+
+	switch log.Mode {
+	case enforcement.DryRunStr:
+		log = planAction(mctx, rule, action, event, actionners, log)
+		return log, data, err
+	case enforcement.ObserveStr:
+		log = observeAction(mctx, rule, action, event, log)
+		return log, data, err
 	}
 
-	if rule.DryRun == trueStr {
-		log.Output = "no action, dry-run is enabled"
-		utils.PrintLog(utils.InfoStr, log)
-		return err
+	// the attempts are audited with their final results, the output too if
+	// there's one
+	startedAt := time.Now()
+	var outputStartedAt time.Time
+	defer func() {
+		audit.Action(startedAt, rule, action, event, log)
+		if outputLog != nil {
+			audit.Output(outputStartedAt, rule, action, event, *outputLog)
+		}
+	}()
+
+	if reason := pause.Check(rule.GetName(), action); reason != "" {
+		log.Status = utils.SkippedStr
+		log.Output = fmt.Sprintf("paused, %v", reason)
+		utils.PrintLog(utils.WarningStr, log)
+		metrics.IncreaseCounter(log)
+		go notifiers.Notify(mctx, rule, action, event, log)
+		return log, data, errPaused
 	}
 
 	actionner := actionners.FindActionner(action.GetActionner())
@@ -187,7 +303,7 @@ func runAction(mctx context.Context, rule *rules.Rule, action *rules.Action, eve
 		log.Status = utils.FailureStr
 		log.Error = fmt.Sprintf("unknown actionner '%v'", action.GetActionner())
 		utils.PrintLog(utils.ErrorStr, log)
-		return fmt.Errorf("unknown actionner '%v'", action.GetActionner())
+		return log, data, fmt.Errorf("unknown actionner '%v'", action.GetActionner())
 	}
 
 	// _, span := tracer.Start(mctx, "checks",
@@ -200,12 +316,35 @@ func runAction(mctx context.Context, rule *rules.Rule, action *rules.Action, eve
 		span.SetStatus(codes.Error, err2.Error())
 		span.RecordError(err2)
 		span.End()
-		return err2
+		return log, data, err2
 	}
 	span.SetStatus(codes.Ok, "all checks passed")
 	span.AddEvent("all checks passed")
 	span.End()
 
+	_, span = tracer.Start(mctx, "protection")
+	if reason := protection.Check(event, action); reason != "" {
+		log.Status = utils.VetoedStr
+		log.Error = reason
+		utils.PrintLog(utils.WarningStr, log)
+		metrics.IncreaseCounter(log)
+		span.SetStatus(codes.Error, reason)
+		span.End()
+		go notifiers.Notify(mctx, rule, action, event, log)
+		return log, data, errors.New(reason)
+	}
+	span.End()
+
+	usage, reason := budget.Consume(event, action)
+	if reason != "" {
+		log.Status = utils.ThrottledStr
+		log.Error = reason
+		utils.PrintLog(utils.WarningStr, log)
+		metrics.IncreaseCounter(log)
+		go notifiers.Notify(mctx, rule, action, event, log)
+		return log, data, errors.New(reason)
+	}
+
 	var cont bool
 	if action.Continue != "" {
 		cont, _ = strconv.ParseBool(action.Continue) // can't trigger an error, cause the value is validated before
@@ -225,6 +364,9 @@ func runAction(mctx context.Context, rule *rules.Rule, action *rules.Action, eve
 		trace.WithAttributes(attribute.Bool("action.ignore_errors", ignoreErr)),
 		trace.WithAttributes(attribute.String("actionner.Information().Category", action.GetActionnerCategory())),
 		trace.WithAttributes(attribute.String("actionner.name", action.GetActionnerName())),
+		trace.WithAttributes(attribute.Int64("rules.version", int64(ruleSet.Version))), // #nosec G115 -- the version is a counter of reloads
+		trace.WithAttributes(attribute.String("rules.checksum", ruleSet.Checksum)),
+		trace.WithAttributes(attribute.String("action.mode", log.Mode)),
 	)
 	defer span.End()
 
@@ -233,6 +375,8 @@ func runAction(mctx context.Context, rule *rules.Rule, action *rules.Action, eve
 	utils.PrintLog(utils.InfoStr, logP)
 
 	result, data, err := actionner.Run(event, action)
+	// the failed actions are given back to the budgets
+	usage.End(err != nil || result.Error != "")
 	span.SetAttributes(attribute.String("action.result", result.Status))
 	span.SetAttributes(attribute.String("action.output", result.Output))
 
@@ -266,7 +410,7 @@ func runAction(mctx context.Context, rule *rules.Rule, action *rules.Action, eve
 		span.RecordError(err)
 		utils.PrintLog(utils.ErrorStr, log)
 		go notifiers.Notify(actx, rule, action, event, log)
-		return err
+		return log, data, err
 	}
 	log.Status = utils.SuccessStr
 	span.AddEvent(result.Output)
@@ -279,10 +423,13 @@ func runAction(mctx context.Context, rule *rules.Rule, action *rules.Action, eve
 		octx, span := tracer.Start(actx, outputStr)
 
 		logO := utils.LogLine{
-			Message: outputStr,
-			Action:  action.GetName(),
-			TraceID: event.TraceID,
+			Message:       outputStr,
+			Action:        action.GetName(),
+			TraceID:       event.TraceID,
+			RulesVersion:  log.RulesVersion,
+			RulesChecksum: log.RulesChecksum,
 		}
+		outputLog, outputStartedAt = &logO, time.Now()
 
 		if output == nil {
 			err = fmt.Errorf("an output is required")
@@ -295,7 +442,7 @@ func runAction(mctx context.Context, rule *rules.Rule, action *rules.Action, eve
 			span.RecordError(err)
 			go notifiers.Notify(octx, rule, action, event, logO)
 			span.End()
-			return err
+			return log, data, err
 		}
 
 		if data == nil || len(data.Bytes) == 0 {
@@ -308,7 +455,7 @@ func runAction(mctx context.Context, rule *rules.Rule, action *rules.Action, eve
 			span.RecordError(err)
 			go notifiers.Notify(octx, rule, action, event, logO)
 			span.End()
-			return err
+			return log, data, err
 		}
 
 		target := output.GetTarget()
@@ -325,7 +472,7 @@ func runAction(mctx context.Context, rule *rules.Rule, action *rules.Action, eve
 			span.RecordError(err)
 			go notifiers.Notify(octx, rule, action, event, logO)
 			span.End()
-			return err
+			return log, data, err
 		}
 
 		logO.Category = o.Information().Category
@@ -344,7 +491,7 @@ func runAction(mctx context.Context, rule *rules.Rule, action *rules.Action, eve
 			span.RecordError(err2)
 			go notifiers.Notify(octx, rule, action, event, logO)
 			span.End()
-			return err2
+			return log, data, err2
 		}
 
 		result, err = o.Run(output, data)
@@ -369,7 +516,7 @@ func runAction(mctx context.Context, rule *rules.Rule, action *rules.Action, eve
 			span.RecordError(err)
 			go notifiers.Notify(octx, rule, action, event, logO)
 			span.End()
-			return err
+			return log, data, err
 		}
 		span.SetStatus(codes.Ok, "output successfully completed")
 		span.AddEvent(result.Output)
@@ -377,18 +524,21 @@ func runAction(mctx context.Context, rule *rules.Rule, action *rules.Action, eve
 		utils.PrintLog(utils.InfoStr, logO)
 		go notifiers.Notify(octx, rule, action, event, logO)
 		span.End()
-		return nil
+		return log, data, nil
 	}
 
 	if actionner.Information().AllowOutput && output != nil && data != nil {
 		octx, span := tracer.Start(actx, outputStr)
 
 		logO := utils.LogLine{
-			Message: outputStr,
-			Rule:    rule.GetName(),
-			Action:  action.GetName(),
-			TraceID: event.TraceID,
+			Message:       outputStr,
+			Rule:          rule.GetName(),
+			Action:        action.GetName(),
+			TraceID:       event.TraceID,
+			RulesVersion:  log.RulesVersion,
+			RulesChecksum: log.RulesChecksum,
 		}
+		outputLog, outputStartedAt = &logO, time.Now()
 
 		target := output.GetTarget()
 		o := outputs.GetOutputs().FindOutput(target)
@@ -403,7 +553,7 @@ func runAction(mctx context.Context, rule *rules.Rule, action *rules.Action, eve
 			span.RecordError(err)
 			go notifiers.Notify(octx, rule, action, event, logO)
 			span.End()
-			return err
+			return log, data, err
 		}
 
 		logO.OutputTarget = target
@@ -423,7 +573,7 @@ func runAction(mctx context.Context, rule *rules.Rule, action *rules.Action, eve
 			span.RecordError(err)
 			go notifiers.Notify(octx, rule, action, event, logO)
 			span.End()
-			return err
+			return log, data, err
 		}
 
 		result, err = o.Run(output, data)
@@ -448,7 +598,7 @@ func runAction(mctx context.Context, rule *rules.Rule, action *rules.Action, eve
 			span.RecordError(err)
 			go notifiers.Notify(octx, rule, action, event, logO)
 			span.End()
-			return err
+			return log, data, err
 		}
 		span.SetStatus(codes.Ok, "output successfully completed")
 		span.AddEvent(result.Output)
@@ -456,17 +606,16 @@ func runAction(mctx context.Context, rule *rules.Rule, action *rules.Action, eve
 		utils.PrintLog(utils.InfoStr, logO)
 		go notifiers.Notify(octx, rule, action, event, logO)
 		span.End()
-		return nil
+		return log, data, nil
 	}
 
-	return nil
+	return log, data, nil
 }
 
 func StartConsumer(eventsC <-chan nats.MessageWithContext) {
-	config := configuration.GetConfiguration()
 	for {
 		m := <-eventsC
-		handleEvent(config, m)
+		handleEvent(configuration.GetConfiguration(), m)
 	}
 }
 
@@ -504,9 +653,11 @@ func handleEvent(config *configuration.Configuration, m nats.MessageWithContext)
 		TraceID:  event.TraceID,
 	}
 
-	enabledRules := rules.GetRules()
+	// the same snapshot of the rules is used for the whole event, even if a
+	// reload happens in the meantime
+	ruleSet := rules.GetRuleSet()
 	triggeredRules := make([]*rules.Rule, 0)
-	for _, i := range *enabledRules {
+	for _, i := range ruleSet.Rules {
 		if i.CompareRule(event) {
 			triggeredRules = append(triggeredRules, i)
 		}
@@ -523,6 +674,8 @@ func handleEvent(config *configuration.Configuration, m nats.MessageWithContext)
 	for _, i := range triggeredRules {
 		log.Message = "match"
 		log.Rule = i.GetName()
+		log.RulesVersion = fmt.Sprintf("%v", ruleSet.Version)
+		log.RulesChecksum = ruleSet.ShortChecksum()
 
 		tracer := traces.GetTracer()
 		mctx, span := tracer.Start(ectx, "match",
@@ -532,6 +685,8 @@ func handleEvent(config *configuration.Configuration, m nats.MessageWithContext)
 			trace.WithAttributes(attribute.String("event.trace_id", event.TraceID)),
 			trace.WithAttributes(attribute.String("rule.name", i.GetName())),
 			trace.WithAttributes(attribute.String("rule.description", i.GetDescription())),
+			trace.WithAttributes(attribute.Int64("rules.version", int64(ruleSet.Version))), // #nosec G115 -- the version is a counter of reloads
+			trace.WithAttributes(attribute.String("rules.checksum", ruleSet.Checksum)),
 		)
 		span.AddEvent(event.Output, trace.EventOption(trace.WithTimestamp(event.Time)))
 		span.SetStatus(codes.Ok, "match detected")
@@ -540,44 +695,693 @@ func handleEvent(config *configuration.Configuration, m nats.MessageWithContext)
 		utils.PrintLog(utils.InfoStr, log)
 		metrics.IncreaseCounter(log)
 
-		for _, a := range i.GetActions() {
-			e := new(events.Event)
-			*e = *event
-			i.AddFalcoTalonContext(e, a)
-			if ListDefaultActionners().FindActionner(a.GetActionner()).Information().UseContext &&
-				len(a.GetAdditionalContexts()) != 0 {
-				for _, j := range a.GetAdditionalContexts() {
-					elements, err := talonContext.GetContext(mctx, j, e)
-					if err != nil {
-						log := utils.LogLine{
-							Message:   "context",
-							Context:   j,
-							Rule:      e.Rule,
-							Action:    a.GetName(),
-							Actionner: a.GetActionner(),
-							TraceID:   e.TraceID,
-							Error:     err.Error(),
-						}
-						utils.PrintLog(utils.ErrorStr, log)
-						if a.IgnoreErrors != trueStr {
-							break
-						}
-					} else {
-						e.AddContext(elements)
-					}
-				}
+		if reason := suppressions.Check(i.GetName(), event); reason != "" {
+			suppressed := log
+			suppressed.Message = suppressionStr
+			suppressed.Status = utils.SkippedStr
+			suppressed.Output = reason
+			utils.PrintLog(utils.InfoStr, suppressed)
+			metrics.IncreaseCounter(suppressed)
+		} else {
+			runActions(mctx, ruleSet, i, event, 0, notResumed, nil)
+		}
+
+		if i.Continue == falseStr {
+			break
+		}
+	}
+}
+
+// planAction resolves the changes the action would make, for the dry runs.
+// The plans are notified to be reviewed before the rule is enforced, nothing
+// is changed. The action is checked like in enforce mode first, the plan
+// reports the pause, the failed checks, the veto or the exhausted budget which
+// would stop it, no budget is taken.
+func planAction(mctx context.Context, rule *rules.Rule, action *rules.Action, event *events.Event, actionners *Actionners, log utils.LogLine) utils.LogLine {
+	actionner := actionners.FindActionner(action.GetActionner())
+	if actionner == nil {
+		log.Status = utils.FailureStr
+		log.Error = fmt.Sprintf("unknown actionner '%v'", action.GetActionner())
+		utils.PrintLog(utils.ErrorStr, log)
+		return log
+	}
+
+	_, span := traces.GetTracer().Start(mctx, "plan",
+		trace.WithAttributes(attribute.String("action.name", action.GetName())),
+		trace.WithAttributes(attribute.String("action.actionner", action.GetActionner())),
+		trace.WithAttributes(attribute.String("action.mode", log.Mode)),
+	)
+	defer span.End()
+
+	var level string
+	if reason := pause.Check(rule.GetName(), action); reason != "" {
+		log.Status = utils.SkippedStr
+		log.Output = fmt.Sprintf("paused, %v", reason)
+		level = utils.WarningStr
+	} else if err := actionner.Checks(event, action); err != nil {
+		log.Status = utils.FailureStr
+		log.Error = err.Error()
+		level = utils.ErrorStr
+	} else if reason := protection.Check(event, action); reason != "" {
+		log.Status = utils.VetoedStr
+		log.Error = reason
+		level = utils.WarningStr
+	} else if reason := budget.Check(event, action); reason != "" {
+		log.Status = utils.ThrottledStr
+		log.Error = reason
+		level = utils.WarningStr
+	}
+	if level != "" {
+		utils.PrintLog(level, log)
+		span.SetStatus(codes.Error, log.Status)
+		metrics.IncreaseCounter(log)
+		go notifiers.Notify(mctx, rule, action, event, log)
+		return log
+	}
+
+	result, err := actionner.Plan(event, action)
+	log.Objects = result.Objects
+	log.Output = result.Output
+	log.Result = result.Result
+	log.Status = utils.PlannedStr
+	if err != nil {
+		log.Status = utils.FailureStr
+		log.Error = err.Error()
+		utils.PrintLog(utils.ErrorStr, log)
+		span.SetStatus(codes.Error, err.Error())
+		span.RecordError(err)
+	} else {
+		if log.Output == "" {
+			log.Output = "no change"
+		}
+		utils.PrintLog(utils.InfoStr, log)
+		span.SetStatus(codes.Ok, "action planned")
+	}
+	metrics.IncreaseCounter(log)
+	go notifiers.Notify(mctx, rule, action, event, log)
+	return log
+}
+
+// observeAction reports the action which would be run, its targets are not
+// resolved.
+func observeAction(mctx context.Context, rule *rules.Rule, action *rules.Action, event *events.Event, log utils.LogLine) utils.LogLine {
+	_, span := traces.GetTracer().Start(mctx, "observe",
+		trace.WithAttributes(attribute.String("action.name", action.GetName())),
+		trace.WithAttributes(attribute.String("action.actionner", action.GetActionner())),
+		trace.WithAttributes(attribute.String("action.mode", log.Mode)),
+	)
+	defer span.End()
+
+	log.Status = utils.ObservedStr
+	log.Output = "no action, the actions are observed only"
+	utils.PrintLog(utils.InfoStr, log)
+	metrics.IncreaseCounter(log)
+	go notifiers.Notify(mctx, rule, action, event, log)
+	return log
+}
+
+// runActions runs the chain of actions of the rule, from the action at index
+// start. A delayed action or an action requiring an approval parks the rest of
+// the chain, which is resumed by ResumeSchedule or ResumeApproval; from is the
+// step from which the action at index start is resumed. The results of the
+// actions already run are used by the 'when' conditions. The 'finally' actions
+// are run once the chain is over, not when it's parked.
+func runActions(mctx context.Context, ruleSet *rules.RuleSet, rule *rules.Rule, event *events.Event, start int, from resumption, results rules.Results) {
+	if results == nil {
+		results = rules.Results{}
+	}
+	actions := rule.GetActions()
+	// only the actions of the escalation step are run, the step of a resumed
+	// chain is the one of its action
+	var step *rules.EscalationStep
+	switch {
+	case rule.GetEscalation() == nil:
+	case start == 0 && from == notResumed:
+		step = escalate(mctx, ruleSet, rule, event)
+		if step == nil {
+			return
+		}
+	default:
+		step = actions[start].GetStep()
+	}
+	var last *rules.ActionResult
+	for n := start; n < len(actions); n++ {
+		a := actions[n]
+		if a.GetStep() != step {
+			continue
+		}
+		var previous *rules.ActionResult
+		if n > 0 && actions[n-1].GetStep() == step {
+			previous = results[actions[n-1].GetName()]
+		}
+		if group := a.GetParallel(); group != nil {
+			m := n
+			for m < len(actions) && actions[m].GetParallel() == group {
+				m++
 			}
-			err := runAction(mctx, i, a, e)
-			if err != nil && a.IgnoreErrors != trueStr {
+			cont := runParallel(mctx, ruleSet, rule, event, actions[n:m], previous, results)
+			last = results[actions[m-1].GetName()]
+			if !cont {
 				break
 			}
-			if a.Continue == falseStr || a.Continue != trueStr && !ListDefaultActionners().FindActionner(a.GetActionner()).Information().Continue {
-				break
+			n = m - 1
+			continue
+		}
+		resumed := notResumed
+		if n == start {
+			resumed = from
+		}
+		if resumed == notResumed && !a.GetCondition().Evaluate(event, results, previous) {
+			results[a.GetName()] = skipAction(ruleSet, rule, a, event)
+			last = results[a.GetName()]
+			continue
+		}
+		if a.GetDelay() != 0 && enforcement.Mode(rule, a) == enforcement.EnforceStr && resumed == notResumed {
+			log, parked := scheduleAction(mctx, ruleSet, rule, n, event, results)
+			if parked {
+				return
 			}
+			results[a.GetName()] = newActionResult(log, nil)
+			last = results[a.GetName()]
+			break
+		}
+		if a.RequireApproval != nil && enforcement.Mode(rule, a) == enforcement.EnforceStr && resumed != afterApproval {
+			log, parked := requestApproval(mctx, ruleSet, rule, n, event, results)
+			if parked {
+				return
+			}
+			results[a.GetName()] = newActionResult(log, nil)
+			last = results[a.GetName()]
+			break
 		}
 
-		if i.Continue == falseStr {
+		stop, err := runActionWithFallbacks(mctx, ruleSet, rule, a, event, previous, results)
+		last = results[a.GetName()]
+		if stop || stopsTheChain(a, err) {
 			break
 		}
 	}
+	runFinally(mctx, ruleSet, rule, event, last, results)
+}
+
+// escalate counts the offense of the event and returns the escalation step to
+// run, nil if no step is reached yet.
+func escalate(mctx context.Context, ruleSet *rules.RuleSet, rule *rules.Rule, event *events.Event) *rules.EscalationStep {
+	e := rule.GetEscalation()
+	log := utils.LogLine{
+		Message:       escalateStr,
+		Rule:          rule.GetName(),
+		Event:         event.Output,
+		TraceID:       event.TraceID,
+		RulesVersion:  fmt.Sprintf("%v", ruleSet.Version),
+		RulesChecksum: ruleSet.ShortChecksum(),
+	}
+
+	key, err := escalation.Key(e, event)
+	if errors.Is(err, escalation.ErrNoKey) {
+		// the offenses of all the events without key would be counted together
+		log.Status = utils.SkippedStr
+		log.Error = fmt.Sprintf("can't count the offense by %v, no escalation step is run: %v", e.GetKey(), err)
+		utils.PrintLog(utils.WarningStr, log)
+		return nil
+	}
+	if err != nil {
+		utils.PrintLog(utils.WarningStr, utils.LogLine{Message: escalateStr, Rule: rule.GetName(), TraceID: event.TraceID,
+			Error: fmt.Sprintf("can't resolve the %v of the event, the offenses are counted by '%v': %v", e.GetKey(), key, err)})
+	}
+	// the offenses of the rules not enforced are counted apart, to not
+	// escalate faster once they're enforced
+	namespace := key
+	if mode := escalationMode(rule); mode != enforcement.EnforceStr {
+		namespace = mode + "/" + key
+	}
+	offenses := escalation.GetStore().Record(rule.GetName(), namespace, e.GetDecay(), time.Now())
+
+	log.Objects = map[string]string{e.GetKey(): key, "offenses": strconv.Itoa(offenses)}
+	step := e.GetStep(offenses)
+	if step == nil {
+		log.Status = utils.SkippedStr
+		log.Output = fmt.Sprintf("offense %v of '%v' within %v, no escalation step is reached", offenses, key, e.GetDecay())
+		utils.PrintLog(utils.InfoStr, log)
+		return nil
+	}
+	log.Status = utils.SuccessStr
+	log.Output = fmt.Sprintf("offense %v of '%v' within %v, the step from the offense %v is run", offenses, key, e.GetDecay(), step.Offense)
+	utils.PrintLog(utils.InfoStr, log)
+	span := trace.SpanFromContext(mctx)
+	span.SetAttributes(attribute.Int("escalation.offenses", offenses), attribute.Int("escalation.step", step.Offense))
+	return step
+}
+
+// escalationMode returns the least restrictive mode of the actions of the
+// escalation steps of the rule, enforce if one of them is enforced.
+func escalationMode(rule *rules.Rule) string {
+	mode := len(enforcement.Modes) - 1
+	for _, i := range rule.GetActions() {
+		mode = min(mode, slices.Index(enforcement.Modes, enforcement.Mode(rule, i)))
+	}
+	return enforcement.Modes[mode]
+}
+
+// runActionWithFallbacks runs the action and records its result, its
+// 'on_failure' actions are run if it fails. The error is the one of the action,
+// nil if the failure is recovered by the 'on_failure' actions. stop is true if
+// one of the 'on_failure' actions stops the chain.
+func runActionWithFallbacks(mctx context.Context, ruleSet *rules.RuleSet, rule *rules.Rule, a *rules.Action, event *events.Event, previous *rules.ActionResult, results rules.Results) (stop bool, err error) {
+	e := newActionEvent(mctx, rule, a, event, previous)
+	log, data, err := runAction(mctx, ruleSet, rule, a, e)
+	results[a.GetName()] = newActionResult(log, data)
+	if err == nil || len(a.OnFailure) == 0 || errors.Is(err, errPaused) {
+		return false, err
+	}
+	recovered, stop := runFallbacks(mctx, ruleSet, rule, a, event, results)
+	if recovered {
+		return stop, nil
+	}
+	return stop, err
+}
+
+// runFallbacks runs the 'on_failure' actions of the failed action in sequence,
+// with the same rules as the chain. recovered is true if none of them failed
+// without 'ignore_errors', stop is true if one of them stops the chain, the
+// next 'on_failure' actions and the rest of the chain are not run then.
+func runFallbacks(mctx context.Context, ruleSet *rules.RuleSet, rule *rules.Rule, failed *rules.Action, event *events.Event, results rules.Results) (recovered, stop bool) {
+	utils.PrintLog(utils.InfoStr, utils.LogLine{
+		Message:   "action",
+		Rule:      rule.GetName(),
+		Event:     event.Output,
+		Action:    failed.GetName(),
+		Actionner: failed.GetActionner(),
+		TraceID:   event.TraceID,
+		Output:    fmt.Sprintf("the action failed, running its %v 'on_failure' action(s)", len(failed.OnFailure)),
+	})
+	previous := results[failed.GetName()]
+	for _, a := range failed.OnFailure {
+		if !a.GetCondition().Evaluate(event, results, previous) {
+			results[a.GetName()] = skipAction(ruleSet, rule, a, event)
+			previous = results[a.GetName()]
+			continue
+		}
+		stop, err := runActionWithFallbacks(mctx, ruleSet, rule, a, event, previous, results)
+		previous = results[a.GetName()]
+		if err != nil && a.IgnoreErrors != trueStr {
+			return false, stop
+		}
+		if stop || stopsTheChain(a, nil) {
+			return true, true
+		}
+	}
+	return true, false
+}
+
+// runFinally runs the 'finally' actions of the rule, whatever the outcome of
+// the chain. They are all run, in sequence, even if one of them fails; last is
+// the result of the last action of the chain.
+func runFinally(mctx context.Context, ruleSet *rules.RuleSet, rule *rules.Rule, event *events.Event, last *rules.ActionResult, results rules.Results) {
+	previous := last
+	for _, a := range rule.GetFinally() {
+		if !a.GetCondition().Evaluate(event, results, previous) {
+			results[a.GetName()] = skipAction(ruleSet, rule, a, event)
+		} else {
+			_, _ = runActionWithFallbacks(mctx, ruleSet, rule, a, event, previous, results)
+		}
+		previous = results[a.GetName()]
+	}
+}
+
+// runParallel runs the actions of a parallel group concurrently, each one in
+// its own span, and waits for all of them until the timeout of the group. The
+// actions still running at the timeout are considered as failed. It returns
+// false if the chain stops after the group.
+func runParallel(mctx context.Context, ruleSet *rules.RuleSet, rule *rules.Rule, event *events.Event, group []*rules.Action, previous *rules.ActionResult, results rules.Results) bool {
+	type branch struct {
+		result *rules.ActionResult
+		err    error
+		n      int
+	}
+
+	tracer := traces.GetTracer()
+	timeout := group[0].GetParallel().GetTimeout()
+	done := make(chan branch, len(group)) // buffered, for the actions ending after the timeout
+	running := make(map[int]bool, len(group))
+	for n, a := range group {
+		// the actions of the group see the results of the actions before the group only
+		if !a.GetCondition().Evaluate(event, results, previous) {
+			results[a.GetName()] = skipAction(ruleSet, rule, a, event)
+			continue
+		}
+		running[n] = true
+		e := newActionEvent(mctx, rule, a, event, previous)
+		go func() {
+			bctx, span := tracer.Start(mctx, parallelStr,
+				trace.WithAttributes(attribute.String("action.name", a.GetName())),
+				trace.WithAttributes(attribute.Int("parallel.branch", n)),
+				trace.WithAttributes(attribute.String("parallel.timeout", timeout.String())),
+			)
+			defer span.End()
+			log, data, err := runAction(bctx, ruleSet, rule, a, e)
+			if err != nil {
+				span.SetStatus(codes.Error, err.Error())
+			} else {
+				span.SetStatus(codes.Ok, "branch completed")
+			}
+			done <- branch{result: newActionResult(log, data), err: err, n: n}
+		}()
+	}
+
+	cont := true
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for len(running) != 0 {
+		select {
+		case b := <-done:
+			delete(running, b.n)
+			results[group[b.n].GetName()] = b.result
+			if stopsTheChain(group[b.n], b.err) {
+				cont = false
+			}
+		case <-timer.C:
+			for n := range running {
+				a := group[n]
+				log := utils.LogLine{
+					Message:       "action",
+					Rule:          rule.GetName(),
+					Event:         event.Output,
+					Action:        a.GetName(),
+					Actionner:     a.GetActionner(),
+					TraceID:       event.TraceID,
+					Status:        utils.FailureStr,
+					Error:         fmt.Sprintf("the parallel group timed out after %v", timeout),
+					RulesVersion:  fmt.Sprintf("%v", ruleSet.Version),
+					RulesChecksum: ruleSet.ShortChecksum(),
+				}
+				utils.PrintLog(utils.ErrorStr, log)
+				metrics.IncreaseCounter(log)
+				go notifiers.Notify(mctx, rule, a, event, log)
+				results[a.GetName()] = newActionResult(log, nil)
+				if a.IgnoreErrors != trueStr {
+					cont = false
+				}
+			}
+			return cont
+		}
+	}
+	return cont
+}
+
+// newActionEvent returns a copy of the event for the action, with the context
+// of falco-talon, of the previous action and the additional contexts.
+func newActionEvent(mctx context.Context, rule *rules.Rule, a *rules.Action, event *events.Event, previous *rules.ActionResult) *events.Event {
+	e := new(events.Event)
+	*e = *event
+	e.Context = maps.Clone(event.Context)
+	rule.AddFalcoTalonContext(e, a)
+	previous.AddPreviousContext(e)
+	if len(a.GetAdditionalContexts()) != 0 &&
+		ListDefaultActionners().FindActionner(a.GetActionner()).Information().UseContext {
+		for _, j := range a.GetAdditionalContexts() {
+			elements, err := talonContext.GetContext(mctx, j, e)
+			if err != nil {
+				log := utils.LogLine{
+					Message:   "context",
+					Context:   j,
+					Rule:      e.Rule,
+					Action:    a.GetName(),
+					Actionner: a.GetActionner(),
+					TraceID:   e.TraceID,
+					Error:     err.Error(),
+				}
+				utils.PrintLog(utils.ErrorStr, log)
+				if a.IgnoreErrors != trueStr {
+					break
+				}
+			} else {
+				e.AddContext(elements)
+			}
+		}
+	}
+	return e
+}
+
+// stopsTheChain returns true if the chain of actions stops after the action,
+// because of its error or of its 'continue' setting.
+func stopsTheChain(a *rules.Action, err error) bool {
+	if err != nil && a.IgnoreErrors != trueStr {
+		return true
+	}
+	return a.Continue == falseStr || a.Continue != trueStr && !ListDefaultActionners().FindActionner(a.GetActionner()).Information().Continue
+}
+
+// skipAction logs an action not run because its 'when' condition is false.
+func skipAction(ruleSet *rules.RuleSet, rule *rules.Rule, action *rules.Action, event *events.Event) *rules.ActionResult {
+	log := utils.LogLine{
+		Message:       "action",
+		Rule:          rule.GetName(),
+		Event:         event.Output,
+		Action:        action.GetName(),
+		Actionner:     action.GetActionner(),
+		TraceID:       event.TraceID,
+		Status:        utils.SkippedStr,
+		Output:        fmt.Sprintf("the condition '%v' is false", action.When),
+		RulesVersion:  fmt.Sprintf("%v", ruleSet.Version),
+		RulesChecksum: ruleSet.ShortChecksum(),
+	}
+	utils.PrintLog(utils.InfoStr, log)
+	metrics.IncreaseCounter(log)
+	return &rules.ActionResult{Action: action.GetName(), Status: utils.SkippedStr}
+}
+
+func newActionResult(log utils.LogLine, data *models.Data) *rules.ActionResult {
+	return &rules.ActionResult{
+		Action:  log.Action,
+		Data:    data,
+		Status:  log.Status,
+		Output:  log.Output,
+		Error:   log.Error,
+		Objects: log.Objects,
+	}
+}
+
+// requestApproval parks the action at index n and sends the approval request
+// through the notifiers.
+func requestApproval(mctx context.Context, ruleSet *rules.RuleSet, rule *rules.Rule, n int, event *events.Event, results rules.Results) (log utils.LogLine, parked bool) {
+	action := rule.GetActions()[n]
+	log = utils.LogLine{
+		Message:       approvalStr,
+		Rule:          rule.GetName(),
+		Event:         event.Output,
+		Action:        action.GetName(),
+		Actionner:     action.GetActionner(),
+		TraceID:       event.TraceID,
+		RulesVersion:  fmt.Sprintf("%v", ruleSet.Version),
+		RulesChecksum: ruleSet.ShortChecksum(),
+	}
+
+	store := approvals.GetStore()
+	if store == nil {
+		log.Status = utils.FailureStr
+		log.Error = "the approvals are not available, the action is not run"
+		utils.PrintLog(utils.ErrorStr, log)
+		go notifiers.Notify(mctx, rule, action, event, log)
+		return log, false
+	}
+
+	now := time.Now()
+	approval := &approvals.Approval{
+		CreatedAt:       now,
+		ExpiresAt:       now.Add(action.RequireApproval.GetTimeout()),
+		Event:           event,
+		Rule:            rule.GetName(),
+		Action:          action.GetName(),
+		Actionner:       action.GetActionner(),
+		DefaultDecision: action.RequireApproval.GetDefaultDecision(),
+		RulesVersion:    log.RulesVersion,
+		RulesChecksum:   log.RulesChecksum,
+		Results:         results,
+		ActionIndex:     n,
+	}
+	if err := store.Add(approval); err != nil {
+		log.Status = utils.FailureStr
+		log.Error = err.Error()
+		utils.PrintLog(utils.ErrorStr, log)
+		go notifiers.Notify(mctx, rule, action, event, log)
+		return log, false
+	}
+
+	log.Status = utils.PendingStr
+	log.Objects = map[string]string{"approval_id": approval.ID}
+	log.Output = fmt.Sprintf("waiting for an approval until %v, the default decision is '%v'", approval.ExpiresAt.Format(time.RFC3339), approval.DefaultDecision)
+	utils.PrintLog(utils.InfoStr, log)
+	go notifiers.Notify(mctx, rule, action, event, log)
+	return log, true
+}
+
+// ResumeApproval applies the decision taken for a parked action: the chain is
+// resumed from this action if it's approved, cancelled otherwise, the
+// 'finally' actions are run then.
+func ResumeApproval(approval *approvals.Approval, decision, by string) {
+	log := utils.LogLine{
+		Message:   approvalStr,
+		Rule:      approval.Rule,
+		Action:    approval.Action,
+		Actionner: approval.Actionner,
+		Objects:   map[string]string{"approval_id": approval.ID},
+		Result:    fmt.Sprintf("%v by '%v'", decision, by),
+	}
+	if approval.Event != nil {
+		log.Event = approval.Event.Output
+		log.TraceID = approval.Event.TraceID
+	}
+
+	ruleSet := rules.GetRuleSet()
+	rule, n := findParkedAction(ruleSet, approval.Rule, approval.Action, approval.ActionIndex)
+	if n < 0 || approval.Event == nil {
+		log.Status = utils.FailureStr
+		log.Error = "the rule or the action doesn't exist anymore, the chain is cancelled"
+		utils.PrintLog(utils.ErrorStr, log)
+		return
+	}
+	log.RulesVersion = fmt.Sprintf("%v", ruleSet.Version)
+	log.RulesChecksum = ruleSet.ShortChecksum()
+
+	ctx := context.Background()
+	if decision != rules.ApproveStr {
+		log.Status = utils.DeniedStr
+		log.Output = "the remaining actions are cancelled"
+		utils.PrintLog(utils.InfoStr, log)
+		go notifiers.Notify(ctx, rule, rule.GetActions()[n], approval.Event, log)
+		results := approval.Results
+		if results == nil {
+			results = rules.Results{}
+		}
+		results[approval.Action] = &rules.ActionResult{Action: approval.Action, Status: utils.DeniedStr}
+		runFinally(ctx, ruleSet, rule, approval.Event, results[approval.Action], results)
+		return
+	}
+
+	log.Status = utils.ApprovedStr
+	utils.PrintLog(utils.InfoStr, log)
+	go notifiers.Notify(ctx, rule, rule.GetActions()[n], approval.Event, log)
+	runActions(ctx, ruleSet, rule, approval.Event, n, afterApproval, approval.Results)
+}
+
+// findParkedAction returns the rule of a parked action and the index of the
+// action in its chain, -1 if they don't exist anymore. The index is used if
+// the name of the action appears several times in the chain.
+func findParkedAction(ruleSet *rules.RuleSet, ruleName, action string, index int) (*rules.Rule, int) {
+	for _, i := range ruleSet.Rules {
+		if i.GetName() != ruleName {
+			continue
+		}
+		n := -1
+		for j, a := range i.GetActions() {
+			if a.GetName() == action && (n < 0 || j == index) {
+				n = j
+			}
+		}
+		return i, n
+	}
+	return nil, -1
+}
+
+// scheduleAction parks the delayed action at index n and the rest of the
+// chain until its time. It returns false if the action can't be scheduled,
+// the chain stops then.
+func scheduleAction(mctx context.Context, ruleSet *rules.RuleSet, rule *rules.Rule, n int, event *events.Event, results rules.Results) (log utils.LogLine, parked bool) {
+	action := rule.GetActions()[n]
+	log = utils.LogLine{
+		Message:       scheduleStr,
+		Rule:          rule.GetName(),
+		Event:         event.Output,
+		Action:        action.GetName(),
+		Actionner:     action.GetActionner(),
+		TraceID:       event.TraceID,
+		RulesVersion:  fmt.Sprintf("%v", ruleSet.Version),
+		RulesChecksum: ruleSet.ShortChecksum(),
+	}
+
+	store := schedules.GetStore()
+	if store == nil {
+		log.Status = utils.FailureStr
+		log.Error = "the delayed actions are not available, the action is not run"
+		utils.PrintLog(utils.ErrorStr, log)
+		go notifiers.Notify(mctx, rule, action, event, log)
+		return log, false
+	}
+
+	now := time.Now()
+	schedule := &schedules.Schedule{
+		CreatedAt:     now,
+		FiresAt:       now.Add(action.GetDelay()),
+		Event:         event,
+		Rule:          rule.GetName(),
+		Action:        action.GetName(),
+		Actionner:     action.GetActionner(),
+		RulesVersion:  log.RulesVersion,
+		RulesChecksum: log.RulesChecksum,
+		Results:       results,
+		ActionIndex:   n,
+	}
+	if err := store.Add(schedule); err != nil {
+		log.Status = utils.FailureStr
+		log.Error = err.Error()
+		utils.PrintLog(utils.ErrorStr, log)
+		go notifiers.Notify(mctx, rule, action, event, log)
+		return log, false
+	}
+
+	log.Status = utils.ScheduledStr
+	log.Objects = map[string]string{"schedule_id": schedule.ID}
+	log.Output = fmt.Sprintf("the action and the rest of the chain are scheduled at %v", schedule.FiresAt.Format(time.RFC3339))
+	utils.PrintLog(utils.InfoStr, log)
+	go notifiers.Notify(mctx, rule, action, event, log)
+	return log, true
+}
+
+// ResumeSchedule resumes the chain of a delayed action when it fires, the
+// chain is cancelled if it's cancelled before, the 'finally' actions are run
+// then.
+func ResumeSchedule(schedule *schedules.Schedule, status, by string) {
+	log := utils.LogLine{
+		Message:   scheduleStr,
+		Rule:      schedule.Rule,
+		Action:    schedule.Action,
+		Actionner: schedule.Actionner,
+		Objects:   map[string]string{"schedule_id": schedule.ID},
+		Status:    status,
+		Result:    fmt.Sprintf("%v by '%v'", status, by),
+	}
+	if schedule.Event != nil {
+		log.Event = schedule.Event.Output
+		log.TraceID = schedule.Event.TraceID
+	}
+
+	ruleSet := rules.GetRuleSet()
+	rule, n := findParkedAction(ruleSet, schedule.Rule, schedule.Action, schedule.ActionIndex)
+	if n < 0 || schedule.Event == nil {
+		log.Status = utils.FailureStr
+		log.Error = "the rule or the action doesn't exist anymore, the chain is cancelled"
+		utils.PrintLog(utils.ErrorStr, log)
+		return
+	}
+	log.RulesVersion = fmt.Sprintf("%v", ruleSet.Version)
+	log.RulesChecksum = ruleSet.ShortChecksum()
+
+	ctx := context.Background()
+	if status != utils.FiredStr {
+		log.Output = "the remaining actions are cancelled"
+		utils.PrintLog(utils.InfoStr, log)
+		go notifiers.Notify(ctx, rule, rule.GetActions()[n], schedule.Event, log)
+		results := schedule.Results
+		if results == nil {
+			results = rules.Results{}
+		}
+		results[schedule.Action] = &rules.ActionResult{Action: schedule.Action, Status: utils.CancelledStr}
+		runFinally(ctx, ruleSet, rule, schedule.Event, results[schedule.Action], results)
+		return
+	}
+
+	utils.PrintLog(utils.InfoStr, log)
+	go notifiers.Notify(ctx, rule, rule.GetActions()[n], schedule.Event, log)
+	runActions(ctx, ruleSet, rule, schedule.Event, n, afterDelay, schedule.Results)
 }
@@ -2,21 +2,31 @@ package actionners
 
 import (
 	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/approvals"
+	"github.com/falcosecurity/falco-talon/internal/budget"
+	"github.com/falcosecurity/falco-talon/internal/enforcement"
+	"github.com/falcosecurity/falco-talon/internal/escalation"
 	"github.com/falcosecurity/falco-talon/internal/events"
 	"github.com/falcosecurity/falco-talon/internal/models"
 	"github.com/falcosecurity/falco-talon/internal/otlp/metrics"
 	"github.com/falcosecurity/falco-talon/internal/otlp/traces"
+	"github.com/falcosecurity/falco-talon/internal/pause"
 	"github.com/falcosecurity/falco-talon/internal/rules"
+	"github.com/falcosecurity/falco-talon/internal/schedules"
 	"github.com/falcosecurity/falco-talon/utils"
 )
 
 type requireOutputActionnerStub struct{}
 
-func (a requireOutputActionnerStub) Init() error { return nil }
+func (a requireOutputActionnerStub) Init(_ *configuration.Configuration) error { return nil }
 
 func (a requireOutputActionnerStub) Run(_ *events.Event, _ *rules.Action) (utils.LogLine, *models.Data, error) {
 	return utils.LogLine{Status: utils.SuccessStr}, &models.Data{
@@ -26,6 +36,10 @@ func (a requireOutputActionnerStub) Run(_ *events.Event, _ *rules.Action) (utils
 	}, nil
 }
 
+func (a requireOutputActionnerStub) Plan(_ *events.Event, _ *rules.Action) (utils.LogLine, error) {
+	return utils.LogLine{}, nil
+}
+
 func (a requireOutputActionnerStub) CheckParameters(_ *rules.Action) error { return nil }
 
 func (a requireOutputActionnerStub) Checks(_ *events.Event, _ *rules.Action) error { return nil }
@@ -52,10 +66,10 @@ func TestRunActionReturnsOutputCheckErrors(t *testing.T) {
 		_ = shutdown(context.Background())
 	})
 
-	previousEnabled := enabledActionners
-	enabledActionners = &Actionners{requireOutputActionnerStub{}}
+	previousEnabled := enabledActionners.Load()
+	enabledActionners.Store(&Actionners{requireOutputActionnerStub{}})
 	t.Cleanup(func() {
-		enabledActionners = previousEnabled
+		enabledActionners.Store(previousEnabled)
 	})
 
 	action := &rules.Action{
@@ -73,12 +87,784 @@ func TestRunActionReturnsOutputCheckErrors(t *testing.T) {
 	rule := &rules.Rule{Name: "rule"}
 	event := &events.Event{Output: "event", TraceID: "trace-id"}
 
-	err = runAction(context.Background(), rule, action, event)
+	log, data, err := runAction(context.Background(), rules.GetRuleSet(), rule, action, event)
 	if err == nil {
 		t.Fatal("expected output checks failure to be returned")
 	}
+	if r := newActionResult(log, data); r.Status != utils.SuccessStr || r.Data == nil || string(r.Data.Bytes) != "payload" {
+		t.Fatalf("expected the result and the data of the action to be returned for the next actions, got %#v", r)
+	}
 
 	if !strings.Contains(err.Error(), "does not exist") {
 		t.Fatalf("expected missing destination error, got %v", err)
 	}
 }
+
+func TestRunActionsParksTheChainUntilApproval(t *testing.T) {
+	configuration.CreateConfiguration("")
+	metrics.Init()
+
+	if err := approvals.Init(filepath.Join(t.TempDir(), "approvals.json"), nil); err != nil {
+		t.Fatalf("init approvals: %v", err)
+	}
+	store := approvals.GetStore()
+	t.Cleanup(store.Close)
+
+	rule := &rules.Rule{
+		Name: "rule",
+		Actions: []*rules.Action{
+			{
+				Name:            "terminate",
+				Actionner:       "kubernetes:terminate",
+				RequireApproval: &rules.Approval{Timeout: "1h", DefaultDecision: rules.DenyStr},
+			},
+		},
+	}
+	previous := rules.GetRuleSet()
+	ruleSet := rules.SetRules(&[]*rules.Rule{rule})
+	t.Cleanup(func() { rules.RestoreRuleSet(previous) })
+
+	event := &events.Event{Output: "event", TraceID: "trace-id"}
+	runActions(context.Background(), ruleSet, rule, event, 0, notResumed, nil)
+
+	l := store.List()
+	if len(l) != 1 {
+		t.Fatalf("expected 1 pending approval, got %d", len(l))
+	}
+	if l[0].Rule != "rule" || l[0].Action != "terminate" || l[0].ActionIndex != 0 || l[0].DefaultDecision != rules.DenyStr {
+		t.Fatalf("unexpected approval %#v", l[0])
+	}
+	if d := time.Until(l[0].ExpiresAt); d <= 59*time.Minute || d > time.Hour {
+		t.Fatalf("unexpected expiration %v", l[0].ExpiresAt)
+	}
+
+	// a denied approval cancels the chain without running the action
+	if _, err := store.Decide(l[0].ID, rules.DenyStr, "alice"); err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	if len(store.List()) != 0 {
+		t.Fatal("expected no pending approval after the decision")
+	}
+}
+
+func TestRunActionsSkipsTheActionsWithAFalseCondition(t *testing.T) {
+	configuration.CreateConfiguration("")
+	metrics.Init()
+
+	if err := approvals.Init(filepath.Join(t.TempDir(), "approvals.json"), nil); err != nil {
+		t.Fatalf("init approvals: %v", err)
+	}
+	store := approvals.GetStore()
+	t.Cleanup(store.Close)
+
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rulesFile, []byte(`- rule: rule
+  actions:
+    - action: terminate
+      actionner: kubernetes:terminate
+      when: event.output_fields[k8s.ns.name] = prod
+      require_approval: {}
+    - action: label
+      actionner: kubernetes:label
+      when: action[terminate].status = skipped
+      require_approval: {}
+`), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+	previous := rules.GetRuleSet()
+	r := rules.LoadRules([]string{rulesFile})
+	if r == nil {
+		t.Fatalf("expected the rules to be valid, got %v", rules.GetDiagnostics())
+	}
+	ruleSet := rules.SetRules(r)
+	t.Cleanup(func() { rules.RestoreRuleSet(previous) })
+	rule := (*r)[0]
+
+	event := &events.Event{Output: "event", TraceID: "trace-id", OutputFields: map[string]any{"k8s.ns.name": "default"}}
+	runActions(context.Background(), ruleSet, rule, event, 0, notResumed, nil)
+
+	l := store.List()
+	if len(l) != 1 || l[0].Action != "label" {
+		t.Fatalf("expected the second action only to be parked, got %#v", l)
+	}
+	if r := l[0].Results["terminate"]; r == nil || r.Status != utils.SkippedStr {
+		t.Fatalf("expected the result of the skipped action to be kept with the approval, got %#v", l[0].Results)
+	}
+}
+
+// blockingActionnerStub signals the start of its actions and blocks them until
+// release is closed.
+type blockingActionnerStub struct {
+	started chan string
+	release chan struct{}
+}
+
+func (blockingActionnerStub) Init(_ *configuration.Configuration) error { return nil }
+
+func (a blockingActionnerStub) Run(_ *events.Event, action *rules.Action) (utils.LogLine, *models.Data, error) {
+	a.started <- action.GetName()
+	<-a.release
+	return utils.LogLine{Status: utils.SuccessStr}, nil, nil
+}
+
+func (blockingActionnerStub) Plan(_ *events.Event, _ *rules.Action) (utils.LogLine, error) {
+	return utils.LogLine{}, nil
+}
+
+func (blockingActionnerStub) CheckParameters(_ *rules.Action) error { return nil }
+
+func (blockingActionnerStub) Checks(_ *events.Event, _ *rules.Action) error { return nil }
+
+func (blockingActionnerStub) Information() models.Information {
+	return models.Information{Name: "blocking", FullName: "tests:blocking", Category: "tests", Continue: true}
+}
+
+func (blockingActionnerStub) Parameters() models.Parameters { return nil }
+
+func TestRunParallelRunsTheActionsConcurrently(t *testing.T) {
+	configuration.CreateConfiguration("")
+	metrics.Init()
+	shutdown, err := traces.SetupOTelSDK(context.Background())
+	if err != nil {
+		t.Fatalf("setup traces: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = shutdown(context.Background())
+	})
+
+	stub := blockingActionnerStub{started: make(chan string, 2), release: make(chan struct{})}
+	previousEnabled := enabledActionners.Load()
+	enabledActionners.Store(&Actionners{stub})
+	t.Cleanup(func() {
+		enabledActionners.Store(previousEnabled)
+	})
+
+	rule := loadParallelRule(t, "5s", "logs", "tcpdump")
+	event := &events.Event{Output: "event", TraceID: "trace-id"}
+	results := rules.Results{}
+	done := make(chan bool)
+	go func() {
+		done <- runParallel(context.Background(), rules.GetRuleSet(), rule, event, rule.GetActions(), nil, results)
+	}()
+	// both actions have to be started before one of them is released
+	for range 2 {
+		select {
+		case <-stub.started:
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected the actions of the group to run concurrently")
+		}
+	}
+	close(stub.release)
+	if !<-done {
+		t.Fatal("expected the chain to continue after the group")
+	}
+	for _, i := range rule.GetActions() {
+		if r := results[i.GetName()]; r == nil || r.Status != utils.SuccessStr {
+			t.Fatalf("expected the action '%v' to succeed, got %#v", i.GetName(), r)
+		}
+	}
+
+	// the actions still running at the timeout are failed
+	stub.release = make(chan struct{})
+	enabledActionners.Store(&Actionners{stub})
+	t.Cleanup(func() { close(stub.release) })
+	rule = loadParallelRule(t, "50ms", "sysdig", "download")
+	results = rules.Results{}
+	if runParallel(context.Background(), rules.GetRuleSet(), rule, event, rule.GetActions(), nil, results) {
+		t.Fatal("expected the chain to stop after a timeout")
+	}
+	if r := results["sysdig"]; r == nil || r.Status != utils.FailureStr || !strings.Contains(r.Error, "timed out") {
+		t.Fatalf("expected the action to be failed by the timeout, got %#v", r)
+	}
+}
+
+// loadParallelRule loads a rule with a parallel group of actions.
+func loadParallelRule(t *testing.T, timeout string, names ...string) *rules.Rule {
+	t.Helper()
+	content := "- rule: rule\n  actions:\n    - parallel:\n        timeout: " + timeout + "\n        actions:\n"
+	for _, i := range names {
+		content += "          - action: " + i + "\n            actionner: tests:blocking\n            continue: true\n"
+	}
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rulesFile, []byte(content), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+	r := rules.LoadRules([]string{rulesFile})
+	if r == nil {
+		t.Fatalf("expected the rules to be valid, got %v", rules.GetDiagnostics())
+	}
+	return (*r)[0]
+}
+
+// recordingActionnerStub records the actions run, the ones with the parameter
+// 'fail' fail, their plans too.
+type recordingActionnerStub struct {
+	runs *[]string
+}
+
+func (recordingActionnerStub) Init(_ *configuration.Configuration) error { return nil }
+
+func (a recordingActionnerStub) Run(_ *events.Event, action *rules.Action) (utils.LogLine, *models.Data, error) {
+	*a.runs = append(*a.runs, action.GetName())
+	if action.GetParameters()["fail"] == true {
+		return utils.LogLine{Status: utils.FailureStr}, nil, errors.New("failed")
+	}
+	return utils.LogLine{Status: utils.SuccessStr}, nil, nil
+}
+
+func (recordingActionnerStub) Plan(_ *events.Event, action *rules.Action) (utils.LogLine, error) {
+	if action.GetParameters()["fail"] == true {
+		return utils.LogLine{}, errors.New("failed")
+	}
+	return utils.LogLine{Objects: map[string]string{"pod": "demo"}, Output: "+ " + action.GetName()}, nil
+}
+
+func (recordingActionnerStub) CheckParameters(_ *rules.Action) error { return nil }
+
+func (recordingActionnerStub) Checks(_ *events.Event, _ *rules.Action) error { return nil }
+
+func (recordingActionnerStub) Information() models.Information {
+	return models.Information{Name: "recording", FullName: "tests:recording", Category: "tests", Continue: true}
+}
+
+func (recordingActionnerStub) Parameters() models.Parameters { return nil }
+
+func TestRunActionsRunsTheFallbacksAndTheFinallyActions(t *testing.T) {
+	configuration.CreateConfiguration("")
+	metrics.Init()
+	shutdown, err := traces.SetupOTelSDK(context.Background())
+	if err != nil {
+		t.Fatalf("setup traces: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = shutdown(context.Background())
+	})
+
+	var runs []string
+	previousEnabled := enabledActionners.Load()
+	enabledActionners.Store(&Actionners{recordingActionnerStub{runs: &runs}})
+	// the default settings of the actionners are used for the chain
+	previousDefault := *defaultActionners
+	defaultActionners.Add(recordingActionnerStub{runs: &runs})
+	t.Cleanup(func() {
+		enabledActionners.Store(previousEnabled)
+		*defaultActionners = previousDefault
+	})
+
+	cases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name: "recovered failure",
+			content: `
+    - action: cilium
+      actionner: tests:recording
+      parameters: {fail: true}
+      on_failure:
+        - action: kubernetes
+          actionner: tests:recording
+          parameters: {fail: true}
+          on_failure:
+            - action: terminate
+              actionner: tests:recording
+    - action: label
+      actionner: tests:recording
+  finally:
+    - action: summary
+      actionner: tests:recording
+      when: action[cilium].status = failure and previous.status = success`,
+			expected: "cilium,kubernetes,terminate,label,summary",
+		},
+		{
+			name: "unrecovered failure",
+			content: `
+    - action: cilium
+      actionner: tests:recording
+      parameters: {fail: true}
+      on_failure:
+        - action: kubernetes
+          actionner: tests:recording
+          parameters: {fail: true}
+    - action: label
+      actionner: tests:recording
+  finally:
+    - action: cleanup
+      actionner: tests:recording
+      parameters: {fail: true}
+    - action: summary
+      actionner: tests:recording`,
+			expected: "cilium,kubernetes,cleanup,summary",
+		},
+		{
+			name: "failure recovered by an action stopping the chain",
+			content: `
+    - action: cilium
+      actionner: tests:recording
+      parameters: {fail: true}
+      on_failure:
+        - action: terminate
+          actionner: tests:recording
+          continue: false
+        - action: kubernetes
+          actionner: tests:recording
+    - action: label
+      actionner: tests:recording
+  finally:
+    - action: summary
+      actionner: tests:recording`,
+			expected: "cilium,terminate,summary",
+		},
+	}
+
+	for _, c := range cases {
+		runs = nil
+		rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+		if err := os.WriteFile(rulesFile, []byte("- rule: rule\n  actions:"+c.content+"\n"), 0o600); err != nil {
+			t.Fatalf("write rules file: %v", err)
+		}
+		r := rules.LoadRules([]string{rulesFile})
+		if r == nil {
+			t.Fatalf("%v: expected the rules to be valid, got %v", c.name, rules.GetDiagnostics())
+		}
+		runActions(context.Background(), rules.GetRuleSet(), (*r)[0], &events.Event{Output: "event"}, 0, notResumed, nil)
+		if got := strings.Join(runs, ","); got != c.expected {
+			t.Errorf("%v: expected the actions '%v' to be run, got '%v'", c.name, c.expected, got)
+		}
+	}
+}
+
+func TestRunActionsSchedulesTheDelayedActions(t *testing.T) {
+	configuration.CreateConfiguration("")
+	metrics.Init()
+
+	ended := make(chan string, 1)
+	if err := schedules.Init(filepath.Join(t.TempDir(), "schedules.json"), func(schedule *schedules.Schedule, status, by string) {
+		ended <- schedule.Action + " " + status + " by " + by
+	}); err != nil {
+		t.Fatalf("init schedules: %v", err)
+	}
+	store := schedules.GetStore()
+	t.Cleanup(store.Close)
+
+	rule := &rules.Rule{
+		Name: "rule",
+		Actions: []*rules.Action{
+			{Name: "terminate", Actionner: "kubernetes:terminate", Delay: "10m"},
+		},
+	}
+	previous := rules.GetRuleSet()
+	ruleSet := rules.SetRules(&[]*rules.Rule{rule})
+	t.Cleanup(func() { rules.RestoreRuleSet(previous) })
+
+	event := &events.Event{Output: "event", TraceID: "trace-id"}
+	runActions(context.Background(), ruleSet, rule, event, 0, notResumed, nil)
+
+	l := store.List()
+	if len(l) != 1 {
+		t.Fatalf("expected 1 scheduled action, got %d", len(l))
+	}
+	if l[0].Rule != "rule" || l[0].Action != "terminate" || l[0].ActionIndex != 0 {
+		t.Fatalf("unexpected scheduled action %#v", l[0])
+	}
+	if d := time.Until(l[0].FiresAt); d <= 9*time.Minute || d > 10*time.Minute {
+		t.Fatalf("unexpected fire time %v", l[0].FiresAt)
+	}
+
+	if _, err := store.Cancel(l[0].ID, "alice"); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if s := <-ended; s != "terminate cancelled by alice" {
+		t.Fatalf("unexpected end of the scheduled action '%v'", s)
+	}
+	if len(store.List()) != 0 {
+		t.Fatal("expected no scheduled action after the cancellation")
+	}
+}
+
+func TestRunActionsRunsTheEscalationStep(t *testing.T) {
+	configuration.CreateConfiguration("")
+	metrics.Init()
+	shutdown, err := traces.SetupOTelSDK(context.Background())
+	if err != nil {
+		t.Fatalf("setup traces: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = shutdown(context.Background())
+	})
+	if err := escalation.Init(filepath.Join(t.TempDir(), "escalations.json")); err != nil {
+		t.Fatalf("init escalations: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = escalation.GetStore().Close()
+	})
+
+	var runs []string
+	previousEnabled := enabledActionners.Load()
+	enabledActionners.Store(&Actionners{recordingActionnerStub{runs: &runs}})
+	previousDefault := *defaultActionners
+	defaultActionners.Add(recordingActionnerStub{runs: &runs})
+	t.Cleanup(func() {
+		enabledActionners.Store(previousEnabled)
+		*defaultActionners = previousDefault
+	})
+
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rulesFile, []byte(`- rule: rule
+  escalation:
+    key: namespace
+    steps:
+      - offense: 1
+        actions:
+          - action: label
+            actionner: tests:recording
+      - offense: 2
+        actions:
+          - action: netpol
+            actionner: tests:recording
+      - offense: 3
+        actions:
+          - action: label
+            actionner: tests:recording
+          - action: terminate
+            actionner: tests:recording
+            when: previous.status = success
+  finally:
+    - action: summary
+      actionner: tests:recording
+`), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+	r := rules.LoadRules([]string{rulesFile})
+	if r == nil {
+		t.Fatalf("expected the rules to be valid, got %v", rules.GetDiagnostics())
+	}
+
+	inNamespace := func(namespace string) *events.Event {
+		return &events.Event{Output: "event", OutputFields: map[string]any{"k8s.ns.name": namespace}}
+	}
+	for _, i := range []string{"default", "default", "prod", "default", "default"} {
+		runActions(context.Background(), rules.GetRuleSet(), (*r)[0], inNamespace(i), 0, notResumed, nil)
+	}
+	expected := "label,summary,netpol,summary,label,summary,label,terminate,summary,label,terminate,summary"
+	if got := strings.Join(runs, ","); got != expected {
+		t.Fatalf("expected the actions '%v' to be run, got '%v'", expected, got)
+	}
+
+	// the events without namespace are not counted together
+	for range 3 {
+		runActions(context.Background(), rules.GetRuleSet(), (*r)[0], &events.Event{Output: "event"}, 0, notResumed, nil)
+	}
+	if got := strings.Join(runs, ","); got != expected {
+		t.Fatalf("expected no step to be run for the events without key, got '%v'", got)
+	}
+	if n := escalation.GetStore().Record("rule", "", time.Hour, time.Now()); n != 1 {
+		t.Fatalf("expected no offense counted without key, got %v offense(s)", n)
+	}
+
+	// the offenses in dry run are counted apart
+	p, err := enforcement.NewPolicy(configuration.Enforcement{Mode: enforcement.DryRunStr})
+	if err != nil {
+		t.Fatalf("new enforcement policy: %v", err)
+	}
+	previousPolicy := enforcement.GetPolicy()
+	enforcement.Store(p)
+	t.Cleanup(func() { enforcement.Store(previousPolicy) })
+	runActions(context.Background(), rules.GetRuleSet(), (*r)[0], inNamespace("default"), 0, notResumed, nil)
+	if n := escalation.GetStore().Record("rule", "dry-run/default", time.Hour, time.Now()); n != 2 {
+		t.Fatalf("expected the offense in dry run to be counted apart, got %v offense(s)", n)
+	}
+	if n := escalation.GetStore().Record("rule", "default", time.Hour, time.Now()); n != 5 {
+		t.Fatalf("expected the enforced offenses to be unchanged, got %v offense(s)", n)
+	}
+}
+
+func TestRunActionsSkipsThePausedActions(t *testing.T) {
+	configuration.CreateConfiguration("")
+	metrics.Init()
+	shutdown, err := traces.SetupOTelSDK(context.Background())
+	if err != nil {
+		t.Fatalf("setup traces: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = shutdown(context.Background())
+	})
+	if err := pause.Init(filepath.Join(t.TempDir(), "pause.json"), nil, "", 0); err != nil {
+		t.Fatalf("init the pause: %v", err)
+	}
+	store := pause.GetStore()
+	t.Cleanup(func() {
+		_, _ = store.Resume(pause.Request{})
+		_, _ = store.Resume(pause.Request{Rule: "rule"})
+		store.Close()
+	})
+
+	var runs []string
+	previousEnabled := enabledActionners.Load()
+	enabledActionners.Store(&Actionners{recordingActionnerStub{runs: &runs}})
+	previousDefault := *defaultActionners
+	defaultActionners.Add(recordingActionnerStub{runs: &runs})
+	t.Cleanup(func() {
+		enabledActionners.Store(previousEnabled)
+		*defaultActionners = previousDefault
+	})
+
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rulesFile, []byte(`- rule: rule
+  actions:
+    - action: label
+      actionner: tests:recording
+      on_failure:
+        - action: terminate
+          actionner: tests:recording
+    - action: netpol
+      actionner: tests:recording
+`), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+	r := rules.LoadRules([]string{rulesFile})
+	if r == nil {
+		t.Fatalf("expected the rules to be valid, got %v", rules.GetDiagnostics())
+	}
+
+	cases := []struct {
+		name     string
+		pause    *pause.Request
+		resume   *pause.Request
+		expected string
+	}{
+		{name: "category paused", pause: &pause.Request{Category: "tests"}, expected: ""},
+		{name: "other category paused", pause: &pause.Request{Category: "kubernetes"}, resume: &pause.Request{Category: "tests"}, expected: "label,netpol"},
+		{name: "rule disabled", pause: &pause.Request{Rule: "rule"}, expected: ""},
+		{name: "rule enabled", resume: &pause.Request{Rule: "rule"}, expected: "label,netpol"},
+		{name: "all paused", pause: &pause.Request{}, expected: ""},
+		{name: "all resumed", resume: &pause.Request{}, expected: "label,netpol"},
+	}
+	for _, c := range cases {
+		if c.pause != nil {
+			if _, err := store.Pause(*c.pause); err != nil {
+				t.Fatalf("%v: pause: %v", c.name, err)
+			}
+		}
+		if c.resume != nil {
+			if _, err := store.Resume(*c.resume); err != nil {
+				t.Fatalf("%v: resume: %v", c.name, err)
+			}
+		}
+		runs = nil
+		results := rules.Results{}
+		runActions(context.Background(), rules.GetRuleSet(), (*r)[0], &events.Event{Output: "event"}, 0, notResumed, results)
+		if got := strings.Join(runs, ","); got != c.expected {
+			t.Errorf("%v: expected the actions '%v' to be run, got '%v'", c.name, c.expected, got)
+		}
+		if c.expected == "" && (results["label"] == nil || results["label"].Status != utils.SkippedStr || results["terminate"] != nil) {
+			t.Errorf("%v: expected the action to be skipped without fallback, got %+v", c.name, results["label"])
+		}
+	}
+}
+
+func TestRunActionsPlansTheActionsInDryRun(t *testing.T) {
+	configuration.CreateConfiguration("")
+	metrics.Init()
+	shutdown, err := traces.SetupOTelSDK(context.Background())
+	if err != nil {
+		t.Fatalf("setup traces: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = shutdown(context.Background())
+	})
+
+	var runs []string
+	previousEnabled := enabledActionners.Load()
+	enabledActionners.Store(&Actionners{recordingActionnerStub{runs: &runs}})
+	previousDefault := *defaultActionners
+	defaultActionners.Add(recordingActionnerStub{runs: &runs})
+	t.Cleanup(func() {
+		enabledActionners.Store(previousEnabled)
+		*defaultActionners = previousDefault
+	})
+
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rulesFile, []byte(`- rule: rule
+  dry_run: true
+  actions:
+    - action: label
+      actionner: tests:recording
+    - action: netpol
+      actionner: tests:recording
+      parameters:
+        fail: true
+`), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+	r := rules.LoadRules([]string{rulesFile})
+	if r == nil {
+		t.Fatalf("expected the rules to be valid, got %v", rules.GetDiagnostics())
+	}
+
+	results := rules.Results{}
+	runActions(context.Background(), rules.GetRuleSet(), (*r)[0], &events.Event{Output: "event"}, 0, notResumed, results)
+	if len(runs) != 0 {
+		t.Fatalf("expected no action to be run in dry run, got %v", runs)
+	}
+	if l := results["label"]; l == nil || l.Status != utils.PlannedStr || l.Output != "+ label" || l.Objects["pod"] != "demo" {
+		t.Fatalf("expected the plan of the action, got %+v", l)
+	}
+	if l := results["netpol"]; l == nil || l.Status != utils.FailureStr || l.Error != "failed" {
+		t.Fatalf("expected the plan of the action to fail, got %+v", l)
+	}
+
+	// the plans report the refusal of an exhausted budget, without taking
+	// from it
+	b, err := budget.New([]configuration.Budget{{Name: "labels", Actionners: []string{"tests:recording"}, Max: 1}})
+	if err != nil {
+		t.Fatalf("new budgets: %v", err)
+	}
+	budget.Store(b)
+	t.Cleanup(func() { budget.Store(nil) })
+	runActions(context.Background(), rules.GetRuleSet(), (*r)[0], &events.Event{Output: "event"}, 0, notResumed, results)
+	if l := results["label"]; l == nil || l.Status != utils.PlannedStr {
+		t.Fatalf("expected the plan of the action within its budget, got %+v", l)
+	}
+	if _, reason := budget.Consume(&events.Event{}, (*r)[0].GetActions()[0]); reason != "" {
+		t.Fatalf("expected the budget not to be taken by the plans, got '%v'", reason)
+	}
+	runActions(context.Background(), rules.GetRuleSet(), (*r)[0], &events.Event{Output: "event"}, 0, notResumed, results)
+	if l := results["label"]; l == nil || l.Status != utils.ThrottledStr || !strings.Contains(l.Error, "'labels'") {
+		t.Fatalf("expected the plan to report the exhausted budget, got %+v", l)
+	}
+	if len(runs) != 0 {
+		t.Fatalf("expected no action to be run in dry run, got %v", runs)
+	}
+}
+
+func TestRunActionsFollowsTheEnforcementModes(t *testing.T) {
+	configuration.CreateConfiguration("")
+	metrics.Init()
+	shutdown, err := traces.SetupOTelSDK(context.Background())
+	if err != nil {
+		t.Fatalf("setup traces: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = shutdown(context.Background())
+	})
+	// the blocking stub would block if its action was run
+	p, err := enforcement.NewPolicy(configuration.Enforcement{
+		Mode:       enforcement.EnforceStr,
+		Categories: map[string]string{"tests": enforcement.ObserveStr},
+		Actionners: map[string]string{"tests:recording": enforcement.DryRunStr},
+	})
+	if err != nil {
+		t.Fatalf("new enforcement policy: %v", err)
+	}
+	previousPolicy := enforcement.GetPolicy()
+	enforcement.Store(p)
+	t.Cleanup(func() { enforcement.Store(previousPolicy) })
+
+	var runs []string
+	previousEnabled := enabledActionners.Load()
+	enabledActionners.Store(&Actionners{recordingActionnerStub{runs: &runs}, blockingActionnerStub{}})
+	previousDefault := *defaultActionners
+	defaultActionners.Add(recordingActionnerStub{runs: &runs})
+	defaultActionners.Add(blockingActionnerStub{})
+	t.Cleanup(func() {
+		enabledActionners.Store(previousEnabled)
+		*defaultActionners = previousDefault
+	})
+
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rulesFile, []byte(`- rule: rule
+  actions:
+    - action: label
+      actionner: tests:recording
+    - action: drain
+      actionner: tests:blocking
+`), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+	r := rules.LoadRules([]string{rulesFile})
+	if r == nil {
+		t.Fatalf("expected the rules to be valid, got %v", rules.GetDiagnostics())
+	}
+
+	results := rules.Results{}
+	runActions(context.Background(), rules.GetRuleSet(), (*r)[0], &events.Event{Output: "event"}, 0, notResumed, results)
+	if len(runs) != 0 {
+		t.Fatalf("expected no action to be run, got %v", runs)
+	}
+	if l := results["label"]; l == nil || l.Status != utils.PlannedStr {
+		t.Fatalf("expected the action of the actionner in dry run to be planned, got %+v", l)
+	}
+	if l := results["drain"]; l == nil || l.Status != utils.ObservedStr {
+		t.Fatalf("expected the action of the category observed to be observed, got %+v", l)
+	}
+}
+
+func TestRunActionsFailsTheActionsOfAnUnknownCluster(t *testing.T) {
+	configuration.CreateConfiguration("")
+	metrics.Init()
+	shutdown, err := traces.SetupOTelSDK(context.Background())
+	if err != nil {
+		t.Fatalf("setup traces: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = shutdown(context.Background())
+	})
+	configuration.GetConfiguration().Clusters = configuration.Clusters{
+		Field:       "k8s.cluster.name",
+		Credentials: map[string]configuration.ClusterCredentials{"prod": {}},
+	}
+	t.Cleanup(func() {
+		configuration.GetConfiguration().Clusters = configuration.Clusters{}
+	})
+
+	previousEnabled := enabledActionners.Load()
+	enabledActionners.Store(&Actionners{ListDefaultActionners().FindActionner("kubernetes:terminate")})
+	t.Cleanup(func() {
+		enabledActionners.Store(previousEnabled)
+	})
+
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rulesFile, []byte(`- rule: rule
+  dry_run: true
+  actions:
+    - action: terminate
+      actionner: kubernetes:terminate
+`), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+	r := rules.LoadRules([]string{rulesFile})
+	if r == nil {
+		t.Fatalf("expected the rules to be valid, got %v", rules.GetDiagnostics())
+	}
+
+	event := &events.Event{Output: "event", OutputFields: map[string]any{
+		"k8s.cluster.name": "unknown",
+		"k8s.pod.name":     "demo",
+		"k8s.ns.name":      "default",
+		"fd.rip":           "10.0.0.1",
+		"fd.sip":           "10.0.0.1",
+	}}
+	results := rules.Results{}
+	runActions(context.Background(), rules.GetRuleSet(), (*r)[0], event, 0, notResumed, results)
+	if l := results["terminate"]; l == nil || l.Status != utils.FailureStr || !strings.Contains(l.Error, "'unknown'") {
+		t.Fatalf("expected the plan to fail for the unknown cluster, got %+v", l)
+	}
+
+	// neither the plans nor the runs of the actionners of the clusters panic
+	for _, a := range *ListDefaultActionners() {
+		switch a.Information().Category {
+		case "kubernetes", "cilium", "calico":
+		default:
+			continue
+		}
+		action := &rules.Action{Name: "action", Actionner: a.Information().FullName}
+		if _, err := a.Plan(event, action); err == nil {
+			t.Errorf("%v: expected the plan to fail for the unknown cluster", action.Actionner)
+		}
+		if _, _, err := a.Run(event, action); err == nil {
+			t.Errorf("%v: expected the run to fail for the unknown cluster", action.Actionner)
+		}
+	}
+}
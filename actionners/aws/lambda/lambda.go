@@ -3,11 +3,13 @@ package lambda
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
 
+	"github.com/falcosecurity/falco-talon/configuration"
 	awsChecks "github.com/falcosecurity/falco-talon/internal/aws/checks"
 	aws "github.com/falcosecurity/falco-talon/internal/aws/client"
 	"github.com/falcosecurity/falco-talon/internal/events"
@@ -72,8 +74,8 @@ func Register() *Actionner {
 	return new(Actionner)
 }
 
-func (a Actionner) Init() error {
-	return aws.Init()
+func (a Actionner) Init(config *configuration.Configuration) error {
+	return aws.Init(config.AwsConfig)
 }
 
 func (a Actionner) Information() models.Information {
@@ -164,6 +166,28 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 	}, nil, nil
 }
 
+func (a Actionner) Plan(event *events.Event, action *rules.Action) (utils.LogLine, error) {
+	var parameters Parameters
+	err := utils.DecodeParams(action.GetParameters(), &parameters)
+	if err != nil {
+		return utils.LogLine{}, err
+	}
+
+	objects := map[string]string{
+		"name":    parameters.AWSLambdaName,
+		"version": parameters.AWSLambdaAliasOrVersion,
+	}
+
+	if err := a.Checks(event, action); err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+
+	return utils.LogLine{
+		Objects: objects,
+		Output:  fmt.Sprintf("the lambda '%v' would be invoked with the event as payload, with the invocation type '%v'", parameters.AWSLambdaName, getInvocationType(parameters.AWSLambdaInvocationType)),
+	}, nil
+}
+
 func (a Actionner) CheckParameters(action *rules.Action) error {
 	var parameters Parameters
 	err := utils.DecodeParams(action.GetParameters(), &parameters)
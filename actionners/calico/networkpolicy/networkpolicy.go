@@ -10,12 +10,13 @@ import (
 	errorsv1 "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/falcosecurity/falco-talon/configuration"
 	calico "github.com/falcosecurity/falco-talon/internal/calico/client"
 
 	"github.com/falcosecurity/falco-talon/internal/events"
 	k8sChecks "github.com/falcosecurity/falco-talon/internal/kubernetes/checks"
-	k8s "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
 	"github.com/falcosecurity/falco-talon/internal/models"
+	"github.com/falcosecurity/falco-talon/internal/plan"
 	"github.com/falcosecurity/falco-talon/internal/rules"
 	"github.com/falcosecurity/falco-talon/utils"
 )
@@ -74,6 +75,13 @@ rules:
   - statefulsets
   verbs:
   - get
+- apiGroups:
+  - batch
+  resources:
+  - jobs
+  - cronjobs
+  verbs:
+  - get
 `
 	Example string = `- action: Create Calico netpol
   actionner: calico:networkpolicy
@@ -110,7 +118,7 @@ func Register() *Actionner {
 	return new(Actionner)
 }
 
-func (a Actionner) Init() error {
+func (a Actionner) Init(_ *configuration.Configuration) error {
 	return calico.Init()
 }
 
@@ -166,10 +174,7 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 		"pod":       podName,
 		"namespace": namespace,
 	}
-	k8sClient := k8s.GetClient()
-	calicoClient := calico.GetClient()
-
-	pod, err := k8sClient.GetPod(podName, namespace)
+	calicoClient, err := calico.GetClientFor(event)
 	if err != nil {
 		return utils.LogLine{
 			Objects: objects,
@@ -178,65 +183,153 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 		}, nil, err
 	}
 
-	var owner string
-	labels := make(map[string]string)
+	payload, err := buildCalicoNetworkPolicy(event, &parameters)
+	if err != nil {
+		return utils.LogLine{
+			Objects: objects,
+			Error:   err.Error(),
+			Status:  utils.FailureStr,
+		}, nil, err
+	}
+	owner := payload.Name
 
-	if len(pod.OwnerReferences) != 0 {
-		switch pod.OwnerReferences[0].Kind {
-		case "DaemonSet":
-			u, err2 := k8sClient.GetDaemonsetFromPod(pod)
-			if err2 != nil {
-				return utils.LogLine{
-					Objects: objects,
-					Error:   err2.Error(),
-					Status:  utils.FailureStr,
-				}, nil, err2
-			}
-			owner = u.Name
-			labels = u.Spec.Selector.MatchLabels
-		case "StatefulSet":
-			u, err2 := k8sClient.GetStatefulsetFromPod(pod)
-			if err2 != nil {
-				return utils.LogLine{
-					Objects: objects,
-					Error:   err2.Error(),
-					Status:  utils.FailureStr,
-				}, nil, err2
-			}
-			owner = u.Name
-			labels = u.Spec.Selector.MatchLabels
-		case "ReplicaSet":
-			u, err2 := k8sClient.GetReplicasetFromPod(pod)
-			if err2 != nil {
+	var output string
+	var netpol *networkingv3.NetworkPolicy
+	netpol, err = calicoClient.ProjectcalicoV3().NetworkPolicies(namespace).Get(context.Background(), owner, metav1.GetOptions{})
+	if errorsv1.IsNotFound(err) {
+		_, err2 := calicoClient.ProjectcalicoV3().NetworkPolicies(namespace).Create(context.Background(), payload, metav1.CreateOptions{})
+		if err2 != nil {
+			if !errorsv1.IsAlreadyExists(err2) {
 				return utils.LogLine{
 					Objects: objects,
 					Error:   err2.Error(),
 					Status:  utils.FailureStr,
 				}, nil, err2
 			}
-			owner = u.Name
-			labels = u.Spec.Selector.MatchLabels
+			netpol, err = calicoClient.ProjectcalicoV3().NetworkPolicies(namespace).Get(context.Background(), owner, metav1.GetOptions{})
+		} else {
+			output = fmt.Sprintf("the caliconetworkpolicy '%v' in the namespace '%v' has been created", owner, namespace)
+			return utils.LogLine{
+				Objects: objects,
+				Output:  output,
+				Status:  utils.SuccessStr,
+			}, nil, nil
 		}
-	} else {
-		owner = pod.Name
-		labels = pod.Labels
 	}
-
-	if owner == "" || len(labels) == 0 {
-		err3 := fmt.Errorf("can't find the owner and/or labels for the pod '%v' in the namespace '%v'", podName, namespace)
+	if err != nil {
 		return utils.LogLine{
 			Objects: objects,
-			Error:   err3.Error(),
+			Error:   err.Error(),
 			Status:  utils.FailureStr,
-		}, nil, err3
+		}, nil, err
+	}
+	payload = mergeCalicoNetworkPolicy(payload, netpol, event.GetRemoteIP())
+	_, err = calicoClient.ProjectcalicoV3().NetworkPolicies(namespace).Update(context.Background(), payload, metav1.UpdateOptions{})
+	if err != nil {
+		return utils.LogLine{
+			Objects: objects,
+			Error:   err.Error(),
+			Status:  utils.FailureStr,
+		}, nil, err
+	}
+	objects["caliconetworpolicy"] = owner
+	output = fmt.Sprintf("the caliconetworkpolicy '%v' in the namespace '%v' has been updated", owner, namespace)
+
+	return utils.LogLine{
+		Objects: objects,
+		Output:  output,
+		Status:  utils.SuccessStr,
+	}, nil, nil
+}
+
+func (a Actionner) Plan(event *events.Event, action *rules.Action) (utils.LogLine, error) {
+	podName := event.GetPodName()
+	namespace := event.GetNamespaceName()
+
+	var parameters Parameters
+	err := utils.DecodeParams(action.GetParameters(), &parameters)
+	if err != nil {
+		return utils.LogLine{}, err
+	}
+
+	objects := map[string]string{
+		"pod":       podName,
+		"namespace": namespace,
+	}
+
+	payload, err := buildCalicoNetworkPolicy(event, &parameters)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+	owner := payload.Name
+	objects["caliconetworpolicy"] = owner
+
+	calicoClient, err := calico.GetClientFor(event)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+
+	netpol, err := calicoClient.ProjectcalicoV3().NetworkPolicies(namespace).Get(context.Background(), owner, metav1.GetOptions{})
+	if errorsv1.IsNotFound(err) {
+		diff, err2 := plan.Diff(nil, planView(payload))
+		if err2 != nil {
+			return utils.LogLine{Objects: objects}, err2
+		}
+		return utils.LogLine{
+			Objects: objects,
+			Output:  fmt.Sprintf("the caliconetworkpolicy '%v' in the namespace '%v' would be created:\n%v", owner, namespace, diff),
+		}, nil
+	}
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
 	}
 
-	delete(labels, "pod-template-hash")
-	delete(labels, "pod-template-generation")
-	delete(labels, "controller-revision-hash")
+	diff, err := plan.Diff(planView(netpol), planView(mergeCalicoNetworkPolicy(payload, netpol, event.GetRemoteIP())))
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+	if !plan.Changed(diff) {
+		return utils.LogLine{
+			Objects: objects,
+			Output:  fmt.Sprintf("the caliconetworkpolicy '%v' in the namespace '%v' is already up to date", owner, namespace),
+		}, nil
+	}
+	return utils.LogLine{
+		Objects: objects,
+		Output:  fmt.Sprintf("the caliconetworkpolicy '%v' in the namespace '%v' would be updated:\n%v", owner, namespace, diff),
+	}, nil
+}
+
+// buildCalicoNetworkPolicy returns the calico network policy blocking the
+// egress traffic of the owner of the pod to the remote IP of the event.
+func buildCalicoNetworkPolicy(event *events.Event, parameters *Parameters) (*networkingv3.NetworkPolicy, error) {
+	podName := event.GetPodName()
+	namespace := event.GetNamespaceName()
+
+	k8sClient, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return nil, err
+	}
+
+	pod, err := k8sClient.GetPod(podName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	workload, err := k8sClient.GetWorkload(pod)
+	if err != nil {
+		return nil, err
+	}
+	owner := workload.Name
+	labels := workload.Selector
+
+	if len(labels) == 0 {
+		err2 := fmt.Errorf("can't find the labels of the %v for the pod '%v' in the namespace '%v'", workload, podName, namespace)
+		return nil, err2
+	}
 	labels[managedByStr] = utils.FalcoTalonStr
 
-	payload := networkingv3.NetworkPolicy{
+	payload := &networkingv3.NetworkPolicy{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      owner,
 			Namespace: namespace,
@@ -270,67 +363,15 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 		}
 		allowNamespacesRule = nil
 	} else {
-		allowCIDRRule = createAllowCIDREgressRule(&parameters)
-		allowNamespacesRule = createAllowNamespaceEgressRule(&parameters)
+		allowCIDRRule = createAllowCIDREgressRule(parameters)
+		allowNamespacesRule = createAllowNamespaceEgressRule(parameters)
 	}
 
 	denyRule := createDenyEgressRule([]string{event.GetRemoteIP() + mask32})
 	if denyRule == nil {
-		err2 := fmt.Errorf("can't create the rule for the networkpolicy '%v' in the namespace '%v'", owner, namespace)
-		return utils.LogLine{
-			Objects: objects,
-			Error:   err2.Error(),
-			Status:  utils.FailureStr,
-		}, nil, err2
+		return nil, fmt.Errorf("can't create the rule for the networkpolicy '%v' in the namespace '%v'", owner, namespace)
 	}
 
-	var output string
-	var netpol *networkingv3.NetworkPolicy
-	netpol, err = calicoClient.ProjectcalicoV3().NetworkPolicies(namespace).Get(context.Background(), owner, metav1.GetOptions{})
-	if errorsv1.IsNotFound(err) {
-		payload.Spec.Egress = []networkingv3.Rule{*denyRule}
-		if allowCIDRRule != nil {
-			payload.Spec.Egress = append(payload.Spec.Egress, *allowCIDRRule)
-		}
-		if allowNamespacesRule != nil {
-			payload.Spec.Egress = append(payload.Spec.Egress, *allowNamespacesRule)
-		}
-		_, err2 := calicoClient.ProjectcalicoV3().NetworkPolicies(namespace).Create(context.Background(), &payload, metav1.CreateOptions{})
-		if err2 != nil {
-			if !errorsv1.IsAlreadyExists(err2) {
-				return utils.LogLine{
-					Objects: objects,
-					Error:   err2.Error(),
-					Status:  utils.FailureStr,
-				}, nil, err2
-			}
-			netpol, err = calicoClient.ProjectcalicoV3().NetworkPolicies(namespace).Get(context.Background(), owner, metav1.GetOptions{})
-		} else {
-			output = fmt.Sprintf("the caliconetworkpolicy '%v' in the namespace '%v' has been created", owner, namespace)
-			return utils.LogLine{
-				Objects: objects,
-				Output:  output,
-				Status:  utils.SuccessStr,
-			}, nil, nil
-		}
-	}
-	if err != nil {
-		return utils.LogLine{
-			Objects: objects,
-			Error:   err.Error(),
-			Status:  utils.FailureStr,
-		}, nil, err
-	}
-	payload.ResourceVersion = netpol.ResourceVersion
-	var denyCIDR []string
-	for _, i := range netpol.Spec.Egress {
-		if i.Action == actionDeny {
-			denyCIDR = append(denyCIDR, i.Destination.Nets...)
-		}
-	}
-	denyCIDR = append(denyCIDR, event.GetRemoteIP()+mask32)
-	denyCIDR = utils.Deduplicate(denyCIDR)
-	denyRule = createDenyEgressRule(denyCIDR)
 	payload.Spec.Egress = []networkingv3.Rule{*denyRule}
 	if allowCIDRRule != nil {
 		payload.Spec.Egress = append(payload.Spec.Egress, *allowCIDRRule)
@@ -338,22 +379,38 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 	if allowNamespacesRule != nil {
 		payload.Spec.Egress = append(payload.Spec.Egress, *allowNamespacesRule)
 	}
-	_, err = calicoClient.ProjectcalicoV3().NetworkPolicies(namespace).Update(context.Background(), &payload, metav1.UpdateOptions{})
-	if err != nil {
-		return utils.LogLine{
-			Objects: objects,
-			Error:   err.Error(),
-			Status:  utils.FailureStr,
-		}, nil, err
+
+	return payload, nil
+}
+
+// mergeCalicoNetworkPolicy returns the payload updating the current calico
+// network policy, the CIDRs it already denies are kept.
+func mergeCalicoNetworkPolicy(payload, current *networkingv3.NetworkPolicy, remoteIP string) *networkingv3.NetworkPolicy {
+	merged := payload.DeepCopy()
+	merged.ResourceVersion = current.ResourceVersion
+	var denyCIDR []string
+	for _, i := range current.Spec.Egress {
+		if i.Action == actionDeny {
+			denyCIDR = append(denyCIDR, i.Destination.Nets...)
+		}
 	}
-	objects["caliconetworpolicy"] = owner
-	output = fmt.Sprintf("the caliconetworkpolicy '%v' in the namespace '%v' has been updated", owner, namespace)
+	denyCIDR = append(denyCIDR, remoteIP+mask32)
+	denyCIDR = utils.Deduplicate(denyCIDR)
+	merged.Spec.Egress = append([]networkingv3.Rule{*createDenyEgressRule(denyCIDR)}, payload.Spec.Egress[1:]...)
+	return merged
+}
 
-	return utils.LogLine{
-		Objects: objects,
-		Output:  output,
-		Status:  utils.SuccessStr,
-	}, nil, nil
+// planView keeps the fields of the calico network policy set by the
+// actionner, the ones set by the API server are not part of the plans.
+func planView(np *networkingv3.NetworkPolicy) *networkingv3.NetworkPolicy {
+	return &networkingv3.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      np.Name,
+			Namespace: np.Namespace,
+			Labels:    np.Labels,
+		},
+		Spec: np.Spec,
+	}
 }
 
 func createAllowCIDREgressRule(parameters *Parameters) *networkingv3.Rule {
@@ -11,12 +11,13 @@ import (
 	errorsv1 "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/falcosecurity/falco-talon/configuration"
 	cilium "github.com/falcosecurity/falco-talon/internal/cilium/client"
 	"github.com/falcosecurity/falco-talon/internal/models"
+	"github.com/falcosecurity/falco-talon/internal/plan"
 
 	"github.com/falcosecurity/falco-talon/internal/events"
 	k8sChecks "github.com/falcosecurity/falco-talon/internal/kubernetes/checks"
-	k8s "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
 	"github.com/falcosecurity/falco-talon/internal/rules"
 	"github.com/falcosecurity/falco-talon/utils"
 )
@@ -75,6 +76,13 @@ rules:
   - statefulsets
   verbs:
   - get
+- apiGroups:
+  - batch
+  resources:
+  - jobs
+  - cronjobs
+  verbs:
+  - get
 `
 	Example string = `- action: Create Cilium netpol
 actionner: cilium:networkpolicy
@@ -111,7 +119,7 @@ func Register() *Actionner {
 	return new(Actionner)
 }
 
-func (a Actionner) Init() error {
+func (a Actionner) Init(_ *configuration.Configuration) error {
 	return cilium.Init()
 }
 
@@ -162,10 +170,19 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 		"namespace": namespace,
 	}
 
-	k8sClient := k8s.GetClient()
-	ciliumClient := cilium.GetClient()
+	payload, current, err := buildCiliumNetworkPolicy(event, &parameters)
+	if err != nil {
+		return utils.LogLine{
+				Objects: objects,
+				Error:   err.Error(),
+				Status:  utils.FailureStr,
+			},
+			nil,
+			err
+	}
+	owner := payload.Name
 
-	pod, err := k8sClient.GetPod(podName, namespace)
+	ciliumClient, err := cilium.GetClientFor(event)
 	if err != nil {
 		return utils.LogLine{
 				Objects: objects,
@@ -176,70 +193,131 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 			err
 	}
 
-	var owner string
-	labels := make(map[string]string)
-
-	if len(pod.OwnerReferences) != 0 {
-		switch pod.OwnerReferences[0].Kind {
-		case "DaemonSet":
-			u, err2 := k8sClient.GetDaemonsetFromPod(pod)
-			if err2 != nil {
-				return utils.LogLine{
-						Objects: objects,
-						Error:   err2.Error(),
-						Status:  utils.FailureStr,
-					},
-					nil,
-					err2
-			}
-			owner = u.Name
-			labels = u.Spec.Selector.MatchLabels
-		case "StatefulSet":
-			u, err2 := k8sClient.GetStatefulsetFromPod(pod)
-			if err2 != nil {
-				return utils.LogLine{
-						Objects: objects,
-						Error:   err2.Error(),
-						Status:  utils.FailureStr,
-					},
-					nil,
-					err2
-			}
-			owner = u.Name
-			labels = u.Spec.Selector.MatchLabels
-		case "ReplicaSet":
-			u, err2 := k8sClient.GetReplicasetFromPod(pod)
-			if err2 != nil {
-				return utils.LogLine{
-						Objects: objects,
-						Error:   err2.Error(),
-						Status:  utils.FailureStr,
-					},
-					nil,
-					err2
-			}
-			owner = u.Name
-			labels = u.Spec.Selector.MatchLabels
+	var output string
+	if current == nil {
+		_, err = ciliumClient.CiliumV2().CiliumNetworkPolicies(namespace).Create(context.Background(), payload, metav1.CreateOptions{})
+		if err != nil {
+			return utils.LogLine{
+					Objects: objects,
+					Error:   err.Error(),
+					Status:  utils.FailureStr,
+				},
+				nil,
+				err
 		}
-	} else {
-		owner = pod.Name
-		labels = pod.Labels
+		output = fmt.Sprintf("the ciliumnetworkpolicy '%v' in the namespace '%v' has been created", owner, namespace)
+		return utils.LogLine{
+				Objects: objects,
+				Output:  output,
+				Status:  utils.SuccessStr,
+			},
+			nil,
+			nil
 	}
 
-	if owner == "" || len(labels) == 0 {
-		err3 := fmt.Errorf("can't find the owner and/or labels for the pod '%v' in the namespace '%v'", podName, namespace)
+	_, err = ciliumClient.CiliumV2().CiliumNetworkPolicies(namespace).Update(context.Background(), payload, metav1.UpdateOptions{})
+	if err != nil {
 		return utils.LogLine{
 				Objects: objects,
-				Error:   err3.Error(),
+				Error:   err.Error(),
 				Status:  utils.FailureStr,
 			},
 			nil,
-			err3
+			err
+	}
+	output = fmt.Sprintf("the ciliumnetworkpolicy '%v' in the namespace '%v' has been updated", owner, namespace)
+	objects["NetworkPolicy"] = owner
+
+	return utils.LogLine{
+			Objects: objects,
+			Output:  output,
+			Status:  utils.SuccessStr,
+		},
+		nil,
+		nil
+}
+
+func (a Actionner) Plan(event *events.Event, action *rules.Action) (utils.LogLine, error) {
+	podName := event.GetPodName()
+	namespace := event.GetNamespaceName()
+
+	var parameters Parameters
+	err := utils.DecodeParams(action.GetParameters(), &parameters)
+	if err != nil {
+		return utils.LogLine{}, err
+	}
+
+	objects := map[string]string{
+		"pod":       podName,
+		"namespace": namespace,
+	}
+
+	payload, current, err := buildCiliumNetworkPolicy(event, &parameters)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+	owner := payload.Name
+	objects["NetworkPolicy"] = owner
+
+	if current == nil {
+		diff, err2 := plan.Diff(nil, planView(payload))
+		if err2 != nil {
+			return utils.LogLine{Objects: objects}, err2
+		}
+		return utils.LogLine{
+			Objects: objects,
+			Output:  fmt.Sprintf("the ciliumnetworkpolicy '%v' in the namespace '%v' would be created:\n%v", owner, namespace, diff),
+		}, nil
+	}
+
+	diff, err := plan.Diff(planView(current), planView(payload))
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+	if !plan.Changed(diff) {
+		return utils.LogLine{
+			Objects: objects,
+			Output:  fmt.Sprintf("the ciliumnetworkpolicy '%v' in the namespace '%v' is already up to date", owner, namespace),
+		}, nil
+	}
+	return utils.LogLine{
+		Objects: objects,
+		Output:  fmt.Sprintf("the ciliumnetworkpolicy '%v' in the namespace '%v' would be updated:\n%v", owner, namespace, diff),
+	}, nil
+}
+
+// buildCiliumNetworkPolicy returns the cilium network policy blocking the
+// egress traffic of the owner of the pod to the remote IP of the event, and
+// the current one if it exists, to be updated.
+func buildCiliumNetworkPolicy(event *events.Event, parameters *Parameters) (payload, current *v2.CiliumNetworkPolicy, err error) {
+	podName := event.GetPodName()
+	namespace := event.GetNamespaceName()
+
+	k8sClient, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return nil, nil, err
+	}
+	ciliumClient, err := cilium.GetClientFor(event)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	delete(labels, "pod-template-hash")
-	delete(labels, "pod-template-generation")
-	delete(labels, "controller-revision-hash")
+	pod, err := k8sClient.GetPod(podName, namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	workload, err := k8sClient.GetWorkload(pod)
+	if err != nil {
+		return nil, nil, err
+	}
+	owner := workload.Name
+	labels := workload.Selector
+
+	if len(labels) == 0 {
+		err2 := fmt.Errorf("can't find the labels of the %v for the pod '%v' in the namespace '%v'", workload, podName, namespace)
+		return nil, nil, err2
+	}
 
 	resourceLabels := make(map[string]string)
 	for key, value := range labels {
@@ -247,7 +325,7 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 	}
 	resourceLabels[managedByStr] = utils.FalcoTalonStr
 
-	payload := v2.CiliumNetworkPolicy{
+	payload = &v2.CiliumNetworkPolicy{
 		TypeMeta: metav1.TypeMeta{},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      owner,
@@ -273,26 +351,16 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 			},
 		}
 	} else {
-		allowCIDRRule = createAllowCIDREgressRule(parameters)
-		allowNamespacesRule = createAllowNamespaceEgressRule(parameters)
+		allowCIDRRule = createAllowCIDREgressRule(*parameters)
+		allowNamespacesRule = createAllowNamespaceEgressRule(*parameters)
 	}
 
 	denyRule := createDenyEgressRule([]string{event.GetRemoteIP() + mask32})
 	if denyRule == nil {
-		err2 := fmt.Errorf("can't create deny rule for the networkpolicy '%v' in the namespace '%v'", owner, namespace)
-		return utils.LogLine{
-				Objects: objects,
-				Error:   err2.Error(),
-				Status:  utils.FailureStr,
-			},
-			nil,
-			err2
+		return nil, nil, fmt.Errorf("can't create deny rule for the networkpolicy '%v' in the namespace '%v'", owner, namespace)
 	}
 
-	var output string
-	var netpol *v2.CiliumNetworkPolicy
-
-	netpol, err = ciliumClient.CiliumV2().CiliumNetworkPolicies(namespace).Get(context.Background(), owner, metav1.GetOptions{})
+	current, err = ciliumClient.CiliumV2().CiliumNetworkPolicies(namespace).Get(context.Background(), owner, metav1.GetOptions{})
 	if errorsv1.IsNotFound(err) {
 		payload.Spec.EgressDeny = []api.EgressDenyRule{*denyRule}
 		if allowCIDRRule != nil {
@@ -301,40 +369,15 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 		if allowNamespacesRule != nil {
 			payload.Spec.Egress = append(payload.Spec.Egress, *allowNamespacesRule)
 		}
-		_, err2 := ciliumClient.CiliumV2().CiliumNetworkPolicies(namespace).Create(context.Background(), &payload, metav1.CreateOptions{})
-		if err2 != nil {
-			return utils.LogLine{
-					Objects: objects,
-					Error:   err2.Error(),
-					Status:  utils.FailureStr,
-				},
-				nil,
-				err2
-		}
-		output = fmt.Sprintf("the ciliumnetworkpolicy '%v' in the namespace '%v' has been created", owner, namespace)
-		return utils.LogLine{
-				Objects: objects,
-				Output:  output,
-				Status:  utils.SuccessStr,
-			},
-			nil,
-			nil
+		return payload, nil, nil
 	}
 	if err != nil {
-		return utils.LogLine{
-				Objects: objects,
-				Error:   err.Error(),
-				Status:  utils.FailureStr,
-			},
-			nil,
-			err
+		return nil, nil, err
 	}
 
-	payload.ResourceVersion = netpol.ResourceVersion
-	payload.Spec.Egress = netpol.Spec.Egress
-	payload.Spec.EgressDeny = netpol.Spec.EgressDeny
-
-	denyRule = createDenyEgressRule([]string{event.GetRemoteIP() + mask32})
+	payload.ResourceVersion = current.ResourceVersion
+	payload.Spec.Egress = current.Spec.Egress
+	payload.Spec.EgressDeny = current.Spec.EgressDeny
 
 	for i := range payload.Spec.EgressDeny {
 		if !denyEgressRuleExists(&payload.Spec.EgressDeny[i], denyRule) {
@@ -351,26 +394,20 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 		}
 	}
 
-	_, err = ciliumClient.CiliumV2().CiliumNetworkPolicies(namespace).Update(context.Background(), &payload, metav1.UpdateOptions{})
-	if err != nil {
-		return utils.LogLine{
-				Objects: objects,
-				Error:   err.Error(),
-				Status:  utils.FailureStr,
-			},
-			nil,
-			err
-	}
-	output = fmt.Sprintf("the ciliumnetworkpolicy '%v' in the namespace '%v' has been updated", owner, namespace)
-	objects["NetworkPolicy"] = owner
+	return payload, current, nil
+}
 
-	return utils.LogLine{
-			Objects: objects,
-			Output:  output,
-			Status:  utils.SuccessStr,
+// planView keeps the fields of the cilium network policy set by the
+// actionner, the ones set by the API server are not part of the plans.
+func planView(cnp *v2.CiliumNetworkPolicy) *v2.CiliumNetworkPolicy {
+	return &v2.CiliumNetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cnp.Name,
+			Namespace: cnp.Namespace,
+			Labels:    cnp.Labels,
 		},
-		nil,
-		nil
+		Spec: cnp.Spec,
+	}
 }
 
 func createAllowNamespaceEgressRule(parameters Parameters) *api.EgressRule {
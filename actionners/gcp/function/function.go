@@ -13,6 +13,7 @@ import (
 	"cloud.google.com/go/functions/apiv2/functionspb"
 	"google.golang.org/api/idtoken"
 
+	"github.com/falcosecurity/falco-talon/configuration"
 	"github.com/falcosecurity/falco-talon/internal/events"
 	"github.com/falcosecurity/falco-talon/internal/gcp/checks"
 	"github.com/falcosecurity/falco-talon/internal/gcp/client"
@@ -58,8 +59,8 @@ func Register() *Actionner {
 	return new(Actionner)
 }
 
-func (a Actionner) Init() error {
-	return client.Init()
+func (a Actionner) Init(config *configuration.Configuration) error {
+	return client.Init(config.GcpConfig)
 }
 
 func (a Actionner) Information() models.Information {
@@ -107,6 +108,28 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 	return a.RunWithClient(gcpClient, event, action)
 }
 
+func (a Actionner) Plan(event *events.Event, action *rules.Action) (utils.LogLine, error) {
+	var parameters Parameters
+	err := utils.DecodeParams(action.GetParameters(), &parameters)
+	if err != nil {
+		return utils.LogLine{}, err
+	}
+
+	objects := map[string]string{
+		"name":     parameters.GCPFunctionName,
+		"location": parameters.GCPFunctionLocation,
+	}
+
+	if err := a.Checks(event, action); err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+
+	return utils.LogLine{
+		Objects: objects,
+		Output:  fmt.Sprintf("the function '%v' in the location '%v' would be called with the event as payload", parameters.GCPFunctionName, parameters.GCPFunctionLocation),
+	}, nil
+}
+
 func (a Actionner) CheckParameters(action *rules.Action) error {
 	var parameters Parameters
 	err := utils.DecodeParams(action.GetParameters(), &parameters)
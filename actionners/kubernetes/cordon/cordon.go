@@ -7,6 +7,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/falcosecurity/falco-talon/configuration"
 	"github.com/falcosecurity/falco-talon/internal/events"
 	k8sChecks "github.com/falcosecurity/falco-talon/internal/kubernetes/checks"
 	k8s "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
@@ -67,7 +68,7 @@ func Register() *Actionner {
 	return new(Actionner)
 }
 
-func (a Actionner) Init() error {
+func (a Actionner) Init(_ *configuration.Configuration) error {
 	return k8s.Init()
 }
 
@@ -100,7 +101,14 @@ func (a Actionner) Run(event *events.Event, _ *rules.Action) (utils.LogLine, *mo
 
 	objects := map[string]string{}
 
-	client := k8s.GetClient()
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{
+			Objects: objects,
+			Error:   err.Error(),
+			Status:  utils.FailureStr,
+		}, nil, err
+	}
 
 	pod, err := client.GetPod(podName, namespace)
 	if err != nil {
@@ -140,4 +148,41 @@ func (a Actionner) Run(event *events.Event, _ *rules.Action) (utils.LogLine, *mo
 	}, nil, nil
 }
 
+func (a Actionner) Plan(event *events.Event, _ *rules.Action) (utils.LogLine, error) {
+	podName := event.GetPodName()
+	namespace := event.GetNamespaceName()
+
+	objects := map[string]string{}
+
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+
+	pod, err := client.GetPod(podName, namespace)
+	if err != nil {
+		objects["pod"] = podName
+		objects["namespace"] = namespace
+		return utils.LogLine{Objects: objects}, err
+	}
+
+	node, err := client.GetNodeFromPod(pod)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+
+	objects["node"] = node.Name
+
+	if node.Spec.Unschedulable {
+		return utils.LogLine{
+			Objects: objects,
+			Output:  fmt.Sprintf("the node '%v' is already cordoned", node.Name),
+		}, nil
+	}
+	return utils.LogLine{
+		Objects: objects,
+		Output:  fmt.Sprintf("the node '%v' would be cordoned", node.Name),
+	}, nil
+}
+
 func (a Actionner) CheckParameters(_ *rules.Action) error { return nil }
@@ -7,6 +7,7 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/falcosecurity/falco-talon/configuration"
 	"github.com/falcosecurity/falco-talon/internal/events"
 	k8sChecks "github.com/falcosecurity/falco-talon/internal/kubernetes/checks"
 	k8s "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
@@ -118,7 +119,7 @@ func Register() *Actionner {
 	return new(Actionner)
 }
 
-func (a Actionner) Init() error {
+func (a Actionner) Init(_ *configuration.Configuration) error {
 	return k8s.Init()
 }
 
@@ -156,9 +157,14 @@ func (a Actionner) Run(event *events.Event, _ *rules.Action) (utils.LogLine, *mo
 		"namespace": namespace,
 	}
 
-	client := k8s.GetClient()
-
-	var err error
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{
+			Objects: objects,
+			Error:   err.Error(),
+			Status:  utils.FailureStr,
+		}, nil, err
+	}
 
 	switch resource {
 	case namespaces:
@@ -207,4 +213,36 @@ func (a Actionner) Run(event *events.Event, _ *rules.Action) (utils.LogLine, *mo
 	}, nil, nil
 }
 
+func (a Actionner) Plan(event *events.Event, _ *rules.Action) (utils.LogLine, error) {
+	name := event.GetTargetName()
+	resource := event.GetTargetResource()
+	namespace := event.GetTargetNamespace()
+
+	objects := map[string]string{
+		"name":      name,
+		"resource":  resource,
+		"namespace": namespace,
+	}
+
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+	if _, err := client.GetTarget(resource, name, namespace); err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+
+	var output string
+	if resource == namespaces {
+		output = fmt.Sprintf("the %v '%v' would be deleted", strings.TrimSuffix(resource, "s"), name)
+	} else {
+		output = fmt.Sprintf("the %v '%v' in the namespace '%v' would be deleted", strings.TrimSuffix(resource, "s"), name, namespace)
+	}
+
+	return utils.LogLine{
+		Objects: objects,
+		Output:  output,
+	}, nil
+}
+
 func (a Actionner) CheckParameters(_ *rules.Action) error { return nil }
@@ -3,8 +3,8 @@ package copy
 import (
 	"bytes"
 	"fmt"
-	"os"
 
+	"github.com/falcosecurity/falco-talon/configuration"
 	"github.com/falcosecurity/falco-talon/internal/events"
 	k8sChecks "github.com/falcosecurity/falco-talon/internal/kubernetes/checks"
 	k8s "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
@@ -68,7 +68,7 @@ func Register() *Actionner {
 	return new(Actionner)
 }
 
-func (a Actionner) Init() error {
+func (a Actionner) Init(_ *configuration.Configuration) error {
 	return k8s.Init()
 }
 
@@ -119,14 +119,26 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 	file := new(string)
 	*file = parameters.File
 
-	event.ExportEnvVars()
-	*file = os.ExpandEnv(*file)
+	*file = event.ExpandEnv(*file)
 
 	objects["file"] = *file
 
-	client := k8s.GetClient()
-
-	p, _ := client.GetPod(pod, namespace)
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{
+			Objects: objects,
+			Error:   err.Error(),
+			Status:  utils.FailureStr,
+		}, nil, err
+	}
+	p, err := client.GetPod(pod, namespace)
+	if err != nil {
+		return utils.LogLine{
+			Objects: objects,
+			Error:   err.Error(),
+			Status:  utils.FailureStr,
+		}, nil, err
+	}
 	containers := k8s.GetContainers(p)
 	if len(containers) == 0 {
 		err = fmt.Errorf("no container found")
@@ -161,6 +173,40 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 	}, &models.Data{Name: *file, Objects: objects, Bytes: output.Bytes()}, nil
 }
 
+func (a Actionner) Plan(event *events.Event, action *rules.Action) (utils.LogLine, error) {
+	pod := event.GetPodName()
+	namespace := event.GetNamespaceName()
+
+	objects := map[string]string{
+		"pod":       pod,
+		"namespace": namespace,
+	}
+
+	var parameters Parameters
+	err := utils.DecodeParams(action.GetParameters(), &parameters)
+	if err != nil {
+		return utils.LogLine{}, err
+	}
+
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+	p, err := client.GetPod(pod, namespace)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+	containers := k8s.GetContainers(p)
+	if len(containers) == 0 {
+		return utils.LogLine{Objects: objects}, fmt.Errorf("no container found")
+	}
+
+	return utils.LogLine{
+		Objects: objects,
+		Output:  fmt.Sprintf("the file '%v' would be downloaded from the pod '%v' in the namespace '%v', from the containers %v until it's found", event.ExpandEnv(parameters.File), pod, namespace, containers),
+	}, nil
+}
+
 func (a Actionner) CheckParameters(action *rules.Action) error {
 	var parameters Parameters
 
@@ -2,6 +2,7 @@ package drain
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -10,6 +11,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/falcosecurity/falco-talon/actionners/kubernetes/helpers"
+	"github.com/falcosecurity/falco-talon/configuration"
 
 	k8s "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
 
@@ -79,7 +81,7 @@ func Register() *Actionner {
 	return new(Actionner)
 }
 
-func (a Actionner) Init() error {
+func (a Actionner) Init(_ *configuration.Configuration) error {
 	return k8s.Init()
 }
 
@@ -114,7 +116,13 @@ func (a Actionner) Checks(event *events.Event, _ *rules.Action) error {
 }
 
 func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine, *models.Data, error) {
-	client := k8s.GetClient()
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{
+			Error:  err.Error(),
+			Status: utils.FailureStr,
+		}, nil, err
+	}
 	return a.RunWithClient(*client, event, action)
 }
 
@@ -326,6 +334,77 @@ func (a Actionner) RunWithClient(client k8s.Client, event *events.Event, action
 	}, nil, fmt.Errorf("the node '%v' has not been fully drained: %v pods ignored, %v eviction errors, %v were not evicted during max_wait_period, %v other errors", nodeName, ignoredPodsCount, evictionErrorsCount, evictionWaitPeriodErrorsCount, otherErrorsCount)
 }
 
+func (a Actionner) Plan(event *events.Event, action *rules.Action) (utils.LogLine, error) {
+	podName := event.GetPodName()
+	namespace := event.GetNamespaceName()
+	objects := map[string]string{}
+
+	var parameters Parameters
+	err := utils.DecodeParams(action.GetParameters(), &parameters)
+	if err != nil {
+		return utils.LogLine{}, err
+	}
+
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+
+	pod, err := client.GetPod(podName, namespace)
+	if err != nil {
+		objects["pod"] = podName
+		objects["namespace"] = namespace
+		return utils.LogLine{Objects: objects}, err
+	}
+
+	node, err := client.GetNodeFromPod(pod)
+	if err != nil {
+		objects["pod"] = podName
+		objects["namespace"] = namespace
+		return utils.LogLine{Objects: objects}, err
+	}
+	nodeName := node.GetName()
+	objects["node"] = nodeName
+
+	pods, err := client.ListPods(metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("spec.nodeName=%s", nodeName),
+	})
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+
+	var evicted []string
+	var ignoredPodsCount int
+	for _, p := range pods.Items {
+		switch k8s.PodKind(p) {
+		case utils.DaemonSetStr:
+			if parameters.IgnoreDaemonsets {
+				ignoredPodsCount++
+				continue
+			}
+		case utils.StatefulSetStr:
+			if parameters.IgnoreStatefulSets {
+				ignoredPodsCount++
+				continue
+			}
+		}
+		evicted = append(evicted, fmt.Sprintf("- %s/%s", p.Namespace, p.Name))
+	}
+
+	output := fmt.Sprintf("the node '%v' would be drained: %v pods would be evicted, %v ignored", nodeName, len(evicted), ignoredPodsCount)
+	if parameters.MinHealthyReplicas != "" {
+		output += fmt.Sprintf(", the pods of the ReplicaSets with less than %v healthy replicas would be ignored too", parameters.MinHealthyReplicas)
+	}
+	if len(evicted) != 0 {
+		output += ":\n" + strings.Join(evicted, "\n")
+	}
+
+	return utils.LogLine{
+		Objects: objects,
+		Output:  output,
+	}, nil
+}
+
 func (a Actionner) CheckParameters(action *rules.Action) error {
 	var parameters Parameters
 	err := utils.DecodeParams(action.GetParameters(), &parameters)
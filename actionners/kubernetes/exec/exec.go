@@ -3,8 +3,8 @@ package exec
 import (
 	"bytes"
 	"fmt"
-	"os"
 
+	"github.com/falcosecurity/falco-talon/configuration"
 	"github.com/falcosecurity/falco-talon/internal/events"
 	k8sChecks "github.com/falcosecurity/falco-talon/internal/kubernetes/checks"
 	k8s "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
@@ -67,7 +67,7 @@ func Register() *Actionner {
 	return new(Actionner)
 }
 
-func (a Actionner) Init() error {
+func (a Actionner) Init(_ *configuration.Configuration) error {
 	return k8s.Init()
 }
 
@@ -126,12 +126,24 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 	command := new(string)
 	*command = parameters.Command
 
-	event.ExportEnvVars()
-	*command = os.ExpandEnv(*command)
+	*command = event.ExpandEnv(*command)
 
-	client := k8s.GetClient()
-
-	p, _ := client.GetPod(pod, namespace)
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{
+			Objects: objects,
+			Error:   err.Error(),
+			Status:  utils.FailureStr,
+		}, nil, err
+	}
+	p, err := client.GetPod(pod, namespace)
+	if err != nil {
+		return utils.LogLine{
+			Objects: objects,
+			Error:   err.Error(),
+			Status:  utils.FailureStr,
+		}, nil, err
+	}
 	containers := k8s.GetContainers(p)
 	if len(containers) == 0 {
 		err = fmt.Errorf("no container found")
@@ -165,6 +177,45 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 	}, nil, nil
 }
 
+func (a Actionner) Plan(event *events.Event, action *rules.Action) (utils.LogLine, error) {
+	pod := event.GetPodName()
+	namespace := event.GetNamespaceName()
+
+	objects := map[string]string{
+		"pod":       pod,
+		"namespace": namespace,
+	}
+
+	var parameters Parameters
+	err := utils.DecodeParams(action.GetParameters(), &parameters)
+	if err != nil {
+		return utils.LogLine{}, err
+	}
+
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+	p, err := client.GetPod(pod, namespace)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+	containers := k8s.GetContainers(p)
+	if len(containers) == 0 {
+		return utils.LogLine{Objects: objects}, fmt.Errorf("no container found")
+	}
+
+	shell := binSh
+	if parameters.Shell != "" {
+		shell = parameters.Shell
+	}
+
+	return utils.LogLine{
+		Objects: objects,
+		Output:  fmt.Sprintf("the command '%v' would be run with '%v' in the pod '%v' in the namespace '%v', in the containers %v until it succeeds", event.ExpandEnv(parameters.Command), shell, pod, namespace, containers),
+	}, nil
+}
+
 func (a Actionner) CheckParameters(action *rules.Action) error {
 	var parameters Parameters
 	err := utils.DecodeParams(action.GetParameters(), &parameters)
@@ -11,10 +11,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/falcosecurity/falco-talon/configuration"
 	"github.com/falcosecurity/falco-talon/internal/events"
 	k8sChecks "github.com/falcosecurity/falco-talon/internal/kubernetes/checks"
 	k8s "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
 	"github.com/falcosecurity/falco-talon/internal/models"
+	"github.com/falcosecurity/falco-talon/internal/plan"
 	"github.com/falcosecurity/falco-talon/internal/rules"
 	"github.com/falcosecurity/falco-talon/utils"
 )
@@ -79,7 +81,7 @@ func Register() *Actionner {
 	return new(Actionner)
 }
 
-func (a Actionner) Init() error {
+func (a Actionner) Init(_ *configuration.Configuration) error {
 	return k8s.Init()
 }
 
@@ -127,7 +129,14 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 		}, nil, err
 	}
 
-	client := k8s.GetClient()
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{
+			Objects: objects,
+			Error:   err.Error(),
+			Status:  utils.FailureStr,
+		}, nil, err
+	}
 
 	var kind string
 	var node *corev1.Node
@@ -249,6 +258,70 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 	}, nil, nil
 }
 
+func (a Actionner) Plan(event *events.Event, action *rules.Action) (utils.LogLine, error) {
+	podName := event.GetPodName()
+	namespace := event.GetNamespaceName()
+
+	objects := map[string]string{}
+
+	var parameters Parameters
+	err := utils.DecodeParams(action.GetParameters(), &parameters)
+	if err != nil {
+		return utils.LogLine{}, err
+	}
+
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+
+	pod, err := client.GetPod(podName, namespace)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+
+	var target string
+	var current map[string]string
+	if parameters.Level == nodeStr {
+		node, err2 := client.GetNodeFromPod(pod)
+		if err2 != nil {
+			return utils.LogLine{Objects: objects}, err2
+		}
+		objects[nodeStr] = node.Name
+		target = fmt.Sprintf("the node '%v'", node.Name)
+		current = node.Labels
+	} else {
+		objects[podStr] = podName
+		objects["namespace"] = namespace
+		target = fmt.Sprintf("the pod '%v' in the namespace '%v'", podName, namespace)
+		current = pod.Labels
+	}
+
+	labels := make(map[string]string, len(current))
+	for i, j := range current {
+		labels[i] = j
+	}
+	for i, j := range parameters.Labels {
+		if fmt.Sprintf("%v", j) == "" {
+			delete(labels, i)
+			continue
+		}
+		labels[i] = fmt.Sprintf("%v", j)
+	}
+
+	diff := plan.Maps(current, labels)
+	if diff == "" {
+		return utils.LogLine{
+			Objects: objects,
+			Output:  fmt.Sprintf("the labels of %v are already set", target),
+		}, nil
+	}
+	return utils.LogLine{
+		Objects: objects,
+		Output:  fmt.Sprintf("the labels of %v would be changed:\n%v", target, diff),
+	}, nil
+}
+
 func (a Actionner) CheckParameters(action *rules.Action) error {
 	var parameters Parameters
 	err := utils.DecodeParams(action.GetParameters(), &parameters)
@@ -8,6 +8,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 
+	"github.com/falcosecurity/falco-talon/configuration"
 	"github.com/falcosecurity/falco-talon/internal/events"
 	k8sChecks "github.com/falcosecurity/falco-talon/internal/kubernetes/checks"
 	k8s "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
@@ -74,7 +75,7 @@ func Register() *Actionner {
 	return new(Actionner)
 }
 
-func (a Actionner) Init() error {
+func (a Actionner) Init(_ *configuration.Configuration) error {
 	return k8s.Init()
 }
 
@@ -128,9 +129,22 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 		*tailLines = int64(parameters.TailLines)
 	}
 
-	client := k8s.GetClient()
-
-	p, _ := client.GetPod(pod, namespace)
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{
+			Objects: objects,
+			Error:   err.Error(),
+			Status:  utils.FailureStr,
+		}, nil, err
+	}
+	p, err := client.GetPod(pod, namespace)
+	if err != nil {
+		return utils.LogLine{
+			Objects: objects,
+			Error:   err.Error(),
+			Status:  utils.FailureStr,
+		}, nil, err
+	}
 	containers := k8s.GetContainers(p)
 	if len(containers) == 0 {
 		err := fmt.Errorf("no container found")
@@ -184,6 +198,45 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 	}, &models.Data{Name: "log", Objects: objects, Bytes: output}, nil
 }
 
+func (a Actionner) Plan(event *events.Event, action *rules.Action) (utils.LogLine, error) {
+	pod := event.GetPodName()
+	namespace := event.GetNamespaceName()
+
+	objects := map[string]string{
+		"pod":       pod,
+		"namespace": namespace,
+	}
+
+	var parameters Parameters
+	err := utils.DecodeParams(action.GetParameters(), &parameters)
+	if err != nil {
+		return utils.LogLine{}, err
+	}
+
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+	p, err := client.GetPod(pod, namespace)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+	containers := k8s.GetContainers(p)
+	if len(containers) == 0 {
+		return utils.LogLine{Objects: objects}, fmt.Errorf("no container found")
+	}
+
+	tailLines := defaultTailLines
+	if parameters.TailLines > 0 {
+		tailLines = parameters.TailLines
+	}
+
+	return utils.LogLine{
+		Objects: objects,
+		Output:  fmt.Sprintf("the last %v lines of the logs of the containers %v of the pod '%v' in the namespace '%v' would be downloaded", tailLines, containers, pod, namespace),
+	}, nil
+}
+
 func (a Actionner) CheckParameters(action *rules.Action) error {
 	var parameters Parameters
 
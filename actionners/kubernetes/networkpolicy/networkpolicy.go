@@ -9,10 +9,12 @@ import (
 	errorsv1 "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/falcosecurity/falco-talon/configuration"
 	"github.com/falcosecurity/falco-talon/internal/events"
 	k8sChecks "github.com/falcosecurity/falco-talon/internal/kubernetes/checks"
 	k8s "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
 	"github.com/falcosecurity/falco-talon/internal/models"
+	"github.com/falcosecurity/falco-talon/internal/plan"
 	"github.com/falcosecurity/falco-talon/internal/rules"
 	"github.com/falcosecurity/falco-talon/utils"
 )
@@ -71,6 +73,13 @@ rules:
   - statefulsets
   verbs:
   - get
+- apiGroups:
+  - batch
+  resources:
+  - jobs
+  - cronjobs
+  verbs:
+  - get
 `
 	Example string = `- action: Create a network policy
   actionner: kubernetes:networkpolicy
@@ -101,7 +110,7 @@ func Register() *Actionner {
 	return new(Actionner)
 }
 
-func (a Actionner) Init() error {
+func (a Actionner) Init(_ *configuration.Configuration) error {
 	return k8s.Init()
 }
 
@@ -139,10 +148,17 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 		"pod":       podName,
 		"namespace": namespace,
 	}
-	client := k8s.GetClient()
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{
+			Objects: objects,
+			Error:   err.Error(),
+			Status:  utils.FailureStr,
+		}, nil, err
+	}
 
 	var parameters Parameters
-	err := utils.DecodeParams(action.GetParameters(), &parameters)
+	err = utils.DecodeParams(action.GetParameters(), &parameters)
 	if err != nil {
 		return utils.LogLine{
 			Objects: nil,
@@ -151,7 +167,7 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 		}, nil, err
 	}
 
-	pod, err := client.GetPod(podName, namespace)
+	payload, err := buildNetworkPolicy(client, podName, namespace, &parameters)
 	if err != nil {
 		return utils.LogLine{
 				Objects: objects,
@@ -161,71 +177,123 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 			nil,
 			err
 	}
+	owner := payload.Name
 
-	var owner string
-	labels := make(map[string]string)
-
-	if len(pod.OwnerReferences) != 0 {
-		switch pod.OwnerReferences[0].Kind {
-		case "DaemonSet":
-			u, err2 := client.GetDaemonsetFromPod(pod)
-			if err2 != nil {
-				return utils.LogLine{
-						Objects: objects,
-						Error:   err2.Error(),
-						Status:  utils.FailureStr,
-					},
-					nil,
-					err2
-			}
-			owner = u.Name
-			labels = u.Spec.Selector.MatchLabels
-		case "StatefulSet":
-			u, err2 := client.GetStatefulsetFromPod(pod)
-			if err2 != nil {
-				return utils.LogLine{
-						Objects: objects,
-						Error:   err2.Error(),
-						Status:  utils.FailureStr,
-					},
-					nil,
-					err2
-			}
-			owner = u.Name
-			labels = u.Spec.Selector.MatchLabels
-		case "ReplicaSet":
-			u, err2 := client.GetReplicasetFromPod(pod)
-			if err2 != nil {
-				return utils.LogLine{
-						Objects: objects,
-						Error:   err2.Error(),
-						Status:  utils.FailureStr,
-					},
-					nil,
-					err2
-			}
-			owner = u.Name
-			labels = u.Spec.Selector.MatchLabels
-		}
+	objects["networkpolicy"] = owner
+
+	var output string
+	_, err = client.Clientset.NetworkingV1().NetworkPolicies(namespace).Get(context.Background(), owner, metav1.GetOptions{})
+	if errorsv1.IsNotFound(err) {
+		_, err = client.Clientset.NetworkingV1().NetworkPolicies(namespace).Create(context.Background(), payload, metav1.CreateOptions{})
+		output = fmt.Sprintf("the networkpolicy '%v' in the namespace '%v' has been created", owner, namespace)
 	} else {
-		owner = pod.Name
-		labels = pod.Labels
+		_, err = client.Clientset.NetworkingV1().NetworkPolicies(namespace).Update(context.Background(), payload, metav1.UpdateOptions{})
+		output = fmt.Sprintf("the networkpolicy '%v' in the namespace '%v' has been updated", owner, namespace)
+	}
+	if err != nil {
+		return utils.LogLine{
+			Objects: objects,
+			Error:   err.Error(),
+			Status:  utils.FailureStr,
+		}, nil, err
+	}
+
+	return utils.LogLine{
+		Objects: objects,
+		Output:  output,
+		Status:  utils.SuccessStr,
+	}, nil, nil
+}
+
+func (a Actionner) Plan(event *events.Event, action *rules.Action) (utils.LogLine, error) {
+	podName := event.GetPodName()
+	namespace := event.GetNamespaceName()
+
+	objects := map[string]string{
+		"pod":       podName,
+		"namespace": namespace,
+	}
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+
+	var parameters Parameters
+	err = utils.DecodeParams(action.GetParameters(), &parameters)
+	if err != nil {
+		return utils.LogLine{}, err
+	}
+
+	payload, err := buildNetworkPolicy(client, podName, namespace, &parameters)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+	owner := payload.Name
+	objects["networkpolicy"] = owner
+
+	current, err := client.Clientset.NetworkingV1().NetworkPolicies(namespace).Get(context.Background(), owner, metav1.GetOptions{})
+	if errorsv1.IsNotFound(err) {
+		diff, err2 := plan.Diff(nil, planView(payload))
+		if err2 != nil {
+			return utils.LogLine{Objects: objects}, err2
+		}
+		return utils.LogLine{
+			Objects: objects,
+			Output:  fmt.Sprintf("the networkpolicy '%v' in the namespace '%v' would be created:\n%v", owner, namespace, diff),
+		}, nil
+	}
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
 	}
 
-	if owner == "" || len(labels) == 0 {
-		err3 := fmt.Errorf("can't find the owner and/or labels for the pod '%v' in namespace '%v'", podName, namespace)
+	diff, err := plan.Diff(planView(current), planView(payload))
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+	if !plan.Changed(diff) {
 		return utils.LogLine{
-				Objects: objects,
-				Error:   err3.Error(),
-				Status:  utils.FailureStr,
-			},
-			nil,
-			err3
+			Objects: objects,
+			Output:  fmt.Sprintf("the networkpolicy '%v' in the namespace '%v' is already up to date", owner, namespace),
+		}, nil
+	}
+	return utils.LogLine{
+		Objects: objects,
+		Output:  fmt.Sprintf("the networkpolicy '%v' in the namespace '%v' would be updated:\n%v", owner, namespace, diff),
+	}, nil
+}
+
+// planView keeps the fields of the network policy set by the actionner, the
+// ones set by the API server are not part of the plans.
+func planView(np *networkingv1.NetworkPolicy) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      np.Name,
+			Namespace: np.Namespace,
+			Labels:    np.Labels,
+		},
+		Spec: np.Spec,
+	}
+}
+
+// buildNetworkPolicy returns the network policy blocking the egress traffic
+// of the owner of the pod, named after it.
+func buildNetworkPolicy(client *k8s.Client, podName, namespace string, parameters *Parameters) (*networkingv1.NetworkPolicy, error) {
+	pod, err := client.GetPod(podName, namespace)
+	if err != nil {
+		return nil, err
 	}
 
-	delete(labels, "pod-template-hash")
-	delete(labels, "pod-template-generation")
-	delete(labels, "controller-revision-hash")
+	workload, err := client.GetWorkload(pod)
+	if err != nil {
+		return nil, err
+	}
+	owner := workload.Name
+	labels := workload.Selector
+
+	if len(labels) == 0 {
+		err2 := fmt.Errorf("can't find the labels of the %v for the pod '%v' in namespace '%v'", workload, podName, namespace)
+		return nil, err2
+	}
 	labels[managedByStr] = utils.FalcoTalonStr
 
 	selector := make(map[string]string)
@@ -249,45 +317,16 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 		},
 	}
 
-	np, err := createEgressRule(&parameters)
+	np, err := createEgressRule(parameters)
 	if err != nil {
-		return utils.LogLine{
-				Objects: objects,
-				Error:   err.Error(),
-				Status:  utils.FailureStr,
-			},
-			nil,
-			err
+		return nil, err
 	}
 
 	if np != nil {
 		payload.Spec.Egress = []networkingv1.NetworkPolicyEgressRule{*np}
 	}
 
-	objects["networkpolicy"] = owner
-
-	var output string
-	_, err = client.Clientset.NetworkingV1().NetworkPolicies(namespace).Get(context.Background(), owner, metav1.GetOptions{})
-	if errorsv1.IsNotFound(err) {
-		_, err = client.Clientset.NetworkingV1().NetworkPolicies(namespace).Create(context.Background(), &payload, metav1.CreateOptions{})
-		output = fmt.Sprintf("the networkpolicy '%v' in the namespace '%v' has been created", owner, namespace)
-	} else {
-		_, err = client.Clientset.NetworkingV1().NetworkPolicies(namespace).Update(context.Background(), &payload, metav1.UpdateOptions{})
-		output = fmt.Sprintf("the networkpolicy '%v' in the namespace '%v' has been updated", owner, namespace)
-	}
-	if err != nil {
-		return utils.LogLine{
-			Objects: objects,
-			Error:   err.Error(),
-			Status:  utils.FailureStr,
-		}, nil, err
-	}
-
-	return utils.LogLine{
-		Objects: objects,
-		Output:  output,
-		Status:  utils.SuccessStr,
-	}, nil, nil
+	return &payload, nil
 }
 
 func createEgressRule(parameters *Parameters) (*networkingv1.NetworkPolicyEgressRule, error) {
@@ -8,6 +8,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/falcosecurity/falco-talon/configuration"
 	"github.com/falcosecurity/falco-talon/internal/events"
 	k8sChecks "github.com/falcosecurity/falco-talon/internal/kubernetes/checks"
 	k8s "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
@@ -94,7 +95,7 @@ func Register() *Actionner {
 	return new(Actionner)
 }
 
-func (a Actionner) Init() error {
+func (a Actionner) Init(_ *configuration.Configuration) error {
 	return k8s.Init()
 }
 
@@ -162,9 +163,22 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 		parameters.BufferSize = defaultBufferSize
 	}
 
-	client := k8s.GetClient()
-
-	pod, _ := client.GetPod(podName, namespace)
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{
+			Objects: objects,
+			Error:   err.Error(),
+			Status:  utils.FailureStr,
+		}, nil, err
+	}
+	pod, err := client.GetPod(podName, namespace)
+	if err != nil {
+		return utils.LogLine{
+			Objects: objects,
+			Error:   err.Error(),
+			Status:  utils.FailureStr,
+		}, nil, err
+	}
 	containers := k8s.GetContainers(pod)
 	if len(containers) == 0 {
 		err = fmt.Errorf("no container found")
@@ -267,6 +281,55 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 	}, &models.Data{Name: "sysdig.scap.gz", Objects: objects, Bytes: output.Bytes()}, nil
 }
 
+func (a Actionner) Plan(event *events.Event, action *rules.Action) (utils.LogLine, error) {
+	pod := event.GetPodName()
+	namespace := event.GetNamespaceName()
+
+	objects := map[string]string{
+		defaultScope: pod,
+		"namespace":  namespace,
+	}
+
+	var parameters Parameters
+	err := utils.DecodeParams(action.GetParameters(), &parameters)
+	if err != nil {
+		return utils.LogLine{}, err
+	}
+
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+	p, err := client.GetPod(pod, namespace)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+	containers := k8s.GetContainers(p)
+	if len(containers) == 0 {
+		return utils.LogLine{Objects: objects}, fmt.Errorf("no container found")
+	}
+
+	if parameters.Duration == 0 {
+		parameters.Duration = defaultDuration
+	}
+	if parameters.Image == "" {
+		parameters.Image = defaultImage
+	}
+	if parameters.Scope == "" {
+		parameters.Scope = defaultScope
+	}
+
+	target := fmt.Sprintf("the containers %v of the pod '%v' in the namespace '%v'", containers, pod, namespace)
+	if parameters.Scope == "node" {
+		target = fmt.Sprintf("the node '%v'", p.Spec.NodeName)
+	}
+
+	return utils.LogLine{
+		Objects: objects,
+		Output:  fmt.Sprintf("a job with the image '%v' would be created on the node '%v' to capture the syscalls of %v for %vs", parameters.Image, p.Spec.NodeName, target, parameters.Duration),
+	}, nil
+}
+
 func (a Actionner) CheckParameters(action *rules.Action) error {
 	var parameters Parameters
 
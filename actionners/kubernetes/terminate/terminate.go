@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	helpers "github.com/falcosecurity/falco-talon/actionners/kubernetes/helpers"
+	"github.com/falcosecurity/falco-talon/configuration"
 	"github.com/falcosecurity/falco-talon/internal/events"
 	k8sChecks "github.com/falcosecurity/falco-talon/internal/kubernetes/checks"
 	k8s "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
@@ -75,7 +77,7 @@ func Register() *Actionner {
 	return new(Actionner)
 }
 
-func (a Actionner) Init() error {
+func (a Actionner) Init(_ *configuration.Configuration) error {
 	return k8s.Init()
 }
 
@@ -130,7 +132,14 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 	gracePeriodSeconds := new(int64)
 	*gracePeriodSeconds = int64(parameters.GracePeriodSeconds)
 
-	client := k8s.GetClient()
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{
+			Objects: objects,
+			Error:   err.Error(),
+			Status:  utils.FailureStr,
+		}, nil, err
+	}
 	pod, err := client.GetPod(podName, namespace)
 	if err != nil {
 		return utils.LogLine{
@@ -142,112 +151,135 @@ func (a Actionner) Run(event *events.Event, action *rules.Action) (utils.LogLine
 			err
 	}
 
+	reason, err := ignored(client, pod, &parameters)
+	if err != nil {
+		return utils.LogLine{
+			Objects: objects,
+			Status:  utils.FailureStr,
+			Error:   err.Error(),
+		}, nil, nil
+	}
+	if reason != "" {
+		return utils.LogLine{
+			Objects: objects,
+			Status:  ignoredStr,
+			Result:  fmt.Sprintf("the pod '%v' in the namespace '%v' %v and will be ignored.", podName, namespace, reason),
+		}, nil, nil
+	}
+
+	err = client.Clientset.CoreV1().Pods(namespace).Delete(context.Background(), podName, metav1.DeleteOptions{GracePeriodSeconds: gracePeriodSeconds})
+	if err != nil {
+		return utils.LogLine{
+				Objects: objects,
+				Status:  utils.FailureStr,
+				Error:   err.Error(),
+			},
+			nil,
+			err
+	}
+	return utils.LogLine{
+			Objects: objects,
+			Output:  fmt.Sprintf("the pod '%v' in the namespace '%v' has been terminated", podName, namespace),
+			Status:  utils.SuccessStr,
+		},
+		nil, nil
+}
+
+func (a Actionner) Plan(event *events.Event, action *rules.Action) (utils.LogLine, error) {
+	podName := event.GetPodName()
+	namespace := event.GetNamespaceName()
+
+	objects := map[string]string{
+		"pod":       podName,
+		"namespace": namespace,
+	}
+
+	var parameters Parameters
+	err := utils.DecodeParams(action.GetParameters(), &parameters)
+	if err != nil {
+		return utils.LogLine{}, err
+	}
+
+	client, err := k8sChecks.ClientFor(event)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+	pod, err := client.GetPod(podName, namespace)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+
+	reason, err := ignored(client, pod, &parameters)
+	if err != nil {
+		return utils.LogLine{Objects: objects}, err
+	}
+	if reason != "" {
+		return utils.LogLine{
+			Objects: objects,
+			Output:  fmt.Sprintf("the pod '%v' in the namespace '%v' %v and would be ignored", podName, namespace, reason),
+		}, nil
+	}
+
+	return utils.LogLine{
+		Objects: objects,
+		Output:  fmt.Sprintf("the pod '%v' in the namespace '%v' would be terminated with a grace period of %vs", podName, namespace, parameters.GracePeriodSeconds),
+	}, nil
+}
+
+// ignored returns why the pod is ignored according to the parameters, empty
+// if it's not.
+func ignored(client *k8s.Client, pod *corev1.Pod, parameters *Parameters) (string, error) {
 	ownerKind := k8s.PodKind(*pod)
 
 	switch ownerKind {
 	case utils.DaemonSetStr:
 		if parameters.IgnoreDaemonsets {
-			return utils.LogLine{
-				Objects: objects,
-				Status:  ignoredStr,
-				Result:  fmt.Sprintf("the pod '%v' in the namespace '%v' belongs to a DaemonSet and will be ignored.", podName, namespace),
-			}, nil, nil
+			return "belongs to a DaemonSet", nil
 		}
 	case utils.StatefulSetStr:
 		if parameters.IgnoreStatefulSets {
-			return utils.LogLine{
-				Objects: objects,
-				Status:  ignoredStr,
-				Result:  fmt.Sprintf("the pod '%v' in the namespace '%v' belongs to a StatefulSet and will be ignored.", podName, namespace),
-			}, nil, nil
+			return "belongs to a StatefulSet", nil
 		}
 	case utils.ReplicaSetStr:
-		replicaSetName, err2 := k8s.GetOwnerName(*pod)
-		if err2 != nil {
-			return utils.LogLine{
-				Objects: objects,
-				Status:  utils.FailureStr,
-				Error:   err2.Error(),
-			}, nil, nil
+		replicaSetName, err := k8s.GetOwnerName(*pod)
+		if err != nil {
+			return "", err
 		}
 		if parameters.MinHealthyReplicas != "" {
-			replicaSet, err2 := client.GetReplicaSet(replicaSetName, pod.Namespace)
-			if err2 != nil {
-				return utils.LogLine{
-					Objects: objects,
-					Status:  utils.FailureStr,
-					Error:   err2.Error(),
-				}, nil, nil
+			replicaSet, err := client.GetReplicaSet(replicaSetName, pod.Namespace)
+			if err != nil {
+				return "", err
 			}
-			minHealthyReplicasValue, kind, err2 := helpers.ParseMinHealthyReplicas(parameters.MinHealthyReplicas)
-			if err2 != nil {
-				return utils.LogLine{
-					Objects: objects,
-					Status:  utils.FailureStr,
-					Error:   err2.Error(),
-				}, nil, nil
+			minHealthyReplicasValue, kind, err := helpers.ParseMinHealthyReplicas(parameters.MinHealthyReplicas)
+			if err != nil {
+				return "", err
 			}
 			switch kind {
 			case "absolut":
-				healthyReplicasCount, err2 := k8s.GetHealthyReplicasCount(replicaSet)
-				if err2 != nil {
-					return utils.LogLine{
-						Objects: objects,
-						Status:  utils.FailureStr,
-						Error:   err2.Error(),
-					}, nil, nil
+				healthyReplicasCount, err := k8s.GetHealthyReplicasCount(replicaSet)
+				if err != nil {
+					return "", err
 				}
 				if healthyReplicasCount < minHealthyReplicasValue {
-					return utils.LogLine{
-						Objects: objects,
-						Status:  ignoredStr,
-						Result:  fmt.Sprintf("the pod '%v' in the namespace '%v' belongs to a ReplicaSet without enough healthy replicas and will be ignored.", podName, namespace),
-					}, nil, nil
+					return "belongs to a ReplicaSet without enough healthy replicas", nil
 				}
 			case "percent":
-				healthyReplicasPercent, err2 := k8s.GetHealthyReplicasCount(replicaSet)
-				if err2 != nil {
-					return utils.LogLine{
-						Objects: objects,
-						Status:  utils.FailureStr,
-						Error:   err2.Error(),
-					}, nil, nil
+				healthyReplicasPercent, err := k8s.GetHealthyReplicasCount(replicaSet)
+				if err != nil {
+					return "", err
 				}
 				if healthyReplicasPercent < minHealthyReplicasValue {
-					return utils.LogLine{
-						Objects: objects,
-						Status:  ignoredStr,
-						Result:  fmt.Sprintf("the pod '%v' in the namespace '%v' belongs to a ReplicaSet without enough healthy replicas and will be ignored.", podName, namespace),
-					}, nil, nil
+					return "belongs to a ReplicaSet without enough healthy replicas", nil
 				}
 			}
 		}
 	case utils.StandalonePodStr:
 		if parameters.IgnoreStandalonePods {
-			return utils.LogLine{
-				Objects: objects,
-				Status:  ignoredStr,
-				Result:  fmt.Sprintf("the pod '%v' in the namespace '%v' is a standalone pod and will be ignored.", podName, namespace),
-			}, nil, nil
+			return "is a standalone pod", nil
 		}
 	}
 
-	err = client.Clientset.CoreV1().Pods(namespace).Delete(context.Background(), podName, metav1.DeleteOptions{GracePeriodSeconds: gracePeriodSeconds})
-	if err != nil {
-		return utils.LogLine{
-				Objects: objects,
-				Status:  utils.FailureStr,
-				Error:   err.Error(),
-			},
-			nil,
-			err
-	}
-	return utils.LogLine{
-			Objects: objects,
-			Output:  fmt.Sprintf("the pod '%v' in the namespace '%v' has been terminated", podName, namespace),
-			Status:  utils.SuccessStr,
-		},
-		nil, nil
+	return "", nil
 }
 
 func (a Actionner) CheckParameters(action *rules.Action) error {
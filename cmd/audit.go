@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/audit"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+var auditCmd = &cobra.Command{
+	Use:   auditStr,
+	Short: "Manage the audit log",
+	Long:  "Manage the audit log of the actions.",
+	Run:   nil,
+}
+
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the integrity of the audit log",
+	Long: `Check the integrity of the chain of the records of the audit log: the hash of each
+record, its link to the previous one and the sequence. The signatures are checked too
+with the public key, or with the signing key of the configuration.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		configFile, _ := cmd.Flags().GetString("config")
+		config := configuration.CreateConfiguration(configFile)
+		utils.SetLogFormat(config.LogFormat)
+
+		file, _ := cmd.Flags().GetString("file")
+		if file == "" {
+			file = config.Audit.File
+		}
+		if file == "" {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: "no audit file, with the flag --file or 'audit.file' in the configuration", Message: auditStr})
+		}
+		keyFile, _ := cmd.Flags().GetString("public-key")
+		if keyFile == "" {
+			keyFile = config.Audit.SigningKeyFile
+		}
+
+		var key ed25519.PublicKey
+		if keyFile != "" {
+			k, err := audit.ReadPublicKey(keyFile)
+			if err != nil {
+				utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: auditStr})
+			}
+			key = k
+		}
+
+		f, err := os.Open(file) // #nosec G304 -- the file path comes from the operator
+		if err != nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: auditStr})
+		}
+		defer f.Close()
+
+		result, err := audit.Verify(f, key)
+		if err != nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: fmt.Sprintf("the audit log '%v' is corrupted after %v valid record(s), %v", file, result.Records, err), Message: auditStr})
+		}
+		if result.First > 1 {
+			utils.PrintLog(utils.WarningStr, utils.LogLine{Result: fmt.Sprintf("the chain starts at the record %v, the previous ones are missing", result.First), Message: auditStr})
+		}
+		msg := fmt.Sprintf("the audit log '%v' is valid, %v record(s)", file, result.Records)
+		if result.Records != 0 {
+			msg += fmt.Sprintf(" from %v to %v", result.First, result.Last)
+		}
+		if key != nil {
+			msg += ", all signed"
+		}
+		utils.PrintLog(utils.InfoStr, utils.LogLine{Result: msg, Message: auditStr})
+	},
+}
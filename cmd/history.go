@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/history"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+var historyFilters = []string{"rule", "actionner", "namespace", "pod", "status", "since", "until"}
+
+var historyCmd = &cobra.Command{
+	Use:   historyStr,
+	Short: "Query the history of the actions of a running Falco Talon",
+	Long: `Query the history of the actions of a running Falco Talon, through its API, the most
+recent first. The times of --since and --until are in RFC 3339 or durations before now.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		q := url.Values{}
+		for _, i := range historyFilters {
+			if v, _ := cmd.Flags().GetString(i); v != "" {
+				q.Set(i, v)
+			}
+		}
+		if limit, _ := cmd.Flags().GetInt("limit"); limit != 0 {
+			q.Set("limit", strconv.Itoa(limit))
+		}
+		b, err := callAPI(cmd, http.MethodGet, "/history?"+q.Encode(), nil, "history.token", func(c *configuration.Configuration) string { return c.History.Token })
+		if err != nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: historyStr})
+		}
+
+		format, _ := cmd.Flags().GetString(formatStr)
+		if format == jsonStr {
+			fmt.Println(string(b))
+			return
+		}
+		var l []*history.Entry
+		if err := json.Unmarshal(b, &l); err != nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: historyStr})
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tRULE\tACTION\tACTIONNER\tNAMESPACE\tPOD\tSTATUS")
+		for _, i := range l {
+			status := i.Status
+			if i.DryRun {
+				mode := i.Mode
+				if mode == "" {
+					mode = "dry-run"
+				}
+				status = strings.TrimSpace(i.Status + " (" + mode + ")")
+			}
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", i.Time.Format(time.RFC3339), i.Rule, i.Action, i.Actionner, i.Namespace, i.Pod, status)
+		}
+		_ = w.Flush()
+	},
+}
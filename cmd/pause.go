@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/pause"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+var pauseCmd = &cobra.Command{
+	Use:   pauseStr,
+	Short: "Pause the actions of a running Falco Talon",
+	Long: `Pause all the actions of a running Falco Talon, through its API, or the ones of a
+category of actionners with --category, or disable a rule with --rule. The matched
+events are still logged and notified, their actions are skipped.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		updatePause(cmd, "/pause")
+	},
+}
+
+var pauseStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print the pause of the actions",
+	Long:  "Print the pause of the actions of a running Falco Talon.",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		b, err := callPauseAPI(cmd, http.MethodGet, "/pause", nil)
+		if err != nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: pauseStr})
+		}
+		printPause(b)
+	},
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume the actions of a running Falco Talon",
+	Long: `Resume all the actions of a running Falco Talon, through its API, and the paused
+categories, or the ones of a category of actionners with --category, or enable a rule
+with --rule.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		updatePause(cmd, "/resume")
+	},
+}
+
+func updatePause(cmd *cobra.Command, path string) {
+	var request pause.Request
+	request.Category, _ = cmd.Flags().GetString("category")
+	request.Rule, _ = cmd.Flags().GetString("rule")
+	request.Reason, _ = cmd.Flags().GetString("reason")
+	request.By, _ = cmd.Flags().GetString("by")
+	body, _ := json.Marshal(request)
+	b, err := callPauseAPI(cmd, http.MethodPost, path, body)
+	if err != nil {
+		utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: pauseStr})
+	}
+	printPause(b)
+}
+
+func printPause(b []byte) {
+	var state pause.State
+	if err := json.Unmarshal(b, &state); err != nil {
+		utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: pauseStr})
+	}
+	log := utils.LogLine{Result: state.String(), Message: pauseStr}
+	if !state.UpdatedAt.IsZero() {
+		log.Context = "updated by '" + state.UpdatedBy + "' at " + state.UpdatedAt.Format(time.RFC3339)
+	}
+	utils.PrintLog(utils.InfoStr, log)
+}
+
+// callPauseAPI sends a request to the /pause and /resume endpoints.
+func callPauseAPI(cmd *cobra.Command, method, path string, body []byte) ([]byte, error) {
+	return callAPI(cmd, method, path, body, "pause.token", func(c *configuration.Configuration) string { return c.Pause.Token })
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/falcosecurity/falco-talon/actionners"
+	"github.com/falcosecurity/falco-talon/configuration"
+	ruleengine "github.com/falcosecurity/falco-talon/internal/rules"
+	"github.com/falcosecurity/falco-talon/notifiers"
+	"github.com/falcosecurity/falco-talon/outputs"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+const reloadStr = "reload"
+
+// reloader reloads the configuration, the rules and the registries of
+// actionners, outputs and notifiers. A reload is applied entirely or not at
+// all: the registries are built for the new configuration and rules first,
+// without changing the ones in use, and all of them are published only if
+// every step succeeds.
+type reloader struct {
+	configFile string
+	rulesFiles []string
+	mu         sync.Mutex
+}
+
+func newReloader(configFile string, rulesFiles []string) *reloader {
+	return &reloader{
+		configFile: configFile,
+		rulesFiles: rulesFiles,
+	}
+}
+
+func (r *reloader) reload(trigger string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	utils.PrintLog(utils.InfoStr, utils.LogLine{Result: "reload requested", Context: trigger, Message: reloadStr})
+
+	newConfig, err := configuration.LoadConfiguration(r.configFile)
+	if err != nil {
+		return r.fail(trigger, err)
+	}
+	if len(r.rulesFiles) != 0 {
+		newConfig.RulesFiles = r.rulesFiles
+	}
+
+	newRules := ruleengine.LoadRules(newConfig.RulesFiles)
+	if newRules == nil {
+		return r.fail(trigger, errors.New(invalidRulesStr))
+	}
+	if !validateRules(newRules) {
+		return r.fail(trigger, errors.New(invalidRulesStr))
+	}
+
+	oldConfig := configuration.GetConfiguration()
+
+	// the clients of the new configuration are used and the settings of the
+	// notifiers are applied only once published
+	reg, err := newRegistries(newConfig, newRules)
+	if err != nil {
+		return r.fail(trigger, err)
+	}
+
+	configuration.SetConfiguration(newConfig)
+	rs := ruleengine.SetRules(newRules)
+	reg.publish()
+
+	utils.SetLogFormat(newConfig.LogFormat)
+	for _, i := range restartRequired(oldConfig, newConfig) {
+		utils.PrintLog(utils.WarningStr, utils.LogLine{Error: fmt.Sprintf("the change of '%v' requires a restart", i), Context: trigger, Message: reloadStr})
+	}
+	if newConfig.WatchRules && !reflect.DeepEqual(oldConfig.RulesFiles, newConfig.RulesFiles) {
+		utils.PrintLog(utils.WarningStr, utils.LogLine{Error: "the new rules files will be watched after a restart", Context: trigger, Message: reloadStr})
+	}
+
+	utils.PrintLog(utils.InfoStr, utils.LogLine{
+		Result:        fmt.Sprintf("%v rule(s) has/have been successfully loaded", len(*newRules)),
+		Context:       trigger,
+		Status:        utils.SuccessStr,
+		RulesVersion:  fmt.Sprintf("%v", rs.Version),
+		RulesChecksum: rs.ShortChecksum(),
+		Message:       reloadStr,
+	})
+	return nil
+}
+
+func (*reloader) fail(trigger string, err error) error {
+	utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Context: trigger, Status: utils.FailureStr, Message: reloadStr})
+	return err
+}
+
+// registries are the actionners, outputs and notifiers of a configuration
+// and its rules.
+type registries struct {
+	actionners *actionners.Registry
+	outputs    *outputs.Registry
+	notifiers  *notifiers.Registry
+}
+
+// newRegistries initializes the actionners, outputs and notifiers used by the
+// configuration and the rules, without publishing them nor changing the ones
+// in use.
+func newRegistries(config *configuration.Configuration, rules *[]*ruleengine.Rule) (*registries, error) {
+	var r registries
+	var err error
+	if r.actionners, err = actionners.NewRegistry(config, rules); err != nil {
+		return nil, fmt.Errorf("%v: %v", actionnersStr, err)
+	}
+	if r.outputs, err = outputs.NewRegistry(config, rules); err != nil {
+		return nil, fmt.Errorf("%v: %v", outputsStr, err)
+	}
+	if r.notifiers, err = notifiers.NewRegistry(config, rules); err != nil {
+		return nil, fmt.Errorf("notifiers: %v", err)
+	}
+	return &r, nil
+}
+
+// publish makes the registries the ones in use.
+func (r *registries) publish() {
+	r.actionners.Publish()
+	r.outputs.Publish()
+	r.notifiers.Publish()
+}
+
+// restartRequired lists the settings which are used only at the start of the
+// server and can't be changed by a reload.
+func restartRequired(oldConfig, newConfig *configuration.Configuration) []string {
+	var s []string
+	if oldConfig.ListenAddress != newConfig.ListenAddress {
+		s = append(s, "listen_address")
+	}
+	if oldConfig.ListenPort != newConfig.ListenPort {
+		s = append(s, "listen_port")
+	}
+	if oldConfig.KubeConfig != newConfig.KubeConfig {
+		s = append(s, "kubeconfig")
+	}
+	if oldConfig.Kubernetes != newConfig.Kubernetes {
+		s = append(s, "kubernetes")
+	}
+	if !reflect.DeepEqual(oldConfig.Clusters.Credentials, newConfig.Clusters.Credentials) {
+		s = append(s, "clusters.credentials")
+	}
+	if oldConfig.WatchRules != newConfig.WatchRules {
+		s = append(s, "watch_rules")
+	}
+	if oldConfig.Deduplication != newConfig.Deduplication {
+		s = append(s, "deduplication")
+	}
+	if oldConfig.Otel != newConfig.Otel {
+		s = append(s, "otel")
+	}
+	if oldConfig.Approvals.StoreFile != newConfig.Approvals.StoreFile {
+		s = append(s, "approvals.store_file")
+	}
+	if oldConfig.Schedules.StoreFile != newConfig.Schedules.StoreFile {
+		s = append(s, "schedules.store_file")
+	}
+	if oldConfig.Escalations.StoreFile != newConfig.Escalations.StoreFile {
+		s = append(s, "escalations.store_file")
+	}
+	if oldConfig.History.StoreFile != newConfig.History.StoreFile || oldConfig.History.Retention != newConfig.History.Retention {
+		s = append(s, "history")
+	}
+	if oldConfig.Pause.StoreFile != newConfig.Pause.StoreFile || oldConfig.Pause.ConfigMap != newConfig.Pause.ConfigMap ||
+		oldConfig.Pause.Namespace != newConfig.Pause.Namespace || oldConfig.Pause.RefreshInterval != newConfig.Pause.RefreshInterval {
+		s = append(s, "pause")
+	}
+	if oldConfig.Suppressions.StoreFile != newConfig.Suppressions.StoreFile || oldConfig.Suppressions.Annotations != newConfig.Suppressions.Annotations {
+		s = append(s, "suppressions")
+	}
+	if !reflect.DeepEqual(oldConfig.Audit, newConfig.Audit) {
+		s = append(s, "audit")
+	}
+	return s
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+	ruleengine "github.com/falcosecurity/falco-talon/internal/rules"
+)
+
+func TestReloadKeepsThePreviousStateOnFailure(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	rulesFile := filepath.Join(tmpDir, "rules.yaml")
+	if err := os.WriteFile(rulesFile, []byte(`
+- rule: Shell
+  match:
+    rules:
+      - Terminal shell in container
+  actions:
+    - action: Terminate pod
+      actionner: kubernetes:terminate
+      ignore_errors: maybe
+`), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(configFile, []byte(`
+log_format: text
+rules_files:
+  - `+rulesFile+`
+`), 0o600); err != nil {
+		t.Fatalf("write config file: %v", err)
+	}
+
+	oldConfig := configuration.GetConfiguration()
+	oldRuleSet := ruleengine.GetRuleSet()
+
+	r := newReloader(configFile, nil)
+	if err := r.reload("test"); err == nil {
+		t.Fatal("expected the reload to fail with invalid rules")
+	}
+	if configuration.GetConfiguration() != oldConfig || ruleengine.GetRuleSet() != oldRuleSet {
+		t.Fatal("expected the previous configuration and rules to be kept")
+	}
+
+	validRulesFile := filepath.Join(tmpDir, "valid_rules.yaml")
+	if err := os.WriteFile(validRulesFile, []byte(`
+- rule: Shell
+  match:
+    rules:
+      - Terminal shell in container
+  actions:
+    - action: Terminate pod
+      actionner: kubernetes:terminate
+`), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+	wrongBudgetFile := filepath.Join(tmpDir, "wrong_budget.yaml")
+	if err := os.WriteFile(wrongBudgetFile, []byte(`
+log_format: text
+rules_files:
+  - `+validRulesFile+`
+budgets:
+  - name: pods
+    max: 1
+`), 0o600); err != nil {
+		t.Fatalf("write config file: %v", err)
+	}
+	r = newReloader(wrongBudgetFile, nil)
+	if err := r.reload("test"); err == nil {
+		t.Fatal("expected the reload to fail with a budget without actionners")
+	}
+	if configuration.GetConfiguration() != oldConfig || ruleengine.GetRuleSet() != oldRuleSet {
+		t.Fatal("expected the configuration and rules not to be published before the registries")
+	}
+
+	r = newReloader(filepath.Join(tmpDir, "missing.yaml"), nil)
+	if err := r.reload("test"); err == nil {
+		t.Fatal("expected the reload to fail with a missing configuration file")
+	}
+	if configuration.GetConfiguration() != oldConfig || ruleengine.GetRuleSet() != oldRuleSet {
+		t.Fatal("expected the previous configuration and rules to be kept")
+	}
+}
+
+func TestRestartRequired(t *testing.T) {
+	oldConfig := &configuration.Configuration{ListenPort: 2803, LogFormat: "color"}
+	newConfig := &configuration.Configuration{ListenPort: 2804, LogFormat: "json"}
+
+	s := restartRequired(oldConfig, newConfig)
+	if len(s) != 1 || s[0] != "listen_port" {
+		t.Fatalf("expected only 'listen_port' to require a restart, got %v", s)
+	}
+}
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 
+	"github.com/falcosecurity/falco-talon/internal/history"
 	"github.com/falcosecurity/falco-talon/utils"
 )
 
@@ -21,6 +24,19 @@ const (
 	leaseStr            = "lease"
 	httpStr             = "http"
 	otelTracesStr       = "otel-traces"
+	eventsStr           = "events"
+	configStr           = "config"
+	formatStr           = "format"
+	jsonStr             = "json"
+	approvalsStr        = "approvals"
+	schedulesStr        = "schedules"
+	escalationsStr      = "escalations"
+	auditStr            = "audit"
+	historyStr          = "history"
+	pauseStr            = "pause"
+	suppressionsStr     = "suppressions"
+	trueStr             = "true"
+	falseStr            = "false"
 )
 
 var RootCmd = &cobra.Command{
@@ -44,15 +60,72 @@ func init() {
 	RootCmd.AddCommand(actionnersCmd)
 	RootCmd.AddCommand(outputsCmd)
 	RootCmd.AddCommand(notifiersCmd)
+	RootCmd.AddCommand(schedulesCmd)
+	RootCmd.AddCommand(auditCmd)
+	RootCmd.AddCommand(historyCmd)
+	RootCmd.AddCommand(pauseCmd)
+	RootCmd.AddCommand(resumeCmd)
+	RootCmd.AddCommand(suppressionsCmd)
 	rulesCmd.AddCommand(rulesChecksCmd)
 	rulesCmd.AddCommand(rulesPrintCmd)
+	rulesCmd.AddCommand(rulesSimulateCmd)
+	rulesCmd.AddCommand(rulesSchemaCmd)
+	rulesCmd.AddCommand(rulesLintCmd)
 	actionnersCmd.AddCommand(actionnersListCmd)
 	outputsCmd.AddCommand(outputsListCmd)
 	notifiersCmd.AddCommand(notifiersListCmd)
+	schedulesCmd.AddCommand(schedulesListCmd)
+	schedulesCmd.AddCommand(schedulesCancelCmd)
+	auditCmd.AddCommand(auditVerifyCmd)
+	pauseCmd.AddCommand(pauseStatusCmd)
+	suppressionsCmd.AddCommand(suppressionsListCmd)
+	suppressionsCmd.AddCommand(suppressionsAddCmd)
+	suppressionsCmd.AddCommand(suppressionsDeleteCmd)
 	RootCmd.PersistentFlags().StringArrayP(rulesStr, "r", []string{}, "Falco Talon Rules File")
 	serverCmd.Flags().StringP("config", "c", "/etc/falco-talon/config.yaml", "Falco Talon Config File")
 	rulesCmd.PersistentFlags().StringP("config", "c", "", "Falco Talon Config File")
+	rulesChecksCmd.Flags().StringP(formatStr, "f", "text", "Format of the result, 'text' or 'json' for machine-readable diagnostics")
+	rulesLintCmd.Flags().StringP(formatStr, "f", "text", "Format of the result, 'text' or 'json' for machine-readable diagnostics")
+	rulesSimulateCmd.Flags().StringP(eventsStr, "e", "", "File with the recorded Falco events to replay, one JSON event per line"+requiredStr)
 	actionnersCmd.PersistentFlags().StringP("config", "c", "", "Falco Talon Config File")
 	outputsCmd.PersistentFlags().StringP("config", "c", "", "Falco Talon Config File")
 	notifiersCmd.PersistentFlags().StringP("config", "c", "", "Falco Talon Config File")
+	schedulesCmd.PersistentFlags().StringP("config", "c", "", "Falco Talon Config File")
+	schedulesCmd.PersistentFlags().StringP("url", "u", "", "URL of Falco Talon (default: http://localhost:<listen_port>)")
+	schedulesCmd.PersistentFlags().StringP("token", "t", "", "Token of the /schedules endpoints (default: 'schedules.token' of the configuration)")
+	schedulesCancelCmd.Flags().String("by", "cli", "Author of the cancellation")
+	auditCmd.PersistentFlags().StringP("config", "c", "", "Falco Talon Config File")
+	auditVerifyCmd.Flags().StringP("file", "f", "", "Audit log to verify (default: 'audit.file' of the configuration)")
+	historyCmd.Flags().StringP("config", "c", "", "Falco Talon Config File")
+	historyCmd.Flags().StringP("url", "u", "", "URL of Falco Talon (default: http://localhost:<listen_port>)")
+	historyCmd.Flags().StringP("token", "t", "", "Token of the /history endpoint (default: 'history.token' of the configuration)")
+	historyCmd.Flags().String("rule", "", "Name of the rule")
+	historyCmd.Flags().String("actionner", "", "Full name of the actionner")
+	historyCmd.Flags().StringP("namespace", "n", "", "Namespace of the event")
+	historyCmd.Flags().String("pod", "", "Pod of the event")
+	historyCmd.Flags().String("status", "", "Status of the action")
+	historyCmd.Flags().String("since", "", "Start of the time range, in RFC 3339 or a duration before now")
+	historyCmd.Flags().String("until", "", "End of the time range, in RFC 3339 or a duration before now")
+	historyCmd.Flags().IntP("limit", "l", 0, fmt.Sprintf("Maximum number of results (default: %v)", history.DefaultLimit))
+	historyCmd.Flags().StringP(formatStr, "f", "text", "Format of the result, 'text' or 'json'")
+	for _, c := range []*cobra.Command{pauseCmd, resumeCmd} {
+		c.PersistentFlags().StringP("config", "c", "", "Falco Talon Config File")
+		c.PersistentFlags().StringP("url", "u", "", "URL of Falco Talon (default: http://localhost:<listen_port>)")
+		c.PersistentFlags().StringP("token", "t", "", "Token of the /pause and /resume endpoints (default: 'pause.token' of the configuration)")
+		c.Flags().String("category", "", "Category of actionners, e.g. 'kubernetes'")
+		c.Flags().String("rule", "", "Name of the rule")
+		c.Flags().String("by", "cli", "Author of the change")
+	}
+	pauseCmd.Flags().String("reason", "", "Reason of the pause")
+	suppressionsCmd.PersistentFlags().StringP("config", "c", "", "Falco Talon Config File")
+	suppressionsCmd.PersistentFlags().StringP("url", "u", "", "URL of Falco Talon (default: http://localhost:<listen_port>)")
+	suppressionsCmd.PersistentFlags().StringP("token", "t", "", "Token of the /suppressions endpoints (default: 'suppressions.token' of the configuration)")
+	suppressionsAddCmd.Flags().String("rule", "", "Name of the rule (default: all the rules)")
+	suppressionsAddCmd.Flags().String("key", "", "Key of the target, 'pod', 'workload', 'image', 'node' or 'field'"+requiredStr)
+	suppressionsAddCmd.Flags().String("field", "", "Output field of the target, for the key 'field'")
+	suppressionsAddCmd.Flags().String("value", "", "Value of the target"+requiredStr)
+	suppressionsAddCmd.Flags().String("duration", "", "Duration of the suppression, e.g. '24h'"+requiredStr)
+	suppressionsAddCmd.Flags().String("reason", "", "Reason of the suppression")
+	suppressionsAddCmd.Flags().String("by", "cli", "Author of the suppression")
+	auditVerifyCmd.Flags().StringP("public-key", "k", "", "PEM file of the Ed25519 public key to check the signatures (default: 'audit.signing_key_file' of the configuration)")
 }
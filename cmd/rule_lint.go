@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/falcosecurity/falco-talon/actionners"
+	"github.com/falcosecurity/falco-talon/configuration"
+	ruleengine "github.com/falcosecurity/falco-talon/internal/rules"
+	"github.com/falcosecurity/falco-talon/notifiers"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+var outputFieldRegex = regexp.MustCompile(`[a-z][a-z0-9_]*(\.[a-z0-9_\[\]]+)+`)
+
+// fieldSources maps the prefixes of the Falco fields to the only source able
+// to provide them.
+var fieldSources = map[string]string{
+	"k8s.":       "syscall",
+	"fd.":        "syscall",
+	"proc.":      "syscall",
+	"container.": "syscall",
+	"user.":      "syscall",
+	"ka.":        "k8s_audit",
+}
+
+// lintRules checks the logic of the rules, once they are parsed and valid.
+// It returns the issues found, nothing is blocking at runtime but they all
+// lead to actions which will never be performed as expected.
+func lintRules(rules *[]*ruleengine.Rule, config *configuration.Configuration) []utils.LogLine {
+	defaultActionners := actionners.ListDefaultActionners()
+	issues := []utils.LogLine{}
+
+	for n, rule := range *rules {
+		for _, i := range (*rules)[:n] {
+			if i.Continue == falseStr && i.Covers(rule) {
+				issues = append(issues, utils.LogLine{
+					Error:    fmt.Sprintf("shadowed by the rule '%v' which has a broader match and 'continue: false'", i.GetName()),
+					Rule:     rule.GetName(),
+					Location: rule.Location.String(),
+					Message:  rulesStr,
+				})
+				break
+			}
+		}
+
+		for _, action := range rule.ListAllActions() {
+			actionner := defaultActionners.FindActionner(action.GetActionner())
+			if actionner == nil {
+				continue
+			}
+			if rule.Match.Source != "" && !requiredOutputFieldsAvailable(actionner.Information().RequiredOutputFields, rule.Match.Source) {
+				issues = append(issues, utils.LogLine{
+					Error:     fmt.Sprintf("the required output fields '%v' can't be present for the source '%v'", strings.Join(actionner.Information().RequiredOutputFields, ", "), rule.Match.Source),
+					Rule:      rule.GetName(),
+					Action:    action.GetName(),
+					Actionner: action.GetActionner(),
+					Location:  action.Location.String(),
+					Message:   rulesStr,
+				})
+			}
+		}
+
+		actions := rule.GetActions()
+		for m := 0; m < len(actions); m++ {
+			action := actions[m]
+			actionner := defaultActionners.FindActionner(action.GetActionner())
+			if actionner == nil {
+				continue
+			}
+
+			// the chain is made of the actions of the same escalation step
+			end := m + 1
+			for end < len(actions) && actions[end].GetStep() == action.GetStep() {
+				end++
+			}
+			// the other actions of a parallel group run anyway
+			next := m + 1
+			for next < end && action.GetParallel() != nil && actions[next].GetParallel() == action.GetParallel() {
+				next++
+			}
+			if next == end {
+				continue
+			}
+			// an action skipped by its condition doesn't stop the chain
+			if action.When != "" {
+				continue
+			}
+			if action.Continue == falseStr || action.Continue != trueStr && !actionner.Information().Continue {
+				for _, i := range actions[next:end] {
+					issues = append(issues, utils.LogLine{
+						Error:     fmt.Sprintf("unreachable, the previous action '%v' doesn't continue", action.GetName()),
+						Rule:      rule.GetName(),
+						Action:    i.GetName(),
+						Actionner: i.GetActionner(),
+						Location:  i.Location.String(),
+						Message:   rulesStr,
+					})
+				}
+				m = end - 1
+			}
+		}
+
+		for _, i := range rule.ListNotifiers() {
+			if err := checkNotifierConfiguration(i, config); err != nil {
+				issues = append(issues, utils.LogLine{
+					Error:    err.Error(),
+					Rule:     rule.GetName(),
+					Notifier: i,
+					Location: rule.Location.String(),
+					Message:  rulesStr,
+				})
+			}
+		}
+	}
+
+	for _, i := range config.ListDefaultNotifiers() {
+		if err := checkNotifierConfiguration(i, config); err != nil {
+			issues = append(issues, utils.LogLine{
+				Error:    err.Error(),
+				Notifier: i,
+				Message:  configStr,
+			})
+		}
+	}
+
+	issues = append(issues, ruleengine.GetOverrideMismatches()...)
+
+	return issues
+}
+
+// requiredOutputFieldsAvailable returns false if none of the fields listed by
+// an actionner can be provided by the source.
+func requiredOutputFieldsAvailable(fields []string, source string) bool {
+	found := false
+	for _, i := range fields {
+		for _, j := range outputFieldRegex.FindAllString(i, -1) {
+			found = true
+			s := ""
+			for k, v := range fieldSources {
+				if strings.HasPrefix(j, k) {
+					s = v
+				}
+			}
+			if s == "" || s == source {
+				return true
+			}
+		}
+	}
+	return !found
+}
+
+func checkNotifierConfiguration(name string, config *configuration.Configuration) error {
+	notifier := notifiers.ListDefaultNotifiers().FindNotifier(strings.ToLower(name))
+	if notifier == nil {
+		return fmt.Errorf("unknown notifier '%v'", name)
+	}
+
+	p := notifier.Parameters()
+	if p == nil {
+		return nil
+	}
+	valueOf := reflect.ValueOf(p)
+	if valueOf.Kind() == reflect.Pointer {
+		valueOf = valueOf.Elem()
+	}
+	if valueOf.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < valueOf.NumField(); i++ {
+		field := valueOf.Type().Field(i)
+		if !strings.Contains(field.Tag.Get("validate"), "required") {
+			continue
+		}
+		if v := config.Notifiers[name][field.Tag.Get("field")]; v == nil || fmt.Sprintf("%v", v) == "" {
+			return fmt.Errorf("the notifier '%v' is not configured, missing '%v'", name, field.Tag.Get("field"))
+		}
+	}
+
+	return nil
+}
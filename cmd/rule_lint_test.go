@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+	ruleengine "github.com/falcosecurity/falco-talon/internal/rules"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+func hasIssue(issues []utils.LogLine, rule, action, substr string) bool {
+	for _, i := range issues {
+		if i.Rule == rule && i.Action == action && strings.Contains(i.Error, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLintRulesReportsLogicalIssues(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rulesFile, []byte(`
+- action: Label
+  actionner: kubernetes:label
+  parameters:
+    labels:
+      suspicious: "true"
+
+- rule: Broad
+  continue: false
+  match:
+    rules:
+      - Terminal shell in container
+      - Other
+  actions:
+    - action: Label
+
+- rule: Narrow
+  match:
+    rules:
+      - Terminal shell in container
+    priority: Warning
+  actions:
+    - action: Terminate
+      actionner: kubernetes:terminate
+    - action: Label
+      parameters:
+        labels: wrong-type
+
+- rule: Audit
+  match:
+    source: k8s_audit
+  notifiers:
+    - slack
+    - unknown
+  actions:
+    - action: Terminate
+      actionner: kubernetes:terminate
+`), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+
+	rules := ruleengine.ParseRules([]string{rulesFile})
+	if rules == nil {
+		t.Fatal("expected the rules to be valid")
+	}
+
+	issues := lintRules(rules, &configuration.Configuration{})
+
+	if !hasIssue(issues, "Narrow", "", "shadowed by the rule 'Broad'") {
+		t.Fatalf("expected the rule 'Narrow' to be shadowed, got %#v", issues)
+	}
+	if hasIssue(issues, "Audit", "", "shadowed") {
+		t.Fatalf("did not expect the rule 'Audit' to be shadowed, got %#v", issues)
+	}
+	if !hasIssue(issues, "Narrow", "Label", "unreachable") {
+		t.Fatalf("expected the action after kubernetes:terminate to be unreachable, got %#v", issues)
+	}
+	if !hasIssue(issues, "Audit", "Terminate", "can't be present for the source 'k8s_audit'") {
+		t.Fatalf("expected the required output fields to be missing for k8s_audit, got %#v", issues)
+	}
+	if !hasIssue(issues, "Audit", "", "the notifier 'slack' is not configured") {
+		t.Fatalf("expected the slack notifier to be reported as not configured, got %#v", issues)
+	}
+	if !hasIssue(issues, "Audit", "", "unknown notifier 'unknown'") {
+		t.Fatalf("expected the unknown notifier to be reported, got %#v", issues)
+	}
+	if !hasIssue(issues, "Narrow", "Label", "mismatch of type for a parameter 'labels'") {
+		t.Fatalf("expected the override with a wrong type to be reported, got %#v", issues)
+	}
+}
@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/falcosecurity/falco-talon/actionners"
+	ruleengine "github.com/falcosecurity/falco-talon/internal/rules"
+	"github.com/falcosecurity/falco-talon/notifiers"
+	"github.com/falcosecurity/falco-talon/outputs"
+)
+
+const (
+	jsonSchemaDraft string = "https://json-schema.org/draft/2020-12/schema"
+	jsonSchemaID    string = "https://github.com/falcosecurity/falco-talon/rules.schema.json"
+
+	typeStr    string = "type"
+	objectStr  string = "object"
+	arrayStr   string = "array"
+	stringStr  string = "string"
+	booleanStr string = "boolean"
+	integerStr string = "integer"
+	numberStr  string = "number"
+)
+
+// rulesSchema returns the JSON Schema of the rule files. The parameters of the
+// actions and of their outputs are described with a discriminated union on the
+// 'actionner' and 'target' values, built from the Parameters() structs of the
+// actionners and outputs.
+func rulesSchema() map[string]any {
+	defaultActionners := actionners.ListDefaultActionners()
+	defaultOutputs := outputs.ListDefaultOutputs()
+	defaultNotifiers := notifiers.ListDefaultNotifiers()
+
+	var actionnerNames, targetNames, notifierNames []string
+	var actionnerCases, outputCases []any
+
+	for _, i := range *defaultActionners {
+		actionnerNames = append(actionnerNames, i.Information().FullName)
+		actionnerCases = append(actionnerCases, discriminatedCase("actionner", i.Information().FullName, i.Parameters()))
+	}
+	for _, i := range *defaultOutputs {
+		targetNames = append(targetNames, i.Information().FullName)
+		outputCases = append(outputCases, discriminatedCase("target", i.Information().FullName, i.Parameters()))
+	}
+	for _, i := range *defaultNotifiers {
+		notifierNames = append(notifierNames, i.Information().Name)
+	}
+	sort.Strings(actionnerNames)
+	sort.Strings(targetNames)
+	sort.Strings(notifierNames)
+
+	boolString := map[string]any{
+		"anyOf": []any{
+			map[string]any{typeStr: booleanStr},
+			map[string]any{typeStr: stringStr, "enum": []string{trueStr, falseStr}},
+		},
+	}
+	stringArray := map[string]any{typeStr: arrayStr, "items": map[string]any{typeStr: stringStr}}
+	duration := map[string]any{typeStr: stringStr, "pattern": `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`}
+
+	output := map[string]any{
+		typeStr: objectStr,
+		"properties": map[string]any{
+			"target":     map[string]any{typeStr: stringStr, "enum": targetNames},
+			"parameters": map[string]any{typeStr: objectStr},
+		},
+		"additionalProperties": false,
+		"allOf":                outputCases,
+	}
+
+	action := map[string]any{
+		typeStr:    objectStr,
+		"required": []string{"action"},
+		"properties": map[string]any{
+			"action":              map[string]any{typeStr: stringStr, "minLength": 1},
+			"description":         map[string]any{typeStr: stringStr},
+			"actionner":           map[string]any{typeStr: stringStr, "enum": actionnerNames},
+			"continue":            boolString,
+			"ignore_errors":       boolString,
+			"additional_contexts": map[string]any{typeStr: arrayStr, "items": map[string]any{typeStr: stringStr, "enum": []string{"aws", "k8snode"}}},
+			"parameters":          map[string]any{typeStr: objectStr},
+			"output":              map[string]any{"$ref": "#/$defs/output"},
+			"require_approval": map[string]any{
+				typeStr: objectStr,
+				"properties": map[string]any{
+					"timeout": duration,
+					"default": map[string]any{typeStr: stringStr, "enum": []string{ruleengine.ApproveStr, ruleengine.DenyStr}},
+				},
+				"additionalProperties": false,
+			},
+			"delay":      duration,
+			"when":       map[string]any{typeStr: stringStr, "minLength": 1},
+			"on_failure": map[string]any{typeStr: arrayStr, "items": map[string]any{"$ref": "#/$defs/action"}},
+		},
+		"additionalProperties": false,
+		"allOf":                actionnerCases,
+	}
+
+	parallel := map[string]any{
+		typeStr:    objectStr,
+		"required": []string{"parallel"},
+		"properties": map[string]any{
+			"parallel": map[string]any{
+				typeStr:    objectStr,
+				"required": []string{"actions"},
+				"properties": map[string]any{
+					"timeout": duration,
+					"actions": map[string]any{typeStr: arrayStr, "minItems": 1, "items": map[string]any{"$ref": "#/$defs/action"}},
+				},
+				"additionalProperties": false,
+			},
+		},
+		"additionalProperties": false,
+	}
+
+	// steps are the actions of a rule or of an escalation step
+	steps := map[string]any{typeStr: arrayStr, "items": map[string]any{
+		"oneOf": []any{
+			map[string]any{"$ref": "#/$defs/action"},
+			map[string]any{"$ref": "#/$defs/parallel"},
+		},
+	}}
+
+	escalation := map[string]any{
+		typeStr:    objectStr,
+		"required": []string{"steps"},
+		"properties": map[string]any{
+			"key":   map[string]any{typeStr: stringStr, "enum": []string{ruleengine.WorkloadStr, ruleengine.NamespaceStr, ruleengine.NodeStr}},
+			"decay": duration,
+			"steps": map[string]any{typeStr: arrayStr, "minItems": 1, "items": map[string]any{
+				typeStr:    objectStr,
+				"required": []string{"offense", "actions"},
+				"properties": map[string]any{
+					"offense": map[string]any{typeStr: "integer", "minimum": 1},
+					"actions": map[string]any{"$ref": "#/$defs/steps"},
+				},
+				"additionalProperties": false,
+			}},
+		},
+		"additionalProperties": false,
+	}
+
+	rule := map[string]any{
+		typeStr:    objectStr,
+		"required": []string{"rule"},
+		"properties": map[string]any{
+			"rule":        map[string]any{typeStr: stringStr, "minLength": 1},
+			"description": map[string]any{typeStr: stringStr},
+			"continue":    boolString,
+			"dry_run":     boolString,
+			"finally":     map[string]any{typeStr: arrayStr, "items": map[string]any{"$ref": "#/$defs/action"}},
+			"notifiers":   map[string]any{typeStr: arrayStr, "items": map[string]any{typeStr: stringStr, "enum": notifierNames}},
+			"actions":     map[string]any{"$ref": "#/$defs/steps"},
+			"escalation":  map[string]any{"$ref": "#/$defs/escalation"},
+			"match": map[string]any{
+				typeStr: objectStr,
+				"properties": map[string]any{
+					"rules":         stringArray,
+					"tags":          stringArray,
+					"output_fields": stringArray,
+					"source":        map[string]any{typeStr: stringStr},
+					"priority": map[string]any{
+						typeStr:   stringStr,
+						"pattern": `(?i)^(<|>)?(=)?(Debug|Informational|Notice|Warning|Error|Critical|Alert|Emergency|)$`,
+					},
+				},
+				"additionalProperties": false,
+			},
+		},
+		"additionalProperties": false,
+	}
+
+	return map[string]any{
+		"$schema":     jsonSchemaDraft,
+		"$id":         jsonSchemaID,
+		"title":       "Falco Talon rules",
+		"description": "List of actions and rules for Falco Talon",
+		typeStr:       arrayStr,
+		"items": map[string]any{
+			"oneOf": []any{
+				map[string]any{"$ref": "#/$defs/action"},
+				map[string]any{"$ref": "#/$defs/rule"},
+			},
+		},
+		"$defs": map[string]any{
+			"action":     action,
+			"output":     output,
+			"parallel":   parallel,
+			"steps":      steps,
+			"escalation": escalation,
+			"rule":       rule,
+		},
+	}
+}
+
+// discriminatedCase returns the schema applied to the 'parameters' of an
+// object when its key is equal to value.
+func discriminatedCase(key, value string, parameters any) map[string]any {
+	return map[string]any{
+		"if": map[string]any{
+			"properties": map[string]any{key: map[string]any{"const": value}},
+			"required":   []string{key},
+		},
+		"then": map[string]any{
+			"properties": map[string]any{"parameters": parametersSchema(parameters)},
+		},
+	}
+}
+
+// parametersSchema reflects over a Parameters struct, the names come from the
+// mapstructure tags, the constraints from the validate tags and the defaults
+// from the values returned by Parameters().
+func parametersSchema(parameters any) map[string]any {
+	schema := map[string]any{typeStr: objectStr}
+	if parameters == nil {
+		return schema
+	}
+
+	valueOf := reflect.ValueOf(parameters)
+	if valueOf.Kind() == reflect.Pointer {
+		valueOf = valueOf.Elem()
+	}
+	if valueOf.Kind() != reflect.Struct {
+		return schema
+	}
+
+	properties := map[string]any{}
+	required := []string{}
+	for i := 0; i < valueOf.NumField(); i++ {
+		field := valueOf.Type().Field(i)
+		name := field.Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			continue
+		}
+		property := typeSchema(field.Type)
+		for _, j := range strings.Split(field.Tag.Get("validate"), ",") {
+			k, v, _ := strings.Cut(j, "=")
+			switch k {
+			case "required":
+				required = append(required, name)
+			case "oneof":
+				property["enum"] = strings.Fields(v)
+			case "gt", "gte", "lt", "lte":
+				n, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					continue
+				}
+				property[map[string]string{
+					"gt":  "exclusiveMinimum",
+					"gte": "minimum",
+					"lt":  "exclusiveMaximum",
+					"lte": "maximum",
+				}[k]] = n
+			}
+		}
+		if d := valueOf.Field(i); !d.IsZero() {
+			property["default"] = d.Interface()
+		}
+		properties[name] = property
+	}
+
+	schema["properties"] = properties
+	schema["additionalProperties"] = false
+	if len(required) != 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func typeSchema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{typeStr: stringStr}
+	case reflect.Bool:
+		return map[string]any{typeStr: booleanStr}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{typeStr: integerStr}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{typeStr: numberStr}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{typeStr: arrayStr, "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{typeStr: objectStr, "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		return parametersSchema(reflect.New(t).Elem().Interface())
+	default:
+		return map[string]any{}
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func findCase(t *testing.T, cases []any, key, value string) map[string]any {
+	t.Helper()
+
+	for _, i := range cases {
+		c := i.(map[string]any)
+		cond := c["if"].(map[string]any)["properties"].(map[string]any)[key].(map[string]any)
+		if cond["const"] == value {
+			return c["then"].(map[string]any)["properties"].(map[string]any)["parameters"].(map[string]any)
+		}
+	}
+	t.Fatalf("no case found for %v '%v'", key, value)
+	return nil
+}
+
+func TestRulesSchemaDescribesActionnerParameters(t *testing.T) {
+	schema := rulesSchema()
+
+	if _, err := json.Marshal(schema); err != nil {
+		t.Fatalf("marshal schema: %v", err)
+	}
+
+	action := schema["$defs"].(map[string]any)["action"].(map[string]any)
+	parameters := findCase(t, action["allOf"].([]any), "actionner", "aws:lambda")
+
+	if !reflect.DeepEqual(parameters["required"], []string{"aws_lambda_name"}) {
+		t.Fatalf("expected aws_lambda_name to be required, got %#v", parameters["required"])
+	}
+
+	properties := parameters["properties"].(map[string]any)
+	invocationType := properties["aws_lambda_invocation_type"].(map[string]any)
+	if !reflect.DeepEqual(invocationType["enum"], []string{"RequestResponse", "Event", "DryRun"}) {
+		t.Fatalf("expected the oneof values as enum, got %#v", invocationType["enum"])
+	}
+
+	terminate := findCase(t, action["allOf"].([]any), "actionner", "kubernetes:terminate")
+	gracePeriod := terminate["properties"].(map[string]any)["grace_period_seconds"].(map[string]any)
+	if gracePeriod["type"] != integerStr {
+		t.Fatalf("expected grace_period_seconds to be an integer, got %#v", gracePeriod)
+	}
+}
+
+func TestRulesSchemaDescribesOutputParameters(t *testing.T) {
+	schema := rulesSchema()
+
+	output := schema["$defs"].(map[string]any)["output"].(map[string]any)
+	parameters := findCase(t, output["allOf"].([]any), "target", "local:file")
+
+	if _, ok := parameters["properties"].(map[string]any)["destination"]; !ok {
+		t.Fatalf("expected the destination parameter for local:file, got %#v", parameters)
+	}
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"bufio"
+	"crypto/md5" //nolint:gosec
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/falcosecurity/falco-talon/actionners"
+	"github.com/falcosecurity/falco-talon/internal/escalation"
+	"github.com/falcosecurity/falco-talon/internal/events"
+	ruleengine "github.com/falcosecurity/falco-talon/internal/rules"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+const maxEventLineSize int = 1024 * 1024
+
+type simulationReport struct { //nolint:govet
+	Events          int               `yaml:"events"`
+	InvalidEvents   int               `yaml:"invalid_events,omitempty"`
+	RateLimitHits   int               `yaml:"rate_limit_hits"`
+	MatchedEvents   int               `yaml:"matched_events"`
+	UnmatchedEvents int               `yaml:"unmatched_events"`
+	Rules           []*ruleSimulation `yaml:"rules"`
+}
+
+type ruleSimulation struct { //nolint:govet
+	Name    string              `yaml:"rule"`
+	Matches int                 `yaml:"matches"`
+	Actions []*actionSimulation `yaml:"actions,omitempty"`
+	Targets []string            `yaml:"targets,omitempty"`
+	targets map[string]bool
+}
+
+type actionSimulation struct {
+	Name      string `yaml:"action"`
+	Actionner string `yaml:"actionner"`
+	Runs      int    `yaml:"runs"`
+}
+
+// simulateEvents replays the Falco events read from r, one JSON document per
+// line, against the rules. Nothing is run: the actions that would have been
+// triggered are only counted, like with a forced dry-run. Events with the same
+// output inside the deduplication time window are counted as rate limit hits
+// and skipped, the same way the NATS stream drops them in server mode.
+func simulateEvents(rules *[]*ruleengine.Rule, r io.Reader, timeWindow int) (*simulationReport, error) {
+	report := new(simulationReport)
+	stats := make(map[string]*ruleSimulation, len(*rules))
+	for _, i := range *rules {
+		s := &ruleSimulation{Name: i.GetName(), targets: map[string]bool{}}
+		stats[i.GetName()] = s
+		report.Rules = append(report.Rules, s)
+	}
+
+	lastSeen := map[string]time.Time{}
+	offenses, _ := escalation.NewStore("")
+	defaultActionners := actionners.ListDefaultActionners()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventLineSize)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		event, err := events.DecodeEvent(strings.NewReader(line))
+		if err != nil {
+			report.InvalidEvents++
+			continue
+		}
+		report.Events++
+
+		hasher := md5.New() //nolint:gosec
+		hasher.Write([]byte(event.Output))
+		id := hex.EncodeToString(hasher.Sum(nil))
+		if t, ok := lastSeen[id]; ok && timeWindow > 0 && event.Time.Sub(t) < time.Duration(timeWindow)*time.Second {
+			report.RateLimitHits++
+			continue
+		}
+		lastSeen[id] = event.Time
+
+		matched := false
+		for _, i := range *rules {
+			if !i.CompareRule(event) {
+				continue
+			}
+			matched = true
+			s := stats[i.GetName()]
+			s.Matches++
+			if t := simulationTarget(event); t != "" {
+				s.targets[t] = true
+			}
+			// the offenses are counted at the time of the events, by pod for
+			// the workloads which can't be resolved offline
+			var step *ruleengine.EscalationStep
+			if e := i.GetEscalation(); e != nil {
+				key := fmt.Sprintf("Pod/%v/%v", event.GetNamespaceName(), event.GetPodName())
+				var err error
+				if e.GetKey() != ruleengine.WorkloadStr {
+					key, err = escalation.Key(e, event)
+				} else if event.GetPodName() == "" {
+					err = escalation.ErrNoKey
+				}
+				// the events without key run no escalation step
+				if errors.Is(err, escalation.ErrNoKey) {
+					if i.Continue == falseStr {
+						break
+					}
+					continue
+				}
+				n := offenses.Record(i.GetName(), key, e.GetDecay(), event.Time)
+				if step = e.GetStep(n); step == nil {
+					if i.Continue == falseStr {
+						break
+					}
+					continue
+				}
+			}
+			// the conditions are evaluated as if all the actions succeeded
+			results := ruleengine.Results{}
+			actions := i.GetActions()
+			stop := false
+			var last *ruleengine.ActionResult
+			for n, a := range actions {
+				if a.GetStep() != step {
+					continue
+				}
+				// the actions of a parallel group see the results of the
+				// actions before the group, and the chain stops after it
+				first := n
+				for first > 0 && a.GetParallel() != nil && actions[first-1].GetParallel() == a.GetParallel() {
+					first--
+				}
+				if stop && first == n {
+					break
+				}
+				var previous *ruleengine.ActionResult
+				if first > 0 && actions[first-1].GetStep() == step {
+					previous = results[actions[first-1].GetName()]
+				}
+				if !a.GetCondition().Evaluate(event, results, previous) {
+					results[a.GetName()] = &ruleengine.ActionResult{Status: utils.SkippedStr}
+					last = results[a.GetName()]
+					continue
+				}
+				results[a.GetName()] = &ruleengine.ActionResult{Status: utils.SuccessStr}
+				last = results[a.GetName()]
+				s.countAction(a)
+				if a.Continue == falseStr {
+					stop = true
+				}
+				if a.Continue != trueStr {
+					if actionner := defaultActionners.FindActionner(a.GetActionner()); actionner != nil && !actionner.Information().Continue {
+						stop = true
+					}
+				}
+			}
+			// the 'finally' actions are run whatever the outcome of the chain
+			for _, a := range i.GetFinally() {
+				if a.GetCondition().Evaluate(event, results, last) {
+					results[a.GetName()] = &ruleengine.ActionResult{Status: utils.SuccessStr}
+					s.countAction(a)
+				} else {
+					results[a.GetName()] = &ruleengine.ActionResult{Status: utils.SkippedStr}
+				}
+				last = results[a.GetName()]
+			}
+			if i.Continue == falseStr {
+				break
+			}
+		}
+		if matched {
+			report.MatchedEvents++
+		} else {
+			report.UnmatchedEvents++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, i := range report.Rules {
+		for j := range i.targets {
+			i.Targets = append(i.Targets, j)
+		}
+		sort.Strings(i.Targets)
+	}
+
+	return report, nil
+}
+
+func (s *ruleSimulation) countAction(action *ruleengine.Action) {
+	for _, i := range s.Actions {
+		if i.Name == action.GetName() {
+			i.Runs++
+			return
+		}
+	}
+	s.Actions = append(s.Actions, &actionSimulation{Name: action.GetName(), Actionner: action.GetActionner(), Runs: 1})
+}
+
+// simulationTarget returns the object the actions would have targeted for the
+// event: the pod, the resource of an audit event, or the host as last resort.
+func simulationTarget(event *events.Event) string {
+	if pod := event.GetPodName(); pod != "" && event.GetTargetResource() == "" {
+		return "pod:" + event.GetNamespaceName() + "/" + pod
+	}
+	if name := event.GetTargetName(); name != "" {
+		if namespace := event.GetTargetNamespace(); namespace != "" {
+			return event.GetTargetResource() + ":" + namespace + "/" + name
+		}
+		return event.GetTargetResource() + ":" + name
+	}
+	if event.GetHostname() != "" {
+		return "host:" + event.GetHostname()
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	ruleengine "github.com/falcosecurity/falco-talon/internal/rules"
+)
+
+func TestSimulateEventsCountsMatchesActionsAndDuplicates(t *testing.T) {
+	rules := &[]*ruleengine.Rule{
+		{
+			Name: "terminate-shell",
+			Match: ruleengine.Match{
+				Rules: []string{"Terminal shell in container"},
+			},
+			Actions: []*ruleengine.Action{
+				{Name: "label", Actionner: "kubernetes:label"},
+				{Name: "terminate", Actionner: "kubernetes:terminate"},
+				{Name: "never-run", Actionner: "kubernetes:label"},
+			},
+		},
+		{
+			Name: "other",
+			Match: ruleengine.Match{
+				Rules: []string{"Other rule"},
+			},
+			Actions: []*ruleengine.Action{
+				{Name: "label", Actionner: "kubernetes:label"},
+			},
+		},
+	}
+
+	events := strings.Join([]string{
+		`{"rule":"Terminal shell in container","output":"shell 1","time":"2024-01-01T00:00:00Z","output_fields":{"k8s.ns.name":"prod","k8s.pod.name":"nginx"}}`,
+		`{"rule":"Terminal shell in container","output":"shell 1","time":"2024-01-01T00:00:01Z","output_fields":{"k8s.ns.name":"prod","k8s.pod.name":"nginx"}}`,
+		`{"rule":"Terminal shell in container","output":"shell 2","time":"2024-01-01T00:00:02Z","output_fields":{"k8s.ns.name":"dev","k8s.pod.name":"redis"}}`,
+		``,
+		`not json`,
+		`{"rule":"Unknown","output":"unknown","time":"2024-01-01T00:00:03Z"}`,
+	}, "\n")
+
+	report, err := simulateEvents(rules, strings.NewReader(events), 5)
+	if err != nil {
+		t.Fatalf("simulate events: %v", err)
+	}
+
+	if report.Events != 4 || report.InvalidEvents != 1 {
+		t.Fatalf("expected 4 events and 1 invalid event, got %d and %d", report.Events, report.InvalidEvents)
+	}
+	if report.RateLimitHits != 1 {
+		t.Fatalf("expected 1 rate limit hit, got %d", report.RateLimitHits)
+	}
+	if report.MatchedEvents != 2 || report.UnmatchedEvents != 1 {
+		t.Fatalf("expected 2 matched and 1 unmatched events, got %d and %d", report.MatchedEvents, report.UnmatchedEvents)
+	}
+
+	s := report.Rules[0]
+	if s.Matches != 2 {
+		t.Fatalf("expected 2 matches, got %d", s.Matches)
+	}
+	if len(s.Actions) != 2 {
+		t.Fatalf("expected the chain to stop after kubernetes:terminate, got %#v", s.Actions)
+	}
+	if s.Actions[1].Name != "terminate" || s.Actions[1].Runs != 2 {
+		t.Fatalf("expected terminate to run twice, got %#v", s.Actions[1])
+	}
+	if len(s.Targets) != 2 || s.Targets[0] != "pod:dev/redis" || s.Targets[1] != "pod:prod/nginx" {
+		t.Fatalf("unexpected targets %#v", s.Targets)
+	}
+	if report.Rules[1].Matches != 0 {
+		t.Fatalf("expected no match for the second rule, got %d", report.Rules[1].Matches)
+	}
+}
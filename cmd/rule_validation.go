@@ -1,95 +1,162 @@
 package cmd
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/falcosecurity/falco-talon/actionners"
 	ruleengine "github.com/falcosecurity/falco-talon/internal/rules"
 	"github.com/falcosecurity/falco-talon/outputs"
 	"github.com/falcosecurity/falco-talon/utils"
 )
 
+// diagnostic is the machine-readable form of an error found in the rules.
+type diagnostic struct {
+	File         string `json:"file,omitempty"`
+	Severity     string `json:"severity"`
+	Message      string `json:"message"`
+	Rule         string `json:"rule,omitempty"`
+	Action       string `json:"action,omitempty"`
+	Actionner    string `json:"actionner,omitempty"`
+	OutputTarget string `json:"output_target,omitempty"`
+	Notifier     string `json:"notifier,omitempty"`
+	Line         int    `json:"line,omitempty"`
+	Column       int    `json:"column,omitempty"`
+}
+
+type diagnosticsReport struct {
+	Diagnostics []diagnostic `json:"diagnostics"`
+	Valid       bool         `json:"valid"`
+}
+
 func validateRules(rules *[]*ruleengine.Rule) bool {
+	errs := checkRules(rules)
+	for _, i := range errs {
+		utils.PrintLog(utils.ErrorStr, i)
+	}
+	return len(errs) == 0
+}
+
+// checkRules returns the errors in the parameters of the actions and of their
+// outputs, with the location of the action in the rules files.
+func checkRules(rules *[]*ruleengine.Rule) []utils.LogLine {
 	defaultActionners := actionners.ListDefaultActionners()
 	defaultOutputs := outputs.ListDefaultOutputs()
 
-	valid := true
+	errs := []utils.LogLine{}
 	for _, rule := range *rules {
-		for _, action := range rule.GetActions() {
+		for _, action := range rule.ListAllActions() {
 			actionner := defaultActionners.FindActionner(action.GetActionner())
 			if actionner == nil {
-				utils.PrintLog(utils.ErrorStr, utils.LogLine{
+				errs = append(errs, utils.LogLine{
 					Error:     "unknown actionner",
 					Rule:      rule.GetName(),
 					Action:    action.GetName(),
 					Actionner: action.GetActionner(),
+					Location:  action.Location.String(),
 					Message:   rulesStr,
 				})
-				valid = false
 				continue
 			}
 
 			if err := actionner.CheckParameters(action); err != nil {
-				utils.PrintLog(utils.ErrorStr, utils.LogLine{
+				errs = append(errs, utils.LogLine{
 					Error:     err.Error(),
 					Rule:      rule.GetName(),
 					Action:    action.GetName(),
 					Actionner: action.GetActionner(),
+					Location:  action.Location.String(),
 					Message:   rulesStr,
 				})
-				valid = false
 			}
 
 			output := action.GetOutput()
 			if output == nil {
 				if actionner.Information().RequireOutput {
-					utils.PrintLog(utils.ErrorStr, utils.LogLine{
+					errs = append(errs, utils.LogLine{
 						Error:     "an output is required",
 						Rule:      rule.GetName(),
 						Action:    action.GetName(),
 						Actionner: action.GetActionner(),
+						Location:  action.Location.String(),
 						Message:   rulesStr,
 					})
-					valid = false
 				}
 				continue
 			}
 
 			target := defaultOutputs.FindOutput(output.GetTarget())
 			if target == nil {
-				utils.PrintLog(utils.ErrorStr, utils.LogLine{
+				errs = append(errs, utils.LogLine{
 					Error:        "unknown target",
 					Rule:         rule.GetName(),
 					Action:       action.GetName(),
 					OutputTarget: output.GetTarget(),
+					Location:     action.Location.String(),
 					Message:      rulesStr,
 				})
-				valid = false
 				continue
 			}
 
 			if len(output.Parameters) == 0 {
-				utils.PrintLog(utils.ErrorStr, utils.LogLine{
+				errs = append(errs, utils.LogLine{
 					Error:        "missing parameters for the output",
 					Rule:         rule.GetName(),
 					Action:       action.GetName(),
 					OutputTarget: output.GetTarget(),
+					Location:     action.Location.String(),
 					Message:      rulesStr,
 				})
-				valid = false
 				continue
 			}
 
 			if err := target.CheckParameters(output); err != nil {
-				utils.PrintLog(utils.ErrorStr, utils.LogLine{
+				errs = append(errs, utils.LogLine{
 					Error:        err.Error(),
 					Rule:         rule.GetName(),
 					Action:       action.GetName(),
 					OutputTarget: output.GetTarget(),
+					Location:     action.Location.String(),
 					Message:      rulesStr,
 				})
-				valid = false
 			}
 		}
 	}
 
-	return valid
+	return errs
+}
+
+// newDiagnostic converts a log line into a diagnostic, the location
+// 'file:line:column' is split from the right as the file may contain ':'.
+func newDiagnostic(severity string, log utils.LogLine) diagnostic {
+	d := diagnostic{
+		File:         log.Location,
+		Severity:     severity,
+		Message:      log.Error,
+		Rule:         log.Rule,
+		Action:       log.Action,
+		Actionner:    log.Actionner,
+		OutputTarget: log.OutputTarget,
+		Notifier:     log.Notifier,
+	}
+	var n []int
+	for len(n) < 2 {
+		i := strings.LastIndex(d.File, ":")
+		if i < 0 {
+			break
+		}
+		v, err := strconv.Atoi(d.File[i+1:])
+		if err != nil {
+			break
+		}
+		n = append([]int{v}, n...)
+		d.File = d.File[:i]
+	}
+	switch len(n) {
+	case 1:
+		d.Line = n[0]
+	case 2:
+		d.Line, d.Column = n[0], n[1]
+	}
+	return d
 }
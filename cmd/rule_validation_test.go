@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	ruleengine "github.com/falcosecurity/falco-talon/internal/rules"
+	"github.com/falcosecurity/falco-talon/utils"
 )
 
 func TestValidateRulesRejectsUnknownActionner(t *testing.T) {
@@ -50,3 +51,32 @@ func TestValidateRulesRejectsUnknownOutputTarget(t *testing.T) {
 		t.Fatal("expected validation to reject unknown output targets")
 	}
 }
+
+func TestCheckRulesReportsTheLocationOfTheAction(t *testing.T) {
+	location := ruleengine.Location{File: "c:/rules/rules.yaml", Line: 12, Column: 7}
+	rules := &[]*ruleengine.Rule{
+		{
+			Name: "test-rule",
+			Actions: []*ruleengine.Action{
+				{
+					Name:      "test-action",
+					Actionner: "tests:missing",
+					Location:  location,
+				},
+			},
+		},
+	}
+
+	errs := checkRules(rules)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %#v", errs)
+	}
+
+	d := newDiagnostic(utils.ErrorStr, errs[0])
+	if d.File != location.File || d.Line != location.Line || d.Column != location.Column {
+		t.Fatalf("unexpected location in %#v", d)
+	}
+	if d.Message != "unknown actionner" || d.Rule != "test-rule" || d.Action != "test-action" || d.Severity != utils.ErrorStr {
+		t.Fatalf("unexpected diagnostic %#v", d)
+	}
+}
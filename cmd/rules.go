@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/falcosecurity/falco-talon/configuration"
 	ruleengine "github.com/falcosecurity/falco-talon/internal/rules"
@@ -30,6 +32,18 @@ var rulesChecksCmd = &cobra.Command{
 		if len(rulesFiles) != 0 {
 			config.RulesFiles = rulesFiles
 		}
+		format, _ := cmd.Flags().GetString(formatStr)
+		if format == jsonStr {
+			utils.SetLogOutput(os.Stderr)
+			var errs []utils.LogLine
+			if rules := ruleengine.ParseRules(config.RulesFiles); rules != nil {
+				errs = checkRules(rules)
+			} else {
+				errs = ruleengine.GetDiagnostics()
+			}
+			printDiagnostics(utils.ErrorStr, errs)
+			return
+		}
 		rules := ruleengine.ParseRules(config.RulesFiles)
 		if rules == nil {
 			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: invalidRulesStr, Message: rulesStr})
@@ -57,26 +71,51 @@ var rulesPrintCmd = &cobra.Command{
 		if rules == nil {
 			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: invalidRulesStr, Message: rulesStr})
 		}
+		type yamlAction struct {
+			Parameters map[string]any `yaml:"parameters,omitempty"`
+			Output     struct {
+				Parameters map[string]any `yaml:"parameters"`
+				Target     string         `yaml:"target"`
+			} `yaml:"output,omitempty"`
+			Name               string   `yaml:"action"`
+			Description        string   `yaml:"description,omitempty"`
+			Actionner          string   `yaml:"actionner"`
+			Continue           string   `yaml:"continue,omitempty"`
+			IgnoreErrors       string   `yaml:"ignore_errors,omitempty"`
+			AdditionalContexts []string `yaml:"additional_contexts,omitempty"`
+			RequireApproval    *struct {
+				Timeout         string `yaml:"timeout,omitempty"`
+				DefaultDecision string `yaml:"default,omitempty"`
+			} `yaml:"require_approval,omitempty"`
+			Delay     string       `yaml:"delay,omitempty"`
+			When      string       `yaml:"when,omitempty"`
+			OnFailure []yamlAction `yaml:"on_failure,omitempty"`
+		}
+		type yamlParallel struct {
+			Timeout string       `yaml:"timeout,omitempty"`
+			Actions []yamlAction `yaml:"actions"`
+		}
+		type yamlStep struct {
+			Offense int   `yaml:"offense"`
+			Actions []any `yaml:"actions"`
+		}
+		type yamlEscalation struct {
+			Key   string     `yaml:"key,omitempty"`
+			Decay string     `yaml:"decay,omitempty"`
+			Steps []yamlStep `yaml:"steps"`
+		}
 		type yamlFile struct {
-			Name        string   `yaml:"rule"`
-			Description string   `yaml:"description,omitempty"`
-			Continue    string   `yaml:"continue,omitempty"`
-			DryRun      string   `yaml:"dry_run,omitempty"`
-			Notifiers   []string `yaml:"notifiers,omitempty"`
-			Actions     []struct {
-				Parameters map[string]any `yaml:"parameters,omitempty"`
-				Output     struct {
-					Parameters map[string]any `yaml:"parameters"`
-					Target     string         `yaml:"target"`
-				} `yaml:"output,omitempty"`
-				Name               string   `yaml:"action"`
-				Description        string   `yaml:"description,omitempty"`
-				Actionner          string   `yaml:"actionner"`
-				Continue           string   `yaml:"continue,omitempty"`
-				IgnoreErrors       string   `yaml:"ignore_errors,omitempty"`
-				AdditionalContexts []string `yaml:"additional_contexts,omitempty"`
-			} `yaml:"actions"`
-			Match struct {
+			Name        string       `yaml:"rule"`
+			Description string       `yaml:"description,omitempty"`
+			Continue    string       `yaml:"continue,omitempty"`
+			DryRun      string       `yaml:"dry_run,omitempty"`
+			Notifiers   []string     `yaml:"notifiers,omitempty"`
+			Actions     []yamlAction `yaml:"-"`
+			// Steps are the actions with their parallel groups.
+			Steps      []any           `yaml:"actions,omitempty"`
+			Escalation *yamlEscalation `yaml:"escalation,omitempty" copier:"-"`
+			Finally    []yamlAction    `yaml:"finally,omitempty"`
+			Match      struct {
 				OutputFields []string `yaml:"output_fields,omitempty"`
 				Priority     string   `yaml:"priority,omitempty"`
 				Source       string   `yaml:"source,omitempty"`
@@ -89,8 +128,159 @@ var rulesPrintCmd = &cobra.Command{
 		if err := copier.Copy(&q, &rules); err != nil {
 			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error()})
 		}
+		// regroup returns the actions of the step with their parallel groups
+		regroup := func(rule *ruleengine.Rule, actions []yamlAction, step *ruleengine.EscalationStep) []any {
+			var l []any
+			var group *ruleengine.Parallel
+			for m, j := range rule.GetActions() {
+				switch {
+				case j.GetStep() != step:
+				case j.GetParallel() == nil:
+					l = append(l, actions[m])
+				case j.GetParallel() != group:
+					group = j.GetParallel()
+					l = append(l, map[string]*yamlParallel{"parallel": {Timeout: group.Timeout}})
+					fallthrough
+				default:
+					p := l[len(l)-1].(map[string]*yamlParallel)["parallel"]
+					p.Actions = append(p.Actions, actions[m])
+				}
+			}
+			return l
+		}
+		for n, i := range *rules {
+			e := i.GetEscalation()
+			if e == nil {
+				q[n].Steps = regroup(i, q[n].Actions, nil)
+				continue
+			}
+			q[n].Escalation = &yamlEscalation{Key: e.Key, Decay: e.Decay}
+			for _, j := range e.Steps {
+				q[n].Escalation.Steps = append(q[n].Escalation.Steps, yamlStep{Offense: j.Offense, Actions: regroup(i, q[n].Actions, j)})
+			}
+		}
 
 		b, _ := yaml.Marshal(q)
 		fmt.Printf("---\n%s", b)
 	},
 }
+
+var rulesSimulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Replay recorded Falco events against the rules",
+	Long: `Replay a file of recorded Falco events against the rules, in forced dry-run.
+No action is performed. A summary per rule is printed with the number of matches, the actions
+which would have been run, the affected targets and the events dropped by the deduplication.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		configFile, _ := cmd.Flags().GetString("config")
+		config := configuration.CreateConfiguration(configFile)
+		utils.SetLogFormat(config.LogFormat)
+		rulesFiles, _ := cmd.Flags().GetStringArray(rulesStr)
+		if len(rulesFiles) != 0 {
+			config.RulesFiles = rulesFiles
+		}
+		eventsFile, _ := cmd.Flags().GetString(eventsStr)
+		if eventsFile == "" {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: "missing events file", Message: eventsStr})
+		}
+		rules := ruleengine.ParseRules(config.RulesFiles)
+		if rules == nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: invalidRulesStr, Message: rulesStr})
+		}
+		if !validateRules(rules) {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: invalidRulesStr, Message: rulesStr})
+		}
+
+		f, err := os.Open(eventsFile) // #nosec G304 -- events file path comes from the operator
+		if err != nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: eventsStr})
+		}
+		defer f.Close()
+
+		report, err := simulateEvents(rules, f, config.Deduplication.TimeWindowSeconds)
+		if err != nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: eventsStr})
+		}
+
+		b, _ := yaml.Marshal(report)
+		fmt.Printf("---\n%s", b)
+	},
+}
+
+var rulesSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the rules files",
+	Long: `Print the JSON Schema of the rules files in the stdout.
+The parameters of the actions and outputs are generated from the available actionners and outputs,
+the schema can be used by the editors and the CI to validate and autocomplete the rules.`,
+	Run: func(_ *cobra.Command, _ []string) {
+		b, err := json.MarshalIndent(rulesSchema(), "", "  ")
+		if err != nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: rulesStr})
+		}
+		fmt.Println(string(b))
+	},
+}
+
+var rulesLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Lint Falco Talon Rules file",
+	Long: `Check the logic of the rules: rules shadowed by a previous one, unreachable actions,
+required output fields missing for the source, notifiers not configured and overrides with a wrong type.`,
+	Run: func(cmd *cobra.Command, _ []string) {
+		configFile, _ := cmd.Flags().GetString("config")
+		config := configuration.CreateConfiguration(configFile)
+		utils.SetLogFormat(config.LogFormat)
+		rulesFiles, _ := cmd.Flags().GetStringArray(rulesStr)
+		if len(rulesFiles) != 0 {
+			config.RulesFiles = rulesFiles
+		}
+		format, _ := cmd.Flags().GetString(formatStr)
+		if format == jsonStr {
+			utils.SetLogOutput(os.Stderr)
+			rules := ruleengine.ParseRules(config.RulesFiles)
+			if rules == nil {
+				printDiagnostics(utils.ErrorStr, ruleengine.GetDiagnostics())
+				return
+			}
+			if errs := checkRules(rules); len(errs) != 0 {
+				printDiagnostics(utils.ErrorStr, errs)
+				return
+			}
+			printDiagnostics(utils.WarningStr, lintRules(rules, config))
+			return
+		}
+		rules := ruleengine.ParseRules(config.RulesFiles)
+		if rules == nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: invalidRulesStr, Message: rulesStr})
+		}
+		if !validateRules(rules) {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: invalidRulesStr, Message: rulesStr})
+		}
+		issues := lintRules(rules, config)
+		for _, i := range issues {
+			utils.PrintLog(utils.WarningStr, i)
+		}
+		if len(issues) != 0 {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: fmt.Sprintf("%v issue(s) found", len(issues)), Message: rulesStr})
+		}
+		utils.PrintLog(utils.InfoStr, utils.LogLine{Result: "no issue found", Message: rulesStr})
+	},
+}
+
+// printDiagnostics prints the errors in JSON in the stdout and exits with an
+// error code if there is any.
+func printDiagnostics(severity string, errs []utils.LogLine) {
+	report := diagnosticsReport{Valid: len(errs) == 0, Diagnostics: []diagnostic{}}
+	for _, i := range errs {
+		report.Diagnostics = append(report.Diagnostics, newDiagnostic(severity, i))
+	}
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: rulesStr})
+	}
+	fmt.Println(string(b))
+	if !report.Valid {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/schedules"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+var schedulesCmd = &cobra.Command{
+	Use:   schedulesStr,
+	Short: "Manage the delayed actions of a running Falco Talon",
+	Long:  "Manage the delayed actions of a running Falco Talon, through its API.",
+	Run:   nil,
+}
+
+var schedulesListCmd = &cobra.Command{
+	Use:   listStr,
+	Short: "List the delayed actions waiting for their time",
+	Long:  "List the delayed actions waiting for their time, the next to fire first.",
+	Run: func(cmd *cobra.Command, _ []string) {
+		b, err := callSchedulesAPI(cmd, http.MethodGet, "/schedules", nil)
+		if err != nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: schedulesStr})
+		}
+		var l []*schedules.Schedule
+		if err := json.Unmarshal(b, &l); err != nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: schedulesStr})
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tFIRES AT\tRULE\tACTION\tACTIONNER")
+		for _, i := range l {
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", i.ID, i.FiresAt.Format(time.RFC3339), i.Rule, i.Action, i.Actionner)
+		}
+		_ = w.Flush()
+	},
+}
+
+var schedulesCancelCmd = &cobra.Command{
+	Use:   "cancel <id>",
+	Short: "Cancel a delayed action and the rest of its chain",
+	Long:  "Cancel a delayed action and the rest of its chain, the 'finally' actions of the rule are still run.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		by, _ := cmd.Flags().GetString("by")
+		body, _ := json.Marshal(map[string]string{"by": by})
+		if _, err := callSchedulesAPI(cmd, http.MethodPost, "/schedules/"+url.PathEscape(args[0])+"/cancel", body); err != nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: schedulesStr})
+		}
+		utils.PrintLog(utils.InfoStr, utils.LogLine{Result: fmt.Sprintf("the delayed action '%v' is cancelled", args[0]), Message: schedulesStr})
+	},
+}
+
+// callSchedulesAPI sends a request to the /schedules endpoints.
+func callSchedulesAPI(cmd *cobra.Command, method, path string, body []byte) ([]byte, error) {
+	return callAPI(cmd, method, path, body, "schedules.token", func(c *configuration.Configuration) string { return c.Schedules.Token })
+}
+
+// callAPI sends a request to an endpoint of a running Falco Talon, the URL and
+// the token come from the flags or else from the configuration.
+func callAPI(cmd *cobra.Command, method, path string, body []byte, tokenSetting string, tokenOf func(*configuration.Configuration) string) ([]byte, error) {
+	configFile, _ := cmd.Flags().GetString("config")
+	config := configuration.CreateConfiguration(configFile)
+
+	server, _ := cmd.Flags().GetString("url")
+	if server == "" {
+		server = fmt.Sprintf("http://localhost:%v", config.ListenPort)
+	}
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = tokenOf(config)
+	}
+	if token == "" {
+		return nil, fmt.Errorf("a token is required, with the flag --token or '%v' in the configuration", tokenSetting)
+	}
+
+	req, err := http.NewRequestWithContext(cmd.Context(), method, strings.TrimSuffix(server, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return nil, fmt.Errorf("%v: %v", resp.Status, strings.TrimSpace(string(b)))
+	}
+	return b, nil
+}
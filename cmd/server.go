@@ -4,21 +4,30 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 
 	"github.com/falcosecurity/falco-talon/internal/handler"
 	"github.com/falcosecurity/falco-talon/internal/otlp/metrics"
 	"github.com/falcosecurity/falco-talon/internal/otlp/traces"
 
-	"github.com/fsnotify/fsnotify"
-
 	"github.com/falcosecurity/falco-talon/actionners"
 	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/approvals"
+	"github.com/falcosecurity/falco-talon/internal/audit"
+	"github.com/falcosecurity/falco-talon/internal/escalation"
+	"github.com/falcosecurity/falco-talon/internal/history"
 	k8s "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
 	"github.com/falcosecurity/falco-talon/internal/nats"
+	"github.com/falcosecurity/falco-talon/internal/pause"
 	ruleengine "github.com/falcosecurity/falco-talon/internal/rules"
+	"github.com/falcosecurity/falco-talon/internal/schedules"
+	"github.com/falcosecurity/falco-talon/internal/suppressions"
 	"github.com/falcosecurity/falco-talon/notifiers"
 	"github.com/falcosecurity/falco-talon/outputs"
 	"github.com/falcosecurity/falco-talon/utils"
@@ -57,73 +66,121 @@ var serverCmd = &cobra.Command{
 			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: outputsStr})
 		}
 
-		// init notifiers
-		notifiers.Init()
+		// init notifiers, the failures are logged and not blocking at start
+		_ = notifiers.Init()
+
+		// open the audit log, the actions are not run without their audit
+		if err := audit.Init(); err != nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: auditStr})
+		}
+		defer func() {
+			if l := audit.GetLog(); l != nil {
+				l.Close()
+			}
+		}()
+
+		// open the history of the actions
+		if config.History.StoreFile != "" {
+			if err := initHistory(config.History); err != nil {
+				utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: historyStr})
+			}
+			defer func() {
+				if s := history.GetStore(); s != nil {
+					s.Close()
+				}
+			}()
+		}
+
+		// restore the pause of the actions, the actions are not run without it
+		if err := initPause(config.Pause); err != nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: pauseStr})
+		}
+		defer pause.GetStore().Close()
+
+		// restore the suppressions
+		if err := suppressions.Init(config.Suppressions.StoreFile, config.Suppressions.Annotations); err != nil {
+			utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: suppressionsStr})
+		}
+		defer func() {
+			if s := suppressions.GetStore(); s != nil {
+				s.Close()
+			}
+		}()
+
+		// restore the offenses counted for the escalations
+		if err := escalation.Init(config.Escalations.StoreFile); err != nil {
+			utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: escalationsStr})
+		}
+		defer func() {
+			if err := escalation.GetStore().Close(); err != nil {
+				utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: escalationsStr})
+			}
+		}()
+
+		// restore the pending approvals and the delayed actions last, their
+		// actions can be resumed right away, with the audit, the history, the
+		// pause, the suppressions and the escalations ready
+		if err := approvals.Init(config.Approvals.StoreFile, actionners.ResumeApproval); err != nil {
+			utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: approvalsStr})
+		}
+		defer func() {
+			if s := approvals.GetStore(); s != nil {
+				s.Close()
+			}
+		}()
+
+		if err := schedules.Init(config.Schedules.StoreFile, actionners.ResumeSchedule); err != nil {
+			utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: schedulesStr})
+		}
+		defer func() {
+			if s := schedules.GetStore(); s != nil {
+				s.Close()
+			}
+		}()
 
 		if rules != nil {
-			utils.PrintLog(utils.InfoStr, utils.LogLine{Result: fmt.Sprintf("%v rule(s) has/have been successfully loaded", len(*rules)), Message: initStr})
+			rs := ruleengine.GetRuleSet()
+			utils.PrintLog(utils.InfoStr, utils.LogLine{
+				Result:        fmt.Sprintf("%v rule(s) has/have been successfully loaded", len(*rules)),
+				RulesVersion:  fmt.Sprintf("%v", rs.Version),
+				RulesChecksum: rs.ShortChecksum(),
+				Message:       initStr,
+			})
 		}
 
 		if config.WatchRules {
 			utils.PrintLog(utils.InfoStr, utils.LogLine{Result: "watch of rules enabled", Message: initStr})
 		}
 
+		r := newReloader(configFile, rulesFiles)
+
+		// reload on SIGHUP
+		go func() {
+			c := make(chan os.Signal, 1)
+			signal.Notify(c, syscall.SIGHUP)
+			for range c {
+				_ = r.reload("sighup")
+			}
+		}()
+
 		srv := http.Server{
 			Addr:         fmt.Sprintf("%s:%d", config.ListenAddress, config.ListenPort),
 			ReadTimeout:  2 * time.Second,
 			WriteTimeout: 2 * time.Second,
-			Handler:      newHTTPHandler(),
+			Handler:      newHTTPHandler(r),
 		}
 
 		if config.WatchRules {
-			go func() {
-				ignore := false
-				watcher, err := fsnotify.NewWatcher()
-				if err != nil {
-					utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: rulesStr})
-					return
-				}
-				defer func() { _ = watcher.Close() }()
-				for _, i := range config.RulesFiles {
-					if err := watcher.Add(i); err != nil {
-						utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: rulesStr})
-						return
-					}
-				}
-				for {
-					select {
-					case event := <-watcher.Events:
-						if event.Has(fsnotify.Write) && !ignore {
-							ignore = true
-							go func() {
-								time.Sleep(1 * time.Second)
-								ignore = false
-							}()
-							utils.PrintLog(utils.InfoStr, utils.LogLine{Result: "changes detected", Message: rulesStr})
-							newRules := ruleengine.ParseRules(config.RulesFiles)
-							if newRules == nil {
-								utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: invalidRulesStr, Message: rulesStr})
-								break
-							}
-
-							if newRules != nil {
-								if !validateRules(newRules) {
-									utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: invalidRulesStr, Message: rulesStr})
-									break
-								}
-								utils.PrintLog(utils.InfoStr, utils.LogLine{Result: fmt.Sprintf("%v rules have been successfully loaded", len(*newRules)), Message: rulesStr})
-								rules = newRules
-								if err := actionners.Init(); err != nil {
-									utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: actionnersStr})
-									break
-								}
-							}
-						}
-					case err := <-watcher.Errors:
-						utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: rulesStr})
-					}
-				}
-			}()
+			w, err := newRulesWatcher(config.RulesFiles, configFile, defaultWatchDebounce, func() {
+				utils.PrintLog(utils.InfoStr, utils.LogLine{Result: "changes detected", Message: rulesStr})
+				_ = r.reload("watcher")
+			})
+			if err != nil {
+				utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: rulesStr})
+			} else {
+				go w.run()
+				defer func() { _ = w.close() }()
+			}
 		}
 		// start the local NATS
 		ns, err := nats.StartServer(config.Deduplication.TimeWindowSeconds)
@@ -186,7 +243,37 @@ var serverCmd = &cobra.Command{
 	},
 }
 
-func newHTTPHandler() http.Handler {
+func initHistory(config configuration.History) error {
+	retention, err := time.ParseDuration(config.Retention)
+	if err != nil {
+		return fmt.Errorf("wrong retention '%v' for the history", config.Retention)
+	}
+	return history.Init(config.StoreFile, retention)
+}
+
+func initPause(config configuration.Pause) error {
+	interval, err := time.ParseDuration(config.RefreshInterval)
+	if err != nil {
+		return fmt.Errorf("wrong refresh interval '%v' for the pause", config.RefreshInterval)
+	}
+	var configMaps typedcorev1.ConfigMapInterface
+	if config.ConfigMap != "" {
+		if err := k8s.Init(); err != nil {
+			return err
+		}
+		namespace := config.Namespace
+		if namespace == "" {
+			namespace = k8s.CurrentNamespace()
+		}
+		configMaps = k8s.GetClient().CoreV1().ConfigMaps(namespace)
+	} else {
+		// a local file is read by this replica only
+		interval = 0
+	}
+	return pause.Init(config.StoreFile, configMaps, config.ConfigMap, interval)
+}
+
+func newHTTPHandler(r *reloader) http.Handler {
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", metrics.Handler())
 
@@ -197,6 +284,18 @@ func newHTTPHandler() http.Handler {
 
 	handleFunc("/", handler.MainHandler)
 	handleFunc("/healthz", handler.HealthHandler)
+	handleFunc("/reload", handler.ReloadHandler(func() error { return r.reload("http") }))
+	handleFunc("GET /approvals", handler.ApprovalsHandler)
+	handleFunc("POST /approvals/{id}/{decision}", handler.ApprovalDecisionHandler)
+	handleFunc("GET /schedules", handler.SchedulesHandler)
+	handleFunc("POST /schedules/{id}/cancel", handler.ScheduleCancelHandler)
+	handleFunc("GET /history", handler.HistoryHandler)
+	handleFunc("GET /pause", handler.PauseStateHandler)
+	handleFunc("POST /pause", handler.PauseHandler)
+	handleFunc("POST /resume", handler.ResumeHandler)
+	handleFunc("GET /suppressions", handler.SuppressionsHandler)
+	handleFunc("POST /suppressions", handler.SuppressionAddHandler)
+	handleFunc("DELETE /suppressions/{id}", handler.SuppressionDeleteHandler)
 
 	otelHandler := otelhttp.NewHandler(
 		mux,
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/suppressions"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+var suppressionsCmd = &cobra.Command{
+	Use:   suppressionsStr,
+	Short: "Manage the suppressions of a running Falco Talon",
+	Long:  "Manage the time-limited suppressions of the responses to the events of some targets, through the API of a running Falco Talon.",
+	Run:   nil,
+}
+
+var suppressionsListCmd = &cobra.Command{
+	Use:   listStr,
+	Short: "List the suppressions not expired",
+	Long:  "List the suppressions not expired, the next to expire first.",
+	Run: func(cmd *cobra.Command, _ []string) {
+		b, err := callSuppressionsAPI(cmd, http.MethodGet, "/suppressions", nil)
+		if err != nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: suppressionsStr})
+		}
+		var l []*suppressions.Suppression
+		if err := json.Unmarshal(b, &l); err != nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: suppressionsStr})
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tEXPIRES AT\tRULE\tTARGET\tBY\tREASON")
+		for _, i := range l {
+			rule, target := i.Rule, i.Key+"="+i.Value
+			if rule == "" {
+				rule = "*"
+			}
+			if i.Key == suppressions.FieldStr {
+				target = i.Field + "=" + i.Value
+			}
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", i.ID, i.ExpiresAt.Format(time.RFC3339), rule, target, i.By, i.Reason)
+		}
+		_ = w.Flush()
+	},
+}
+
+var suppressionsAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a suppression",
+	Long: fmt.Sprintf(`Add a suppression of the responses of a rule, or of all the rules, to the events
+of a target, for a duration. The keys of the targets are %v, the value of a pod is
+'namespace/name' and the one of a workload 'Kind/namespace/name'.`, suppressions.Keys),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, _ []string) {
+		body := map[string]string{}
+		for _, i := range []string{"rule", "key", "field", "value", "duration", "reason", "by"} {
+			body[i], _ = cmd.Flags().GetString(i)
+		}
+		b, _ := json.Marshal(body)
+		b, err := callSuppressionsAPI(cmd, http.MethodPost, "/suppressions", b)
+		if err != nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: suppressionsStr})
+		}
+		var suppression suppressions.Suppression
+		if err := json.Unmarshal(b, &suppression); err != nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: suppressionsStr})
+		}
+		utils.PrintLog(utils.InfoStr, utils.LogLine{Result: fmt.Sprintf("the suppression '%v' is added until %v", suppression.ID, suppression.ExpiresAt.Format(time.RFC3339)), Message: suppressionsStr})
+	},
+}
+
+var suppressionsDeleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Delete a suppression before it expires",
+	Long:  "Delete a suppression before it expires.",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := callSuppressionsAPI(cmd, http.MethodDelete, "/suppressions/"+url.PathEscape(args[0]), nil); err != nil {
+			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: suppressionsStr})
+		}
+		utils.PrintLog(utils.InfoStr, utils.LogLine{Result: fmt.Sprintf("the suppression '%v' is deleted", args[0]), Message: suppressionsStr})
+	},
+}
+
+// callSuppressionsAPI sends a request to the /suppressions endpoints.
+func callSuppressionsAPI(cmd *cobra.Command, method, path string, body []byte) ([]byte, error) {
+	return callAPI(cmd, method, path, body, "suppressions.token", func(c *configuration.Configuration) string { return c.Suppressions.Token })
+}
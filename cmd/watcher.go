@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	ruleengine "github.com/falcosecurity/falco-talon/internal/rules"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+const defaultWatchDebounce = 1 * time.Second
+
+// rulesWatcher calls onChange when the rules files or the configuration file
+// change. The parent directories are watched rather than the files, to catch
+// the files replaced by a rename, the new files in the directories and the
+// swaps of the '..data' symlinks of the Kubernetes ConfigMaps volumes. The
+// events are debounced and onChange is called only if the content changed.
+type rulesWatcher struct {
+	watcher     *fsnotify.Watcher
+	onChange    func()
+	files       map[string]bool
+	dirs        map[string]bool
+	watchedDirs map[string]bool
+	configFile  string
+	fingerprint string
+	paths       []string
+	patterns    []string
+	debounce    time.Duration
+}
+
+func newRulesWatcher(paths []string, configFile string, debounce time.Duration, onChange func()) (*rulesWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	w := &rulesWatcher{
+		watcher:     watcher,
+		onChange:    onChange,
+		files:       map[string]bool{},
+		dirs:        map[string]bool{},
+		watchedDirs: map[string]bool{},
+		paths:       paths,
+		debounce:    debounce,
+	}
+
+	for _, i := range paths {
+		p := cleanPath(i)
+		switch info, err := os.Stat(p); {
+		case ruleengine.IsGlob(p):
+			w.patterns = append(w.patterns, p)
+			w.watchedDirs[globBaseDir(p)] = true
+		case err == nil && info.IsDir():
+			w.dirs[p] = true
+			w.watchedDirs[p] = true
+		default:
+			w.files[p] = true
+			w.watchedDirs[filepath.Dir(p)] = true
+		}
+	}
+	if configFile != "" {
+		w.configFile = cleanPath(configFile)
+		w.files[w.configFile] = true
+		w.watchedDirs[filepath.Dir(w.configFile)] = true
+	}
+
+	for i := range w.watchedDirs {
+		if err := watcher.Add(i); err != nil {
+			_ = watcher.Close()
+			return nil, err
+		}
+	}
+	w.addFiles()
+	w.fingerprint = w.computeFingerprint()
+
+	return w, nil
+}
+
+// run handles the events until the watcher is closed.
+func (w *rulesWatcher) run() {
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod || !w.isRelevant(event.Name) {
+				continue
+			}
+			timer.Reset(w.debounce)
+		case <-timer.C:
+			// the files replaced by a rename must be watched again
+			w.addFiles()
+			f := w.computeFingerprint()
+			if f == w.fingerprint {
+				continue
+			}
+			w.fingerprint = f
+			w.onChange()
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: rulesStr})
+		}
+	}
+}
+
+func (w *rulesWatcher) close() error {
+	return w.watcher.Close()
+}
+
+// addFiles watches the files themselves too, to catch the writes in the
+// targets of the symlinks located outside the watched directories.
+func (w *rulesWatcher) addFiles() {
+	for i := range w.files {
+		_ = w.watcher.Add(i)
+	}
+}
+
+func (w *rulesWatcher) isRelevant(name string) bool {
+	name = cleanPath(name)
+	if w.files[name] {
+		return true
+	}
+	dir := filepath.Dir(name)
+	if !w.watchedDirs[dir] {
+		return false
+	}
+	// swap of the symlinks in a ConfigMap volume
+	if strings.HasPrefix(filepath.Base(name), "..") {
+		return true
+	}
+	if w.dirs[dir] && ruleengine.IsRulesFileName(name) {
+		return true
+	}
+	for _, i := range w.patterns {
+		if ok, _ := filepath.Match(i, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// computeFingerprint returns a hash of the content of the configuration file
+// and of all the rules files.
+func (w *rulesWatcher) computeFingerprint() string {
+	h := sha256.New()
+	files := []string{}
+	if w.configFile != "" {
+		files = append(files, w.configFile)
+	}
+	rulesFiles, err := ruleengine.ExpandRulesFiles(w.paths)
+	if err != nil {
+		h.Write([]byte(err.Error()))
+	}
+	files = append(files, rulesFiles...)
+	for _, i := range files {
+		h.Write([]byte(i))
+		b, err := os.ReadFile(i) // #nosec G304 -- rules and config files paths come from the operator configuration
+		if err != nil {
+			h.Write([]byte(err.Error()))
+			continue
+		}
+		h.Write(b)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func cleanPath(path string) string {
+	if p, err := filepath.Abs(path); err == nil {
+		return p
+	}
+	return filepath.Clean(path)
+}
+
+// globBaseDir returns the deepest directory of a pattern without any meta
+// character.
+func globBaseDir(pattern string) string {
+	dir := filepath.Dir(pattern)
+	for ruleengine.IsGlob(dir) {
+		dir = filepath.Dir(dir)
+	}
+	return dir
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRulesWatcherDetectsConfigMapSwapsAndNewFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeRevision := func(name, content string) {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0o700); err != nil {
+			t.Fatalf("create dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name, "rules.yaml"), []byte(content), 0o600); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	// layout of a ConfigMap volume
+	writeRevision("..rev1", "- rule: a")
+	if err := os.Symlink("..rev1", filepath.Join(dir, "..data")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	if err := os.Symlink(filepath.Join("..data", "rules.yaml"), filepath.Join(dir, "rules.yaml")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	changes := make(chan struct{}, 10)
+	w, err := newRulesWatcher([]string{dir}, "", 50*time.Millisecond, func() { changes <- struct{}{} })
+	if err != nil {
+		t.Fatalf("create watcher: %v", err)
+	}
+	go w.run()
+	t.Cleanup(func() { _ = w.close() })
+
+	expectChanges := func(n int) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			select {
+			case <-changes:
+			case <-time.After(2 * time.Second):
+				t.Fatalf("expected %d change(s), got %d", n, i)
+			}
+		}
+		select {
+		case <-changes:
+			t.Fatal("expected the events to be debounced")
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+
+	// swap of the '..data' symlink, like the kubelet does
+	writeRevision("..rev2", "- rule: b")
+	if err := os.Symlink("..rev2", filepath.Join(dir, "..data_tmp")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	if err := os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if err := os.RemoveAll(filepath.Join(dir, "..rev1")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	expectChanges(1)
+
+	// new file dropped in the directory
+	if err := os.WriteFile(filepath.Join(dir, "extra.yaml"), []byte("- rule: c"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	expectChanges(1)
+
+	// a file which is not a rules file is ignored
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	expectChanges(0)
+
+	// removal of a rules file
+	if err := os.Remove(filepath.Join(dir, "extra.yaml")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	expectChanges(1)
+}
@@ -1,8 +1,10 @@
 package configuration
 
 import (
+	"errors"
 	"fmt"
 	"strings"
+	"sync/atomic"
 
 	"github.com/spf13/viper"
 
@@ -23,6 +25,19 @@ const (
 	defaultOtelCollectorUseInsecureGrpc bool   = false
 	defaultOtelCollectorPort            int    = 4317
 	defaultOtelCollectorGRPCTimeout            = 10
+	defaultApprovalsStoreFile           string = "/var/lib/falco-talon/approvals.json"
+	defaultSchedulesStoreFile           string = "/var/lib/falco-talon/schedules.json"
+	defaultEscalationsStoreFile         string = "/var/lib/falco-talon/escalations.json"
+	defaultHistoryStoreFile             string = "/var/lib/falco-talon/history.db"
+	defaultHistoryRetention             string = "720h"
+	defaultPauseStoreFile               string = "/var/lib/falco-talon/pause.json"
+	defaultSuppressionsStoreFile        string = "/var/lib/falco-talon/suppressions.json"
+	defaultPauseRefreshInterval         string = "5s"
+	defaultEnforcementMode              string = "enforce"
+	defaultKubernetesQPS                       = 5
+	defaultKubernetesBurst              int    = 10
+	defaultKubernetesCacheSyncTimeout   string = "30s"
+	defaultClustersField                string = "k8s.cluster.name"
 	configStr                           string = "config"
 )
 
@@ -41,14 +56,27 @@ type Configuration struct {
 	GcpConfig        GcpConfig                         `mapstructure:"gcp"`
 	LogFormat        string                            `mapstructure:"log_format"`
 	KubeConfig       string                            `mapstructure:"kubeconfig"`
+	Kubernetes       Kubernetes                        `mapstructure:"kubernetes"`
+	Clusters         Clusters                          `mapstructure:"clusters"`
 	ListenAddress    string                            `mapstructure:"listen_address"`
 	MinioConfig      MinioConfig                       `mapstructure:"minio"`
 	RulesFiles       []string                          `mapstructure:"rules_files"`
 	DefaultNotifiers []string                          `mapstructure:"default_notifiers"`
 	Otel             Otel                              `mapstructure:"otel"`
 	Deduplication    deduplication                     `mapstructure:"deduplication"`
+	Approvals        Approvals                         `mapstructure:"approvals"`
+	Schedules        Schedules                         `mapstructure:"schedules"`
+	Escalations      Escalations                       `mapstructure:"escalations"`
+	Audit            Audit                             `mapstructure:"audit"`
+	History          History                           `mapstructure:"history"`
+	Pause            Pause                             `mapstructure:"pause"`
+	Suppressions     Suppressions                      `mapstructure:"suppressions"`
+	Enforcement      Enforcement                       `mapstructure:"enforcement"`
+	Protected        ProtectedResources                `mapstructure:"protected_resources"`
+	Budgets          []Budget                          `mapstructure:"budgets"`
 	ListenPort       int                               `mapstructure:"listen_port"`
 	WatchRules       bool                              `mapstructure:"watch_rules"`
+	ReloadToken      string                            `mapstructure:"reload_token"`
 	PrintAllEvents   bool                              `mapstructure:"print_all_events"`
 }
 
@@ -57,6 +85,109 @@ type deduplication struct {
 	TimeWindowSeconds int  `mapstructure:"time_window_seconds"`
 }
 
+type Approvals struct {
+	StoreFile string `mapstructure:"store_file"`
+	Token     string `mapstructure:"token"`
+}
+
+type Schedules struct {
+	StoreFile string `mapstructure:"store_file"`
+	Token     string `mapstructure:"token"`
+}
+
+type Escalations struct {
+	StoreFile string `mapstructure:"store_file"`
+}
+
+// History is the store of the results of the actions, queryable through the
+// API.
+type History struct {
+	StoreFile string `mapstructure:"store_file"`
+	Retention string `mapstructure:"retention"`
+	Token     string `mapstructure:"token"`
+}
+
+// Pause is the store of the pause of the actions, a ConfigMap shared by the
+// replicas or a local file.
+type Pause struct {
+	StoreFile       string `mapstructure:"store_file"`
+	ConfigMap       string `mapstructure:"configmap"`
+	Namespace       string `mapstructure:"namespace"`
+	RefreshInterval string `mapstructure:"refresh_interval"`
+	Token           string `mapstructure:"token"`
+}
+
+type Suppressions struct {
+	StoreFile   string `mapstructure:"store_file"`
+	Token       string `mapstructure:"token"`
+	Annotations bool   `mapstructure:"annotations"`
+}
+
+// Enforcement is the mode of the actions, 'enforce', 'dry-run' or 'observe',
+// global and overridden by category of actionners or by actionner.
+type Enforcement struct {
+	Categories map[string]string `mapstructure:"categories"`
+	Actionners map[string]string `mapstructure:"actionners"`
+	Mode       string            `mapstructure:"mode"`
+}
+
+// Kubernetes is the rate limit of the client of the API server and its cache
+// of the pods, the nodes and their owners.
+type Kubernetes struct {
+	CacheSyncTimeout string  `mapstructure:"cache_sync_timeout"`
+	QPS              float32 `mapstructure:"qps"`
+	Burst            int     `mapstructure:"burst"`
+	Cache            bool    `mapstructure:"cache"`
+}
+
+// Clusters are the credentials of the remote clusters, by name. The cluster of
+// an event is the value of its output field 'field', the events without it or
+// of the 'local' cluster are for the cluster of Falco Talon.
+type Clusters struct {
+	Credentials map[string]ClusterCredentials `mapstructure:"credentials"`
+	Field       string                        `mapstructure:"field"`
+	Local       string                        `mapstructure:"local"`
+}
+
+type ClusterCredentials struct {
+	KubeConfig string `mapstructure:"kubeconfig"`
+	Context    string `mapstructure:"context"`
+}
+
+// Audit is the destination of the audit records of the actions, a local file
+// and/or an output target.
+type Audit struct {
+	Output         AuditOutput `mapstructure:"output"`
+	File           string      `mapstructure:"file"`
+	SigningKeyFile string      `mapstructure:"signing_key_file"`
+}
+
+type AuditOutput struct {
+	Parameters map[string]any `mapstructure:"parameters"`
+	Target     string         `mapstructure:"target"`
+}
+
+// ProtectedResources lists the resources on which no action is ever run,
+// whatever the rules.
+type ProtectedResources struct {
+	Namespaces     []string `mapstructure:"namespaces"`
+	LabelSelectors []string `mapstructure:"label_selectors"`
+	NodeSelectors  []string `mapstructure:"node_selectors"`
+	OwnerKinds     []string `mapstructure:"owner_kinds"`
+}
+
+// Budget limits the number of actions run by the matching actionners in a
+// sliding window, and the number of them running at once, for the whole
+// cluster or per namespace.
+type Budget struct {
+	Name        string   `mapstructure:"name"`
+	Scope       string   `mapstructure:"scope"`
+	Window      string   `mapstructure:"window"`
+	Actionners  []string `mapstructure:"actionners"`
+	Max         int      `mapstructure:"max"`
+	MaxInFlight int      `mapstructure:"max_in_flight"`
+}
+
 type AwsConfig struct {
 	Region     string `mapstructure:"region"`
 	AccessKey  string `mapstructure:"access_key"`
@@ -77,18 +208,32 @@ type MinioConfig struct {
 	UseSSL    bool   `mapstructure:"use_ssl"`
 }
 
-var config *Configuration
+var config atomic.Pointer[Configuration]
 
 func init() {
-	config = new(Configuration)
+	config.Store(new(Configuration))
 }
 
 func CreateConfiguration(configFile string) *Configuration {
+	c, err := LoadConfiguration(configFile)
+	if err != nil {
+		utils.PrintLog(utils.FatalStr, utils.LogLine{Error: err.Error(), Message: configStr})
+	}
+	config.Store(c)
+
+	return c
+}
+
+// LoadConfiguration reads the configuration from the file and the env vars,
+// without replacing the current one.
+func LoadConfiguration(configFile string) (*Configuration, error) {
+	c := new(Configuration)
 	v := viper.New()
 	v.SetDefault("listen_address", defaultListenAddress)
 	v.SetDefault("listen_port", defaultListPort)
 	v.SetDefault("rules_files", []string{defaultRulesFile})
 	v.SetDefault("kubeconfig", "")
+	v.SetDefault("reload_token", "")
 	v.SetDefault("log_format", "color")
 	v.SetDefault("default_notifiers", []string{})
 	v.SetDefault("watch_rules", defaultWatchRules)
@@ -101,6 +246,22 @@ func CreateConfiguration(configFile string) *Configuration {
 	v.SetDefault("otel.collector_port", defaultOtelCollectorPort)
 	v.SetDefault("otel.timeout", defaultOtelCollectorGRPCTimeout)
 	v.SetDefault("otel.collector_use_insecure_grpc", defaultOtelCollectorUseInsecureGrpc)
+	v.SetDefault("approvals.store_file", defaultApprovalsStoreFile)
+	v.SetDefault("approvals.token", "")
+	v.SetDefault("schedules.store_file", defaultSchedulesStoreFile)
+	v.SetDefault("schedules.token", "")
+	v.SetDefault("escalations.store_file", defaultEscalationsStoreFile)
+	v.SetDefault("history.store_file", defaultHistoryStoreFile)
+	v.SetDefault("history.retention", defaultHistoryRetention)
+	v.SetDefault("pause.store_file", defaultPauseStoreFile)
+	v.SetDefault("pause.refresh_interval", defaultPauseRefreshInterval)
+	v.SetDefault("suppressions.store_file", defaultSuppressionsStoreFile)
+	v.SetDefault("enforcement.mode", defaultEnforcementMode)
+	v.SetDefault("kubernetes.qps", defaultKubernetesQPS)
+	v.SetDefault("kubernetes.burst", defaultKubernetesBurst)
+	v.SetDefault("kubernetes.cache", false)
+	v.SetDefault("kubernetes.cache_sync_timeout", defaultKubernetesCacheSyncTimeout)
+	v.SetDefault("clusters.field", defaultClustersField)
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
 
@@ -108,19 +269,30 @@ func CreateConfiguration(configFile string) *Configuration {
 		v.SetConfigFile(configFile)
 		err := v.ReadInConfig()
 		if err != nil {
-			utils.PrintLog(utils.FatalStr, utils.LogLine{Error: fmt.Sprintf("error when reading config file: '%v'", err.Error()), Message: configStr})
+			return nil, fmt.Errorf("error when reading config file: '%v'", err.Error())
 		}
 	}
 
-	if err := v.Unmarshal(config); err != nil {
-		utils.PrintLog(utils.FatalStr, utils.LogLine{Error: fmt.Sprintf("error unmarshalling config file: '%v'", err.Error()), Message: configStr})
+	if err := v.Unmarshal(c); err != nil {
+		return nil, fmt.Errorf("error unmarshalling config file: '%v'", err.Error())
 	}
 
-	return config
+	// without its name, the events of the local cluster with the field would
+	// be for a cluster without credentials
+	if len(c.Clusters.Credentials) != 0 && c.Clusters.Local == "" {
+		return nil, errors.New("'clusters.local' is required with 'clusters.credentials'")
+	}
+
+	return c, nil
+}
+
+// SetConfiguration replaces the current configuration, used by the reloads.
+func SetConfiguration(c *Configuration) {
+	config.Store(c)
 }
 
 func GetConfiguration() *Configuration {
-	return config
+	return config.Load()
 }
 
 func (c *Configuration) ListDefaultNotifiers() []string {
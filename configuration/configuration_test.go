@@ -7,7 +7,7 @@ import (
 )
 
 func resetConfigForTest() {
-	config = new(Configuration)
+	config.Store(new(Configuration))
 }
 
 func TestCreateConfigurationAllowsOverridingOtelTimeoutFromFile(t *testing.T) {
@@ -41,3 +41,44 @@ func TestCreateConfigurationAllowsOverridingOtelTimeoutFromEnv(t *testing.T) {
 		t.Fatalf("expected otel timeout from env to be applied, got %d", cfg.Otel.Timeout)
 	}
 }
+
+func TestCreateConfigurationReadsTheEnforcementModes(t *testing.T) {
+	t.Cleanup(resetConfigForTest)
+	resetConfigForTest()
+
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(configFile, []byte(`
+enforcement:
+  categories:
+    cilium: dry-run
+  actionners:
+    kubernetes:drain: observe
+`), 0o600); err != nil {
+		t.Fatalf("write config file: %v", err)
+	}
+
+	cfg := CreateConfiguration(configFile)
+
+	if cfg.Enforcement.Mode != defaultEnforcementMode {
+		t.Fatalf("expected the default enforcement mode, got %q", cfg.Enforcement.Mode)
+	}
+	if cfg.Enforcement.Categories["cilium"] != "dry-run" || cfg.Enforcement.Actionners["kubernetes:drain"] != "observe" {
+		t.Fatalf("expected the modes of the category and of the actionner, got %+v", cfg.Enforcement)
+	}
+}
+
+func TestLoadConfigurationRequiresTheLocalClusterWithCredentials(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configFile, []byte(`
+clusters:
+  credentials:
+    prod-eu:
+      kubeconfig: /etc/falco-talon/clusters/prod-eu.yaml
+`), 0o600); err != nil {
+		t.Fatalf("write config file: %v", err)
+	}
+	if _, err := LoadConfiguration(configFile); err == nil {
+		t.Fatal("expected an error without the local cluster")
+	}
+}
@@ -0,0 +1,197 @@
+package approvals
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/falco-talon/internal/events"
+	"github.com/falcosecurity/falco-talon/internal/rules"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+const (
+	approvalStr string = "approval"
+	// TimeoutStr is the author of the decisions taken at the timeout.
+	TimeoutStr string = "timeout"
+)
+
+var (
+	ErrNotFound        = errors.New("approval not found")
+	ErrInvalidDecision = fmt.Errorf("the decision can be '%v' or '%v' only", rules.ApproveStr, rules.DenyStr)
+)
+
+// Approval is an action waiting for a decision before being run, with the
+// remaining actions of the chain of its rule.
+type Approval struct {
+	CreatedAt       time.Time     `json:"created_at"`
+	ExpiresAt       time.Time     `json:"expires_at"`
+	Event           *events.Event `json:"event"`
+	Results         rules.Results `json:"results,omitempty"`
+	ID              string        `json:"id"`
+	Rule            string        `json:"rule"`
+	Action          string        `json:"action"`
+	Actionner       string        `json:"actionner"`
+	DefaultDecision string        `json:"default_decision"`
+	RulesVersion    string        `json:"rules_version,omitempty"`
+	RulesChecksum   string        `json:"rules_checksum,omitempty"`
+	ActionIndex     int           `json:"action_index"`
+}
+
+// DecisionFunc is called once a decision is taken for an approval, by a human
+// or at the timeout, in its own goroutine.
+type DecisionFunc func(approval *Approval, decision, by string)
+
+// Store keeps the pending approvals in a file, to survive the restarts.
+type Store struct {
+	pending    map[string]*Approval
+	timers     map[string]*time.Timer
+	onDecision DecisionFunc
+	file       string
+	// wg waits for the decisions being applied
+	wg sync.WaitGroup
+	mu sync.Mutex
+}
+
+var store *Store
+
+// Init loads the pending approvals from the file and schedules their
+// timeouts, the ones expired during a restart get their default decision.
+func Init(file string, onDecision DecisionFunc) error {
+	s, err := NewStore(file, onDecision)
+	if err != nil {
+		return err
+	}
+	store = s
+	return nil
+}
+
+// GetStore returns the store initialized by Init, nil if the approvals are
+// disabled.
+func GetStore() *Store {
+	return store
+}
+
+func NewStore(file string, onDecision DecisionFunc) (*Store, error) {
+	s := &Store{
+		pending:    map[string]*Approval{},
+		timers:     map[string]*time.Timer{},
+		onDecision: onDecision,
+		file:       file,
+	}
+
+	var l []*Approval
+	if err := utils.ReadJSONFile(file, &l); err != nil {
+		return nil, fmt.Errorf("can't read the approvals file '%v': %v", file, err)
+	}
+	for _, i := range l {
+		s.pending[i.ID] = i
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, i := range s.pending {
+		s.schedule(i)
+	}
+	if len(s.pending) != 0 {
+		utils.PrintLog(utils.InfoStr, utils.LogLine{Result: fmt.Sprintf("%v pending approval(s) restored", len(s.pending)), Message: approvalStr})
+	}
+
+	return s, nil
+}
+
+// Add parks a new approval, its ID is generated.
+func (s *Store) Add(approval *Approval) error {
+	id, err := utils.NewID()
+	if err != nil {
+		return err
+	}
+	approval.ID = id
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending[id] = approval
+	if err := s.save(); err != nil {
+		delete(s.pending, id)
+		return err
+	}
+	s.schedule(approval)
+	return nil
+}
+
+// Decide removes the approval from the pending ones and saves the decision,
+// the decision is applied in the background, Decide doesn't wait for it.
+func (s *Store) Decide(id, decision, by string) (*Approval, error) {
+	if decision != rules.ApproveStr && decision != rules.DenyStr {
+		return nil, ErrInvalidDecision
+	}
+
+	s.mu.Lock()
+	approval, ok := s.pending[id]
+	if !ok {
+		s.mu.Unlock()
+		return nil, ErrNotFound
+	}
+	delete(s.pending, id)
+	if t := s.timers[id]; t != nil {
+		t.Stop()
+		delete(s.timers, id)
+	}
+	if err := s.save(); err != nil {
+		utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: approvalStr})
+	}
+	s.mu.Unlock()
+
+	if s.onDecision != nil {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.onDecision(approval, decision, by)
+		}()
+	}
+	return approval, nil
+}
+
+// List returns the pending approvals, the oldest first.
+func (s *Store) List() []*Approval {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l := make([]*Approval, 0, len(s.pending))
+	for _, i := range s.pending {
+		l = append(l, i)
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].CreatedAt.Before(l[j].CreatedAt) })
+	return l
+}
+
+// Close stops the timers and waits for the decisions being applied, the
+// pending approvals stay in the file.
+func (s *Store) Close() {
+	s.mu.Lock()
+	for _, i := range s.timers {
+		i.Stop()
+	}
+	s.timers = map[string]*time.Timer{}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Store) schedule(approval *Approval) {
+	id := approval.ID
+	decision := approval.DefaultDecision
+	s.timers[id] = time.AfterFunc(time.Until(approval.ExpiresAt), func() {
+		_, _ = s.Decide(id, decision, TimeoutStr)
+	})
+}
+
+// save writes the pending approvals in the file.
+func (s *Store) save() error {
+	l := make([]*Approval, 0, len(s.pending))
+	for _, i := range s.pending {
+		l = append(l, i)
+	}
+	return utils.WriteJSONFile(s.file, l)
+}
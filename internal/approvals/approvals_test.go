@@ -0,0 +1,113 @@
+package approvals
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/falco-talon/internal/events"
+	"github.com/falcosecurity/falco-talon/internal/rules"
+)
+
+type decision struct {
+	approval *Approval
+	decision string
+	by       string
+}
+
+func TestStorePersistsAndDecides(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "approvals", "approvals.json")
+	decisions := make(chan decision, 10)
+	onDecision := func(a *Approval, d, by string) { decisions <- decision{a, d, by} }
+
+	s, err := NewStore(file, onDecision)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	approval := &Approval{
+		CreatedAt:       time.Now(),
+		ExpiresAt:       time.Now().Add(time.Hour),
+		Event:           &events.Event{Output: "shell", TraceID: "trace-id"},
+		Rule:            "rule",
+		Action:          "terminate",
+		DefaultDecision: rules.DenyStr,
+		ActionIndex:     1,
+	}
+	if err := s.Add(approval); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if approval.ID == "" {
+		t.Fatal("expected an ID to be generated")
+	}
+	s.Close()
+
+	// restart
+	s, err = NewStore(file, onDecision)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	defer s.Close()
+	l := s.List()
+	if len(l) != 1 || l[0].ID != approval.ID || l[0].Event.TraceID != "trace-id" || l[0].ActionIndex != 1 {
+		t.Fatalf("expected the approval to be restored, got %#v", l)
+	}
+
+	if _, err := s.Decide(approval.ID, "maybe", "alice"); !errors.Is(err, ErrInvalidDecision) {
+		t.Fatalf("expected an invalid decision error, got %v", err)
+	}
+	if _, err := s.Decide(approval.ID, rules.ApproveStr, "alice"); err != nil {
+		t.Fatalf("decide: %v", err)
+	}
+	d := <-decisions
+	if d.approval.ID != approval.ID || d.decision != rules.ApproveStr || d.by != "alice" {
+		t.Fatalf("unexpected decision %#v", d)
+	}
+	if _, err := s.Decide(approval.ID, rules.ApproveStr, "alice"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+
+	// restart
+	s2, err := NewStore(file, onDecision)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	defer s2.Close()
+	if len(s2.List()) != 0 {
+		t.Fatal("expected no pending approval after the decision")
+	}
+}
+
+func TestStoreAppliesTheDefaultDecisionAtTimeout(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "approvals.json")
+	decisions := make(chan decision, 10)
+	s, err := NewStore(file, func(a *Approval, d, by string) { decisions <- decision{a, d, by} })
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.Add(&Approval{
+		CreatedAt:       time.Now(),
+		ExpiresAt:       time.Now().Add(10 * time.Millisecond),
+		DefaultDecision: rules.ApproveStr,
+	}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	select {
+	case d := <-decisions:
+		if d.decision != rules.ApproveStr || d.by != TimeoutStr {
+			t.Fatalf("unexpected decision %#v", d)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the default decision at the timeout")
+	}
+	if len(s.List()) != 0 {
+		t.Fatal("expected no pending approval after the timeout")
+	}
+}
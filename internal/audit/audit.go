@@ -0,0 +1,427 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/events"
+	"github.com/falcosecurity/falco-talon/internal/models"
+	"github.com/falcosecurity/falco-talon/internal/rules"
+	"github.com/falcosecurity/falco-talon/outputs"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+const (
+	auditStr  string = "audit"
+	ActionStr string = "action"
+	OutputStr string = "output"
+)
+
+// Record is the audit record of an attempt of an action or of an output.
+type Record struct {
+	StartedAt     time.Time         `json:"started_at"`
+	EndedAt       time.Time         `json:"ended_at"`
+	Parameters    map[string]any    `json:"parameters,omitempty"`
+	Target        map[string]string `json:"target,omitempty"`
+	Objects       map[string]string `json:"objects,omitempty"`
+	Kind          string            `json:"kind"`
+	RulesVersion  string            `json:"rules_version"`
+	RulesChecksum string            `json:"rules_checksum"`
+	Rule          string            `json:"rule"`
+	Action        string            `json:"action"`
+	Actionner     string            `json:"actionner,omitempty"`
+	OutputTarget  string            `json:"output_target,omitempty"`
+	Status        string            `json:"status"`
+	Error         string            `json:"error,omitempty"`
+	TraceID       string            `json:"trace_id,omitempty"`
+	PreviousHash  string            `json:"previous_hash"`
+	Sequence      uint64            `json:"sequence"`
+}
+
+// Entry is a line of the audit log: the record as written, its hash chained
+// with the hash of the previous record, and its signature if the records are
+// signed.
+type Entry struct {
+	Record    json.RawMessage `json:"record"`
+	Hash      string          `json:"hash"`
+	Signature string          `json:"signature,omitempty"`
+}
+
+// Log writes the records in a local append-only file and/or in an output
+// target.
+type Log struct {
+	file         *os.File
+	key          ed25519.PrivateKey
+	output       outputs.Output
+	outputConfig *rules.Output
+	lastHash     string
+	sequence     uint64
+	mu           sync.Mutex
+}
+
+var auditLog *Log
+
+// Init opens the audit log of the configuration, the chain continues from the
+// last record of the file. The audit is disabled without file and output.
+func Init() error {
+	config := configuration.GetConfiguration().Audit
+	if config.File == "" && config.Output.Target == "" {
+		auditLog = nil
+		return nil
+	}
+	l, err := NewLog(config)
+	if err != nil {
+		return err
+	}
+	auditLog = l
+	return nil
+}
+
+// GetLog returns the audit log initialized by Init, nil if the audit is
+// disabled.
+func GetLog() *Log {
+	return auditLog
+}
+
+func NewLog(config configuration.Audit) (*Log, error) {
+	l := new(Log)
+	if config.SigningKeyFile != "" {
+		key, err := ReadPrivateKey(config.SigningKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		l.key = key
+	}
+
+	if config.Output.Target != "" {
+		o := outputs.ListDefaultOutputs().FindOutput(config.Output.Target)
+		if o == nil {
+			return nil, fmt.Errorf("unknown output target '%v' for the audit", config.Output.Target)
+		}
+		l.outputConfig = &rules.Output{Target: config.Output.Target, Parameters: config.Output.Parameters}
+		if err := o.CheckParameters(l.outputConfig); err != nil {
+			return nil, fmt.Errorf("wrong parameters for the output of the audit: %v", err)
+		}
+		if err := o.Init(configuration.GetConfiguration()); err != nil {
+			return nil, err
+		}
+		l.output = o
+	}
+
+	if config.File != "" {
+		if err := os.MkdirAll(filepath.Dir(config.File), 0o750); err != nil {
+			return nil, err
+		}
+		f, err := os.OpenFile(config.File, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600) // #nosec G304 -- the file path comes from the operator configuration
+		if err != nil {
+			return nil, err
+		}
+		last, err := readLastEntry(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("can't read the audit file '%v': %v", config.File, err)
+		}
+		if last != nil {
+			var r Record
+			if err := json.Unmarshal(last.Record, &r); err != nil {
+				_ = f.Close()
+				return nil, fmt.Errorf("can't read the last record of the audit file '%v': %v", config.File, err)
+			}
+			l.lastHash, l.sequence = last.Hash, r.Sequence
+		}
+		l.file = f
+	}
+
+	return l, nil
+}
+
+// Close closes the file of the audit log.
+func (l *Log) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file != nil {
+		_ = l.file.Close()
+		l.file = nil
+	}
+}
+
+// Action writes the record of the attempt of an action, log is its final log
+// line.
+func Action(startedAt time.Time, rule *rules.Rule, action *rules.Action, event *events.Event, log utils.LogLine) {
+	l := GetLog()
+	if l == nil {
+		return
+	}
+	r := newRecord(startedAt, rule, action, event, log)
+	r.Kind = ActionStr
+	r.Actionner = action.GetActionner()
+	r.Parameters = action.GetParameters()
+	l.write(r)
+}
+
+// Output writes the record of the attempt of the output of an action, log is
+// the final log line of the output.
+func Output(startedAt time.Time, rule *rules.Rule, action *rules.Action, event *events.Event, log utils.LogLine) {
+	l := GetLog()
+	if l == nil {
+		return
+	}
+	r := newRecord(startedAt, rule, action, event, log)
+	r.Kind = OutputStr
+	r.OutputTarget = log.OutputTarget
+	if o := action.GetOutput(); o != nil {
+		r.Parameters = o.GetParameters()
+	}
+	l.write(r)
+}
+
+func newRecord(startedAt time.Time, rule *rules.Rule, action *rules.Action, event *events.Event, log utils.LogLine) *Record {
+	r := &Record{
+		StartedAt:     startedAt.UTC(),
+		EndedAt:       time.Now().UTC(),
+		Objects:       log.Objects,
+		RulesVersion:  log.RulesVersion,
+		RulesChecksum: log.RulesChecksum,
+		Rule:          rule.GetName(),
+		Action:        action.GetName(),
+		Status:        log.Status,
+		Error:         log.Error,
+		TraceID:       event.TraceID,
+	}
+	target := map[string]string{}
+	for k, v := range map[string]string{
+		"namespace": event.GetNamespaceName(),
+		"pod":       event.GetPodName(),
+		"hostname":  event.GetHostname(),
+		"resource":  event.GetTargetResource(),
+	} {
+		if v != "" {
+			target[k] = v
+		}
+	}
+	if len(target) != 0 {
+		r.Target = target
+	}
+	return r
+}
+
+// write chains the record with the previous one, signs it and writes it. The
+// failures are logged, they never block the actions.
+func (l *Log) write(r *Record) {
+	line, err := l.append(r)
+	if err != nil {
+		utils.PrintLog(utils.ErrorStr, utils.LogLine{Message: auditStr, Rule: r.Rule, Action: r.Action, TraceID: r.TraceID, Error: err.Error()})
+		return
+	}
+	// the copy to the output is done out of the lock, the objects are
+	// independent
+	if l.output != nil {
+		data := &models.Data{Name: fmt.Sprintf("audit-%020d.json", r.Sequence), Bytes: line}
+		if _, err := l.output.Run(l.outputConfig, data); err != nil {
+			utils.PrintLog(utils.ErrorStr, utils.LogLine{Message: auditStr, Rule: r.Rule, Action: r.Action, TraceID: r.TraceID, OutputTarget: l.outputConfig.Target, Error: err.Error()})
+		}
+	}
+}
+
+// append chains the record with the previous one, signs it and writes it in
+// the file, it returns the line written.
+func (l *Log) append(r *Record) ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r.Sequence = l.sequence + 1
+	r.PreviousHash = l.lastHash
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(b)
+	e := Entry{Record: b, Hash: hex.EncodeToString(sum[:])}
+	if l.key != nil {
+		e.Signature = hex.EncodeToString(ed25519.Sign(l.key, sum[:]))
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	line = append(line, '\n')
+
+	if l.file != nil {
+		if _, err := l.file.Write(line); err != nil {
+			return nil, err
+		}
+		// the record is written, the chain continues even if it's not synced
+		if err := l.file.Sync(); err != nil {
+			utils.PrintLog(utils.ErrorStr, utils.LogLine{Message: auditStr, Error: err.Error()})
+		}
+	}
+	l.sequence, l.lastHash = r.Sequence, e.Hash
+	return line, nil
+}
+
+// readLastEntry returns the last entry of the file, nil if it's empty.
+func readLastEntry(f *os.File) (*Entry, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	var last []byte
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) != 0 {
+			last = line
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if last == nil {
+		return nil, nil
+	}
+	e := new(Entry)
+	if err := json.Unmarshal(last, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// ReadPrivateKey reads an Ed25519 private key from a PEM file (PKCS #8).
+func ReadPrivateKey(file string) (ed25519.PrivateKey, error) {
+	b, err := os.ReadFile(file) // #nosec G304 -- the file path comes from the operator configuration
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in '%v'", file)
+	}
+	k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := k.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("the key of '%v' is not an Ed25519 key", file)
+	}
+	return key, nil
+}
+
+// ReadPublicKey reads an Ed25519 public key from a PEM file (PKIX), or the
+// public part of a private key (PKCS #8).
+func ReadPublicKey(file string) (ed25519.PublicKey, error) {
+	b, err := os.ReadFile(file) // #nosec G304 -- the file path comes from the operator
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data in '%v'", file)
+	}
+	if k, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		if key, ok := k.(ed25519.PublicKey); ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("the key of '%v' is not an Ed25519 key", file)
+	}
+	key, err := ReadPrivateKey(file)
+	if err != nil {
+		return nil, err
+	}
+	return key.Public().(ed25519.PublicKey), nil
+}
+
+// VerifyResult is the result of the verification of an audit log.
+type VerifyResult struct {
+	Records int `json:"records"`
+	Signed  int `json:"signed"`
+	// First is the sequence of the first record, greater than 1 if the
+	// beginning of the chain is missing.
+	First uint64 `json:"first"`
+	Last  uint64 `json:"last"`
+}
+
+// Verify checks the chain of the records read from r: the hash of each record,
+// its link to the previous one and the sequence. The signatures are checked
+// if a key is given, all the records must be signed then. The error mentions
+// the line of the first broken record.
+func Verify(r io.Reader, key ed25519.PublicKey) (VerifyResult, error) {
+	var result VerifyResult
+	var previous *Record
+	var previousHash string
+	reader := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return result, err
+		}
+		if len(bytes.TrimSpace(line)) != 0 {
+			e, record, err := verifyEntry(line, key)
+			if err != nil {
+				return result, fmt.Errorf("line %v: %v", n, err)
+			}
+			if previous == nil {
+				result.First = record.Sequence
+			} else {
+				if record.PreviousHash != previousHash {
+					return result, fmt.Errorf("line %v: the previous hash doesn't match, a record has been removed or inserted", n)
+				}
+				if record.Sequence != previous.Sequence+1 {
+					return result, fmt.Errorf("line %v: expected the sequence %v, got %v", n, previous.Sequence+1, record.Sequence)
+				}
+			}
+			previous, previousHash = record, e.Hash
+			result.Records++
+			result.Last = record.Sequence
+			if e.Signature != "" {
+				result.Signed++
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+	}
+}
+
+// verifyEntry checks the hash of the record of the entry, and its signature
+// if a key is given.
+func verifyEntry(line []byte, key ed25519.PublicKey) (*Entry, *Record, error) {
+	e := new(Entry)
+	if err := json.Unmarshal(line, e); err != nil {
+		return nil, nil, fmt.Errorf("malformed entry: %v", err)
+	}
+	sum := sha256.Sum256(e.Record)
+	if hex.EncodeToString(sum[:]) != e.Hash {
+		return nil, nil, errors.New("the hash doesn't match the record, the record has been modified")
+	}
+	r := new(Record)
+	if err := json.Unmarshal(e.Record, r); err != nil {
+		return nil, nil, fmt.Errorf("malformed record: %v", err)
+	}
+	if key != nil {
+		sig, err := hex.DecodeString(e.Signature)
+		if err != nil || e.Signature == "" {
+			return nil, nil, errors.New("the record is not signed")
+		}
+		if !ed25519.Verify(key, sum[:], sig) {
+			return nil, nil, errors.New("wrong signature")
+		}
+	}
+	return e, r, nil
+}
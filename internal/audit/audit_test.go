@@ -0,0 +1,107 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+)
+
+func writeRecords(t *testing.T, config configuration.Audit, n int) {
+	t.Helper()
+	l, err := NewLog(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer l.Close()
+	for i := 0; i < n; i++ {
+		if _, err := l.append(&Record{StartedAt: time.Now(), Kind: ActionStr, Rule: "rule", Action: "action", Status: "success"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestVerifyDetectsTheTampering(t *testing.T) {
+	dir := t.TempDir()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config := configuration.Audit{File: filepath.Join(dir, "audit.log"), SigningKeyFile: keyFile}
+
+	// the second log continues the chain of the first one
+	writeRecords(t, config, 2)
+	writeRecords(t, config, 1)
+
+	b, err := os.ReadFile(config.File)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := Verify(bytes.NewReader(b), pub)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Records != 3 || result.Signed != 3 || result.First != 1 || result.Last != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	if k, err := ReadPublicKey(keyFile); err != nil || !k.Equal(pub) {
+		t.Fatalf("expected the public key to be derived from the private key, got %v", err)
+	}
+
+	lines := strings.SplitAfter(string(b), "\n")
+	other, _, _ := ed25519.GenerateKey(nil)
+	tests := map[string]struct {
+		log string
+		key ed25519.PublicKey
+		err string
+	}{
+		"modified record": {
+			log: lines[0] + strings.Replace(lines[1], "success", "failure", 1) + lines[2],
+			key: pub,
+			err: "line 2: the hash doesn't match",
+		},
+		"removed record": {
+			log: lines[0] + lines[2],
+			key: pub,
+			err: "line 2: the previous hash doesn't match",
+		},
+		"wrong key": {
+			log: string(b),
+			key: other,
+			err: "line 1: wrong signature",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Verify(strings.NewReader(tt.log), tt.key)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected the error '%v', got %v", tt.err, err)
+			}
+		})
+	}
+
+	// a chain starting after the first record is valid
+	result, err = Verify(strings.NewReader(lines[1]+lines[2]), pub)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.First != 2 || result.Records != 2 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
@@ -2,7 +2,6 @@ package client
 
 import (
 	"context"
-	"sync"
 
 	"github.com/falcosecurity/falco-talon/configuration"
 	"github.com/falcosecurity/falco-talon/utils"
@@ -24,70 +23,59 @@ type AWSClient struct {
 	cfg          aws.Config
 }
 
-var (
-	awsClient *AWSClient
-	once      sync.Once
-)
-
-func Init() error {
-	if awsClient != nil {
-		return nil
-	}
+var clients utils.Clients[configuration.AwsConfig, AWSClient]
 
-	var initErr error
-
-	once.Do(func() {
-		awsConfig := configuration.GetConfiguration().AwsConfig
-		var cfg aws.Config
-		var err error
-
-		if awsConfig.AccessKey != "" && awsConfig.SecretKey != "" && awsConfig.Region != "" {
-			cfg, err = config.LoadDefaultConfig(
-				context.TODO(),
-				config.WithRegion(awsConfig.Region),
-				config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(awsConfig.AccessKey, awsConfig.SecretKey, "")),
-			)
-		} else {
-			cfg, err = config.LoadDefaultConfig(context.TODO())
-		}
-		if err != nil {
-			initErr = err
-			return
-		}
+// Init creates the client of the configuration, it's used once the
+// configuration is the current one, to take the reloads into account.
+func Init(awsConfig configuration.AwsConfig) error {
+	return clients.Init(awsConfig, configuration.GetConfiguration().AwsConfig, newClient)
+}
 
-		if awsConfig.RoleArn != "" {
-			stsClient := sts.NewFromConfig(cfg)
-			assumeRoleOptions := func(o *stscreds.AssumeRoleOptions) {
-				if awsConfig.ExternalID != "" {
-					o.ExternalID = aws.String(awsConfig.ExternalID)
-				}
-			}
-			provider := stscreds.NewAssumeRoleProvider(stsClient, awsConfig.RoleArn, assumeRoleOptions)
-			cfg.Credentials = aws.NewCredentialsCache(provider)
-		}
+func newClient(awsConfig configuration.AwsConfig) (*AWSClient, error) {
+	var cfg aws.Config
+	var err error
+
+	if awsConfig.AccessKey != "" && awsConfig.SecretKey != "" && awsConfig.Region != "" {
+		cfg, err = config.LoadDefaultConfig(
+			context.TODO(),
+			config.WithRegion(awsConfig.Region),
+			config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(awsConfig.AccessKey, awsConfig.SecretKey, "")),
+		)
+	} else {
+		cfg, err = config.LoadDefaultConfig(context.TODO())
+	}
+	if err != nil {
+		return nil, err
+	}
 
-		// Perform a dry run to validate credentials
+	if awsConfig.RoleArn != "" {
 		stsClient := sts.NewFromConfig(cfg)
-		_, err = stsClient.GetCallerIdentity(context.TODO(), &sts.GetCallerIdentityInput{})
-		if err != nil {
-			initErr = err
-			return
+		assumeRoleOptions := func(o *stscreds.AssumeRoleOptions) {
+			if awsConfig.ExternalID != "" {
+				o.ExternalID = aws.String(awsConfig.ExternalID)
+			}
 		}
+		provider := stscreds.NewAssumeRoleProvider(stsClient, awsConfig.RoleArn, assumeRoleOptions)
+		cfg.Credentials = aws.NewCredentialsCache(provider)
+	}
 
-		awsClient = &AWSClient{
-			cfg: cfg,
-		}
+	// Perform a dry run to validate credentials
+	stsClient := sts.NewFromConfig(cfg)
+	_, err = stsClient.GetCallerIdentity(context.TODO(), &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, err
+	}
 
-		if initErr == nil {
-			utils.PrintLog(utils.InfoStr, utils.LogLine{Message: "init", Category: "aws", Status: utils.SuccessStr})
-		}
-	})
+	utils.PrintLog(utils.InfoStr, utils.LogLine{Message: "init", Category: "aws", Status: utils.SuccessStr})
 
-	return initErr
+	return &AWSClient{
+		cfg: cfg,
+	}, nil
 }
 
+// GetAWSClient returns the client of the current configuration.
 func GetAWSClient() *AWSClient {
-	return awsClient
+	return clients.Get(configuration.GetConfiguration().AwsConfig)
 }
 
 func GetLambdaClient() *lambda.Client {
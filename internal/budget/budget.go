@@ -0,0 +1,389 @@
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/events"
+	k8s "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
+	"github.com/falcosecurity/falco-talon/internal/rules"
+)
+
+const (
+	ClusterStr   string = "cluster"
+	NamespaceStr string = "namespace"
+
+	defaultWindow = 1 * time.Hour
+)
+
+// budget is a validated configuration.Budget.
+type budget struct {
+	actionners map[string]bool
+	name       string
+	scope      string
+	window     time.Duration
+	max        int
+	// maxInFlight is 0 for no limit
+	maxInFlight int
+}
+
+// Remaining is the number of actions still allowed by a budget for a scope.
+type Remaining struct {
+	Budget string
+	// Cluster is empty for the local cluster
+	Cluster   string
+	Scope     string
+	Remaining int
+}
+
+var (
+	budgets Budgets
+	// usages are the times of the actions run, by budget, cluster and scope,
+	// as 'budget/cluster/namespace'. They are kept by name across the
+	// reloads, to not reset the budgets.
+	usages = map[string][]time.Time{}
+	// inFlight are the numbers of actions running, by the same keys, the
+	// keys without actions running are removed.
+	inFlight = map[string]int{}
+	// cordoned are the nodes left unschedulable by the actions ended, by the
+	// same keys, they stay in flight until they're schedulable again.
+	cordoned = map[string]map[string]bool{}
+	mu       sync.Mutex
+	now      = time.Now
+	// schedulable returns true if the node of the cluster is schedulable, or
+	// doesn't exist anymore.
+	schedulable = func(cluster, node string) bool {
+		client := k8s.GetClusterClient(cluster)
+		if client == nil {
+			return true
+		}
+		n, err := client.Clientset.CoreV1().Nodes().Get(context.Background(), node, metav1.GetOptions{})
+		if err != nil {
+			return apierrors.IsNotFound(err)
+		}
+		return !n.Spec.Unschedulable
+	}
+	// nodeOf returns the name of the node of the pod of the event.
+	nodeOf = func(event *events.Event) string {
+		client := k8s.GetClientFor(event)
+		if client == nil {
+			return ""
+		}
+		pod, err := client.GetPod(event.GetPodName(), event.GetNamespaceName())
+		if err != nil {
+			return ""
+		}
+		return pod.Spec.NodeName
+	}
+)
+
+// cordoners are the actionners leaving the node of the pod unschedulable.
+var cordoners = []string{"kubernetes:cordon", "kubernetes:drain"}
+
+// Usage is the action taken from the budgets by Consume, it must be ended
+// once the action has run.
+type Usage struct {
+	keys []string
+	t    time.Time
+	// node is the node cordoned by the action, empty for the other actions
+	node string
+}
+
+// Budgets are the validated budgets of a configuration.
+type Budgets []*budget
+
+// Store replaces the budgets in use, the usages of the budgets removed are
+// forgotten.
+func Store(l Budgets) {
+	mu.Lock()
+	defer mu.Unlock()
+	budgets = l
+	for key := range usages {
+		name, _, _ := strings.Cut(key, "/")
+		if !slices.ContainsFunc(l, func(b *budget) bool { return b.name == name }) {
+			delete(usages, key)
+		}
+	}
+	for key := range cordoned {
+		name, _, _ := strings.Cut(key, "/")
+		if !slices.ContainsFunc(l, func(b *budget) bool { return b.name == name && b.maxInFlight != 0 }) {
+			delete(cordoned, key)
+		}
+	}
+}
+
+// New validates the budgets of the configuration.
+func New(config []configuration.Budget) (Budgets, error) {
+	l := make(Budgets, 0, len(config))
+	names := map[string]bool{}
+	for _, i := range config {
+		if i.Name == "" || strings.Contains(i.Name, "/") {
+			return nil, errors.New("a budget must have a name, without '/'")
+		}
+		if names[i.Name] {
+			return nil, fmt.Errorf("the budget '%v' is declared twice", i.Name)
+		}
+		names[i.Name] = true
+		if len(i.Actionners) == 0 {
+			return nil, fmt.Errorf("the budget '%v' has no actionners", i.Name)
+		}
+		if i.Max <= 0 {
+			return nil, fmt.Errorf("the max of the budget '%v' must be greater than 0", i.Name)
+		}
+		if i.MaxInFlight < 0 {
+			return nil, fmt.Errorf("the max in flight of the budget '%v' can't be negative", i.Name)
+		}
+		b := &budget{
+			actionners:  map[string]bool{},
+			name:        i.Name,
+			scope:       i.Scope,
+			window:      defaultWindow,
+			max:         i.Max,
+			maxInFlight: i.MaxInFlight,
+		}
+		switch b.scope {
+		case "":
+			b.scope = ClusterStr
+		case ClusterStr, NamespaceStr:
+		default:
+			return nil, fmt.Errorf("the scope of the budget '%v' can be '%v' or '%v' only", i.Name, ClusterStr, NamespaceStr)
+		}
+		if i.Window != "" {
+			d, err := time.ParseDuration(i.Window)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("wrong window '%v' for the budget '%v'", i.Window, i.Name)
+			}
+			b.window = d
+		}
+		for _, j := range i.Actionners {
+			b.actionners[j] = true
+		}
+		l = append(l, b)
+	}
+	return l, nil
+}
+
+// matches returns true if the budget applies to the actionner, its full name
+// or its category.
+func (b *budget) matches(action *rules.Action) bool {
+	return b.actionners[action.GetActionner()] || b.actionners[action.GetActionnerCategory()]
+}
+
+// key returns the key of the usages of the event, each cluster has its own
+// budgets.
+func (b *budget) key(event *events.Event) string {
+	key := b.name + "/" + k8s.ClusterOf(event) + "/"
+	if b.scope == NamespaceStr {
+		key += event.GetNamespaceName()
+	}
+	return key
+}
+
+// Consume takes one action from all the budgets of the actionner, it returns
+// the reason of the refusal if one of them is exhausted, nothing is taken
+// then. The action is in flight until the usage is ended, and a cordon or a
+// drain until its node is schedulable again.
+func Consume(event *events.Event, action *rules.Action) (*Usage, string) {
+	node := uncordon(event, action)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	t := now()
+	keys, reason := available(event, action, node, t)
+	if reason != "" {
+		return nil, reason
+	}
+	for _, i := range keys {
+		usages[i] = append(usages[i], t)
+		inFlight[i]++
+	}
+	return &Usage{keys: keys, t: t, node: node}, ""
+}
+
+// uncordon releases the nodes of the budgets in flight of the action which are
+// schedulable again, it returns the node of the event if the action cordons
+// it. The nodes are checked without the lock, they're API calls.
+func uncordon(event *events.Event, action *rules.Action) string {
+	mu.Lock()
+	var keys []string
+	for _, b := range budgets {
+		if b.maxInFlight != 0 && b.matches(action) {
+			keys = append(keys, b.key(event))
+		}
+	}
+	nodes := map[string]bool{}
+	for _, key := range keys {
+		for i := range cordoned[key] {
+			nodes[i] = true
+		}
+	}
+	mu.Unlock()
+
+	if len(keys) == 0 {
+		return ""
+	}
+	cluster := k8s.ClusterOf(event)
+	for i := range nodes {
+		nodes[i] = schedulable(cluster, i)
+	}
+
+	mu.Lock()
+	for _, key := range keys {
+		for i := range cordoned[key] {
+			if nodes[i] {
+				delete(cordoned[key], i)
+			}
+		}
+		if len(cordoned[key]) == 0 {
+			delete(cordoned, key)
+		}
+	}
+	mu.Unlock()
+
+	if !slices.Contains(cordoners, action.GetActionner()) {
+		return ""
+	}
+	return nodeOf(event)
+}
+
+// End releases the action in flight, and gives it back to the budgets if it
+// failed. The node of a cordon or a drain which succeeded stays in flight
+// until it's schedulable again. It does nothing for a nil usage.
+func (u *Usage) End(failed bool) {
+	if u == nil {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, i := range u.keys {
+		if inFlight[i]--; inFlight[i] <= 0 {
+			delete(inFlight, i)
+		}
+		if !failed {
+			if u.node != "" {
+				if cordoned[i] == nil {
+					cordoned[i] = map[string]bool{}
+				}
+				cordoned[i][u.node] = true
+			}
+			continue
+		}
+		if n := slices.Index(usages[i], u.t); n != -1 {
+			usages[i] = slices.Delete(usages[i], n, n+1)
+		}
+	}
+}
+
+// Check returns the reason of the refusal of the action if one of its budgets
+// is exhausted, like Consume, but nothing is taken. It's used for the dry
+// runs.
+func Check(event *events.Event, action *rules.Action) string {
+	node := uncordon(event, action)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	_, reason := available(event, action, node, now())
+	return reason
+}
+
+// available returns the keys of the usages of the budgets of the action, or
+// the reason of the refusal if one of them is exhausted at t. A node already
+// cordoned is not counted twice.
+func available(event *events.Event, action *rules.Action, node string, t time.Time) ([]string, string) {
+	var keys []string
+	for _, b := range budgets {
+		if !b.matches(action) {
+			continue
+		}
+		key := b.key(event)
+		usages[key] = prune(usages[key], t.Add(-b.window))
+		exhausted := len(usages[key]) >= b.max
+		n := inFlight[key] + len(cordoned[key])
+		if cordoned[key][node] {
+			n--
+		}
+		running := b.maxInFlight != 0 && n >= b.maxInFlight
+		if !exhausted && !running {
+			keys = append(keys, key)
+			continue
+		}
+		scope := "the cluster"
+		if cluster := k8s.ClusterOf(event); cluster != "" {
+			scope = fmt.Sprintf("the cluster '%v'", cluster)
+		}
+		if b.scope == NamespaceStr {
+			scope = fmt.Sprintf("the namespace '%v' of %v", event.GetNamespaceName(), scope)
+		}
+		if exhausted {
+			return nil, fmt.Sprintf("the budget '%v' of %v action(s) per %v for %v is exhausted", b.name, b.max, b.window, scope)
+		}
+		return nil, fmt.Sprintf("the budget '%v' of %v action(s) at once for %v is exhausted", b.name, b.maxInFlight, scope)
+	}
+	return keys, ""
+}
+
+// ListRemaining returns the remaining budgets, of the local cluster for the
+// ones scoped by cluster, and per cluster and namespace for the ones with
+// actions in their window.
+func ListRemaining() []Remaining {
+	mu.Lock()
+	defer mu.Unlock()
+
+	t := now()
+	var l []Remaining
+	for _, b := range budgets {
+		local := false
+		for key := range usages {
+			scope, found := strings.CutPrefix(key, b.name+"/")
+			if !found {
+				continue
+			}
+			usages[key] = prune(usages[key], t.Add(-b.window))
+			if len(usages[key]) == 0 {
+				delete(usages, key)
+				continue
+			}
+			cluster, namespace, _ := strings.Cut(scope, "/")
+			if b.scope == ClusterStr {
+				namespace = ClusterStr
+				local = local || cluster == ""
+			}
+			l = append(l, Remaining{Budget: b.name, Cluster: cluster, Scope: namespace, Remaining: max(b.max-len(usages[key]), 0)})
+		}
+		if b.scope == ClusterStr && !local {
+			l = append(l, Remaining{Budget: b.name, Scope: ClusterStr, Remaining: b.max})
+		}
+	}
+	sort.Slice(l, func(i, j int) bool {
+		if l[i].Budget != l[j].Budget {
+			return l[i].Budget < l[j].Budget
+		}
+		if l[i].Cluster != l[j].Cluster {
+			return l[i].Cluster < l[j].Cluster
+		}
+		return l[i].Scope < l[j].Scope
+	})
+	return l
+}
+
+// prune removes the times before the start of the window.
+func prune(l []time.Time, start time.Time) []time.Time {
+	n := 0
+	for n < len(l) && !l[n].After(start) {
+		n++
+	}
+	return l[n:]
+}
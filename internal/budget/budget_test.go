@@ -0,0 +1,213 @@
+package budget
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/events"
+	"github.com/falcosecurity/falco-talon/internal/rules"
+)
+
+func setBudgets(t *testing.T, config ...configuration.Budget) {
+	t.Helper()
+	l, err := New(config)
+	if err != nil {
+		t.Fatalf("new budgets: %v", err)
+	}
+	mu.Lock()
+	budgets = l
+	usages = map[string][]time.Time{}
+	inFlight = map[string]int{}
+	cordoned = map[string]map[string]bool{}
+	mu.Unlock()
+}
+
+func TestConsumeRefusesTheActionsBeyondTheBudget(t *testing.T) {
+	clock := time.Now()
+	now = func() time.Time { return clock }
+	t.Cleanup(func() { now = time.Now })
+
+	setBudgets(t,
+		configuration.Budget{Name: "pods", Actionners: []string{"kubernetes:terminate"}, Scope: NamespaceStr, Max: 2, Window: "1h"},
+		configuration.Budget{Name: "nodes", Actionners: []string{"kubernetes:cordon", "kubernetes:drain"}, Max: 1},
+	)
+
+	terminate := &rules.Action{Actionner: "kubernetes:terminate"}
+	drain := &rules.Action{Actionner: "kubernetes:drain"}
+	cordon := &rules.Action{Actionner: "kubernetes:cordon"}
+	inNamespace := func(namespace string) *events.Event {
+		return &events.Event{OutputFields: map[string]any{"k8s.ns.name": namespace}}
+	}
+
+	for range 2 {
+		if _, reason := Consume(inNamespace("default"), terminate); reason != "" {
+			t.Fatalf("expected the action to be allowed, got '%v'", reason)
+		}
+	}
+	if _, reason := Consume(inNamespace("default"), terminate); !strings.Contains(reason, "namespace 'default'") {
+		t.Fatalf("expected the budget of the namespace to be exhausted, got '%v'", reason)
+	}
+	if _, reason := Consume(inNamespace("prod"), terminate); reason != "" {
+		t.Fatalf("expected the budget of another namespace to be available, got '%v'", reason)
+	}
+
+	if _, reason := Consume(inNamespace("default"), drain); reason != "" {
+		t.Fatalf("expected the drain to be allowed, got '%v'", reason)
+	}
+	if _, reason := Consume(inNamespace("prod"), cordon); !strings.Contains(reason, "'nodes'") {
+		t.Fatalf("expected the budget shared by the cordons and drains to be exhausted, got '%v'", reason)
+	}
+
+	remaining := ListRemaining()
+	if len(remaining) != 3 || remaining[0] != (Remaining{Budget: "nodes", Scope: ClusterStr}) ||
+		remaining[1] != (Remaining{Budget: "pods", Scope: "default"}) ||
+		remaining[2] != (Remaining{Budget: "pods", Scope: "prod", Remaining: 1}) {
+		t.Fatalf("unexpected remaining budgets %+v", remaining)
+	}
+
+	clock = clock.Add(time.Hour + time.Second)
+	if _, reason := Consume(inNamespace("default"), terminate); reason != "" {
+		t.Fatalf("expected the budget to be available after the window, got '%v'", reason)
+	}
+	if _, reason := Consume(inNamespace("default"), &rules.Action{Actionner: "aws:lambda"}); reason != "" {
+		t.Fatalf("expected no budget for the other actionners, got '%v'", reason)
+	}
+}
+
+func TestUsageEndReleasesAndRefundsTheActions(t *testing.T) {
+	setBudgets(t, configuration.Budget{Name: "nodes", Actionners: []string{"kubernetes:cordon", "kubernetes:drain"}, Max: 3, MaxInFlight: 1})
+
+	drain := &rules.Action{Actionner: "kubernetes:drain"}
+	cordon := &rules.Action{Actionner: "kubernetes:cordon"}
+
+	usage, reason := Consume(&events.Event{}, drain)
+	if reason != "" {
+		t.Fatalf("expected the drain to be allowed, got '%v'", reason)
+	}
+	if _, reason := Consume(&events.Event{}, cordon); !strings.Contains(reason, "1 action(s) at once") {
+		t.Fatalf("expected the budget to be exhausted while the drain runs, got '%v'", reason)
+	}
+	usage.End(false)
+	usage, reason = Consume(&events.Event{}, cordon)
+	if reason != "" {
+		t.Fatalf("expected the cordon to be allowed once the drain ended, got '%v'", reason)
+	}
+
+	// a failed action is given back
+	usage.End(true)
+	if remaining := ListRemaining(); len(remaining) != 1 || remaining[0].Remaining != 2 {
+		t.Fatalf("expected the failed cordon to be refunded, got %+v", remaining)
+	}
+	if len(inFlight) != 0 {
+		t.Fatalf("expected no action in flight, got %v", inFlight)
+	}
+	(*Usage)(nil).End(true)
+}
+
+func TestConsumeCountsTheNodesStillCordoned(t *testing.T) {
+	unschedulable := map[string]bool{}
+	defaultSchedulable, defaultNodeOf := schedulable, nodeOf
+	schedulable = func(_, node string) bool { return !unschedulable[node] }
+	nodeOf = func(event *events.Event) string { return "node-" + event.GetPodName() }
+	t.Cleanup(func() { schedulable, nodeOf = defaultSchedulable, defaultNodeOf })
+	setBudgets(t, configuration.Budget{Name: "nodes", Actionners: []string{"kubernetes:cordon", "kubernetes:drain"}, Max: 10, MaxInFlight: 1})
+
+	cordon := &rules.Action{Actionner: "kubernetes:cordon"}
+	drain := &rules.Action{Actionner: "kubernetes:drain"}
+	onPod := func(pod string) *events.Event {
+		return &events.Event{OutputFields: map[string]any{"k8s.pod.name": pod}}
+	}
+
+	usage, reason := Consume(onPod("a"), cordon)
+	if reason != "" {
+		t.Fatalf("expected the cordon to be allowed, got '%v'", reason)
+	}
+	// the cordon has completed, its node is still unschedulable
+	unschedulable["node-a"] = true
+	usage.End(false)
+	if _, reason := Consume(onPod("b"), cordon); !strings.Contains(reason, "1 action(s) at once") {
+		t.Fatalf("expected the budget to be exhausted while the node is cordoned, got '%v'", reason)
+	}
+	if reason := Check(onPod("b"), drain); !strings.Contains(reason, "1 action(s) at once") {
+		t.Fatalf("expected the dry runs to be throttled too, got '%v'", reason)
+	}
+
+	usage, reason = Consume(onPod("a"), drain)
+	if reason != "" {
+		t.Fatalf("expected the drain of the node already cordoned to be allowed, got '%v'", reason)
+	}
+	usage.End(false)
+
+	unschedulable["node-a"] = false
+	if _, reason := Consume(onPod("b"), cordon); reason != "" {
+		t.Fatalf("expected the cordon to be allowed once the node is uncordoned, got '%v'", reason)
+	}
+}
+
+func TestNewBudgetsChecksTheConfiguration(t *testing.T) {
+	t.Parallel()
+
+	for _, i := range []configuration.Budget{
+		{Actionners: []string{"kubernetes"}, Max: 1},
+		{Name: "pods", Max: 1},
+		{Name: "pods", Actionners: []string{"kubernetes"}},
+		{Name: "pods", Actionners: []string{"kubernetes"}, Max: 1, Scope: "node"},
+		{Name: "pods", Actionners: []string{"kubernetes"}, Max: 1, Window: "soon"},
+		{Name: "pods", Actionners: []string{"kubernetes"}, Max: 1, MaxInFlight: -1},
+	} {
+		if _, err := New([]configuration.Budget{i}); err == nil {
+			t.Errorf("expected an error for %+v", i)
+		}
+	}
+	if _, err := New([]configuration.Budget{
+		{Name: "pods", Actionners: []string{"kubernetes"}, Max: 1},
+		{Name: "pods", Actionners: []string{"calico"}, Max: 1},
+	}); err == nil {
+		t.Error("expected an error for the duplicated names")
+	}
+}
+
+func TestConsumeCountsTheClustersApart(t *testing.T) {
+	configuration.CreateConfiguration("")
+	configuration.GetConfiguration().Clusters = configuration.Clusters{
+		Field:       "k8s.cluster.name",
+		Credentials: map[string]configuration.ClusterCredentials{"prod-eu": {}, "prod-us": {}},
+	}
+	t.Cleanup(func() {
+		configuration.GetConfiguration().Clusters = configuration.Clusters{}
+	})
+	setBudgets(t,
+		configuration.Budget{Name: "nodes", Actionners: []string{"kubernetes:drain"}, Max: 1},
+		configuration.Budget{Name: "pods", Actionners: []string{"kubernetes:terminate"}, Scope: NamespaceStr, Max: 1},
+	)
+
+	drain := &rules.Action{Actionner: "kubernetes:drain"}
+	terminate := &rules.Action{Actionner: "kubernetes:terminate"}
+	inCluster := func(cluster string) *events.Event {
+		return &events.Event{OutputFields: map[string]any{"k8s.cluster.name": cluster, "k8s.ns.name": "default"}}
+	}
+
+	for _, i := range []string{"prod-eu", "prod-us"} {
+		if _, reason := Consume(inCluster(i), drain); reason != "" {
+			t.Fatalf("%v: expected the drain to be allowed, got '%v'", i, reason)
+		}
+		if _, reason := Consume(inCluster(i), terminate); reason != "" {
+			t.Fatalf("%v: expected the termination to be allowed, got '%v'", i, reason)
+		}
+	}
+	if _, reason := Consume(inCluster("prod-eu"), drain); !strings.Contains(reason, "the cluster 'prod-eu'") {
+		t.Fatalf("expected the budget of the cluster to be exhausted, got '%v'", reason)
+	}
+	if _, reason := Consume(inCluster("prod-eu"), terminate); !strings.Contains(reason, "the namespace 'default' of the cluster 'prod-eu'") {
+		t.Fatalf("expected the budget of the namespace of the cluster to be exhausted, got '%v'", reason)
+	}
+
+	remaining := ListRemaining()
+	if len(remaining) != 5 || remaining[0] != (Remaining{Budget: "nodes", Scope: ClusterStr, Remaining: 1}) ||
+		remaining[1] != (Remaining{Budget: "nodes", Cluster: "prod-eu", Scope: ClusterStr}) ||
+		remaining[4] != (Remaining{Budget: "pods", Cluster: "prod-us", Scope: "default"}) {
+		t.Fatalf("unexpected remaining budgets %+v", remaining)
+	}
+}
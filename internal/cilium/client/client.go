@@ -1,11 +1,12 @@
 package kubernetes
 
 import (
+	"errors"
+	"fmt"
+
 	cilium "github.com/cilium/cilium/pkg/k8s/client/clientset/versioned"
-	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 
-	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/events"
 	kubernetes "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
 )
 
@@ -13,35 +14,55 @@ type Client struct {
 	*cilium.Clientset
 }
 
-var client *Client
+var (
+	client *Client
+	// clients are the clients of the clusters, by name, empty for the local
+	// cluster
+	clients map[string]*Client
+)
 
+// Init creates the clients of the local cluster and of the remote ones, once,
+// the clusters can't change without a restart.
 func Init() error {
-	// the calico category requires also a k8s client
-	if err := kubernetes.Init(); err != nil {
-		return err
-	}
-
-	client = new(Client)
-	config := configuration.GetConfiguration()
-	var err error
-	var restConfig *rest.Config
-	if config.KubeConfig != "" {
-		restConfig, err = clientcmd.BuildConfigFromFlags("", config.KubeConfig)
-	} else {
-		restConfig, err = rest.InClusterConfig()
+	if client != nil {
+		return nil
 	}
-	if err != nil {
+	// the cilium category requires also a k8s client
+	if err := kubernetes.Init(); err != nil {
 		return err
 	}
 
-	// creates the clientset
-	client.Clientset, err = cilium.NewForConfig(restConfig)
-	if err != nil {
-		return err
+	c := make(map[string]*Client)
+	for _, i := range append([]string{""}, kubernetes.Clusters()...) {
+		k := kubernetes.GetClusterClient(i)
+		if k == nil {
+			return errors.New("wrong k8s client")
+		}
+		// creates the clientset with the rest config of the k8s client of the
+		// cluster
+		clientset, err := cilium.NewForConfig(k.RestConfig)
+		if err != nil {
+			return err
+		}
+		c[i] = &Client{Clientset: clientset}
 	}
+	client, clients = c[""], c
 	return nil
 }
 
 func GetClient() *Client {
 	return client
 }
+
+// GetClientFor returns the client of the cluster of the event, an error if its
+// cluster has no credentials.
+func GetClientFor(event *events.Event) (*Client, error) {
+	cluster := kubernetes.ClusterOf(event)
+	if c := clients[cluster]; c != nil {
+		return c, nil
+	}
+	if cluster != "" {
+		return nil, fmt.Errorf("no credentials for the cluster '%v'", cluster)
+	}
+	return nil, errors.New("wrong cilium client")
+}
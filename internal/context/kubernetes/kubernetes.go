@@ -1,6 +1,8 @@
 package kubernetes
 
 import (
+	"fmt"
+
 	"github.com/falcosecurity/falco-talon/internal/events"
 	kubernetes "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
 )
@@ -9,7 +11,10 @@ func GetNodeContext(event *events.Event) (map[string]any, error) {
 	podName := event.GetPodName()
 	namespace := event.GetNamespaceName()
 
-	client := kubernetes.GetClient()
+	client := kubernetes.GetClientFor(event)
+	if client == nil {
+		return nil, fmt.Errorf("no credentials for the cluster '%v'", kubernetes.ClusterOf(event))
+	}
 	pod, err := client.GetPod(podName, namespace)
 	if err != nil {
 		return nil, err
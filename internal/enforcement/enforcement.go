@@ -0,0 +1,101 @@
+package enforcement
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/rules"
+)
+
+const (
+	// EnforceStr runs the actions.
+	EnforceStr string = "enforce"
+	// DryRunStr plans the actions, their changes are reported and not made.
+	DryRunStr string = "dry-run"
+	// ObserveStr only reports the actions which would be run, their targets
+	// are not resolved.
+	ObserveStr string = "observe"
+
+	trueStr string = "true"
+)
+
+// Modes are the modes, from the least to the most restrictive.
+var Modes = []string{EnforceStr, DryRunStr, ObserveStr}
+
+// Policy is the enforcement mode of the actions, global and overridden by
+// category of actionners or by actionner.
+type Policy struct {
+	categories map[string]string
+	actionners map[string]string
+	mode       string
+}
+
+var policy atomic.Pointer[Policy]
+
+func init() {
+	policy.Store(&Policy{mode: EnforceStr})
+}
+
+// Store makes the policy the one in use.
+func Store(p *Policy) {
+	policy.Store(p)
+}
+
+func GetPolicy() *Policy {
+	return policy.Load()
+}
+
+func NewPolicy(c configuration.Enforcement) (*Policy, error) {
+	p := &Policy{
+		mode:       c.Mode,
+		categories: map[string]string{},
+		actionners: map[string]string{},
+	}
+	if p.mode == "" {
+		p.mode = EnforceStr
+	}
+	if !slices.Contains(Modes, p.mode) {
+		return nil, fmt.Errorf("wrong enforcement mode '%v', it can be %v", p.mode, Modes)
+	}
+	for category, mode := range c.Categories {
+		if !slices.Contains(Modes, mode) {
+			return nil, fmt.Errorf("wrong enforcement mode '%v' for the category '%v', it can be %v", mode, category, Modes)
+		}
+		p.categories[category] = mode
+	}
+	for actionner, mode := range c.Actionners {
+		if !slices.Contains(Modes, mode) {
+			return nil, fmt.Errorf("wrong enforcement mode '%v' for the actionner '%v', it can be %v", mode, actionner, Modes)
+		}
+		if category, name, ok := strings.Cut(actionner, ":"); !ok || category == "" || name == "" {
+			return nil, fmt.Errorf("wrong actionner '%v' for the enforcement, it must be 'category:name'", actionner)
+		}
+		p.actionners[actionner] = mode
+	}
+	return p, nil
+}
+
+// Mode returns the effective mode of the action of the rule. The mode of its
+// actionner overrides the one of its category, which overrides the global
+// one, and a rule in dry run is never enforced.
+func Mode(rule *rules.Rule, action *rules.Action) string {
+	mode := GetPolicy().Mode(action.GetActionner(), action.GetActionnerCategory())
+	if rule.DryRun == trueStr && mode == EnforceStr {
+		return DryRunStr
+	}
+	return mode
+}
+
+// Mode returns the mode of the actionner.
+func (p *Policy) Mode(actionner, category string) string {
+	if mode, ok := p.actionners[actionner]; ok {
+		return mode
+	}
+	if mode, ok := p.categories[category]; ok {
+		return mode
+	}
+	return p.mode
+}
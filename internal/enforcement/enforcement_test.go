@@ -0,0 +1,53 @@
+package enforcement
+
+import (
+	"testing"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/rules"
+)
+
+func TestNewPolicyRejectsTheWrongModes(t *testing.T) {
+	cases := map[string]configuration.Enforcement{
+		"global":    {Mode: "enforced"},
+		"category":  {Categories: map[string]string{"kubernetes": "dryrun"}},
+		"actionner": {Actionners: map[string]string{"kubernetes:drain": "off"}},
+		"name":      {Actionners: map[string]string{"drain": DryRunStr}},
+	}
+	for name, c := range cases {
+		if _, err := NewPolicy(c); err == nil {
+			t.Errorf("%v: expected an error", name)
+		}
+	}
+}
+
+func TestModeOverridesTheGlobalMode(t *testing.T) {
+	p, err := NewPolicy(configuration.Enforcement{
+		Mode:       ObserveStr,
+		Categories: map[string]string{"kubernetes": EnforceStr},
+		Actionners: map[string]string{"kubernetes:drain": DryRunStr},
+	})
+	if err != nil {
+		t.Fatalf("new policy: %v", err)
+	}
+	policy.Store(p)
+	t.Cleanup(func() { policy.Store(&Policy{mode: EnforceStr}) })
+
+	cases := []struct {
+		actionner string
+		dryRun    string
+		expected  string
+	}{
+		{actionner: "kubernetes:terminate", expected: EnforceStr},
+		{actionner: "kubernetes:terminate", dryRun: trueStr, expected: DryRunStr},
+		{actionner: "kubernetes:drain", expected: DryRunStr},
+		{actionner: "cilium:networkpolicy", expected: ObserveStr},
+		{actionner: "cilium:networkpolicy", dryRun: trueStr, expected: ObserveStr},
+	}
+	for _, c := range cases {
+		got := Mode(&rules.Rule{Name: "rule", DryRun: c.dryRun}, &rules.Action{Name: "action", Actionner: c.actionner})
+		if got != c.expected {
+			t.Errorf("%v (dry_run: %q): expected the mode %q, got %q", c.actionner, c.dryRun, c.expected, got)
+		}
+	}
+}
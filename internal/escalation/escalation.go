@@ -0,0 +1,229 @@
+package escalation
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/falcosecurity/falco-talon/internal/events"
+	k8s "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
+	"github.com/falcosecurity/falco-talon/internal/rules"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+const (
+	podKind        string = "Pod"
+	escalationsStr string = "escalations"
+
+	// flushInterval is the interval between two writes of the offenses in
+	// the file, if they changed.
+	flushInterval = 10 * time.Second
+)
+
+// ErrNoKey is returned by Key for the events without the value of the key,
+// e.g. without pod for 'workload', their offenses can't be counted.
+var ErrNoKey = errors.New("the event has no value for the key of the escalation")
+
+type lookup interface {
+	GetPod(pod, namespace string) (*corev1.Pod, error)
+	GetOwner(ref metav1.OwnerReference, namespace string) (metav1.Object, error)
+}
+
+// entry is the offenses counted for a key, the oldest first.
+type entry struct {
+	ExpiresAt time.Time   `json:"expires_at"`
+	Offenses  []time.Time `json:"offenses"`
+}
+
+// Store keeps the offenses counted by rule and key in a file, to survive the
+// restarts. The file is written every flushInterval if the offenses changed,
+// and by Close. Without file, the offenses are kept in memory only.
+type Store struct {
+	entries map[string]*entry
+	file    string
+	dirty   bool
+	done    chan struct{}
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+}
+
+var (
+	store = &Store{entries: map[string]*entry{}}
+	now   = time.Now
+)
+
+// Init loads the offenses from the file, the ones after their decay are
+// forgotten.
+func Init(file string) error {
+	s, err := NewStore(file)
+	if err != nil {
+		return err
+	}
+	store = s
+	return nil
+}
+
+// GetStore returns the store initialized by Init, a store in memory if Init
+// hasn't been called.
+func GetStore() *Store {
+	return store
+}
+
+func NewStore(file string) (*Store, error) {
+	s := &Store{
+		entries: map[string]*entry{},
+		file:    file,
+	}
+
+	if file == "" {
+		return s, nil
+	}
+	if err := utils.ReadJSONFile(file, &s.entries); err != nil {
+		return nil, fmt.Errorf("can't read the escalations file '%v': %v", file, err)
+	}
+	t := now()
+	for k, v := range s.entries {
+		if !v.ExpiresAt.After(t) {
+			delete(s.entries, k)
+		}
+	}
+
+	s.done = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		t := time.NewTicker(flushInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-t.C:
+				if err := s.Flush(); err != nil {
+					utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: escalationsStr})
+				}
+			}
+		}
+	}()
+
+	return s, nil
+}
+
+// Close stops the periodic writes and writes the offenses in the file a last
+// time.
+func (s *Store) Close() error {
+	if s.done == nil {
+		return nil
+	}
+	close(s.done)
+	s.wg.Wait()
+	return s.Flush()
+}
+
+// Record counts a new offense at t for the rule and the key, it returns the
+// number of offenses within the decay, including this one. The offense is
+// written in the file by the next Flush.
+func (s *Store) Record(rule, key string, decay time.Duration, t time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	k := rule + "/" + key
+	e := s.entries[k]
+	if e == nil {
+		e = new(entry)
+		s.entries[k] = e
+	}
+	n := 0
+	for n < len(e.Offenses) && !e.Offenses[n].After(t.Add(-decay)) {
+		n++
+	}
+	e.Offenses = append(e.Offenses[n:], t)
+	e.ExpiresAt = t.Add(decay)
+	s.dirty = true
+
+	return len(e.Offenses)
+}
+
+// Flush writes the offenses not expired in the file, if they changed since the
+// last write.
+func (s *Store) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == "" || !s.dirty {
+		return nil
+	}
+	t := now()
+	for k, v := range s.entries {
+		if !v.ExpiresAt.After(t) {
+			delete(s.entries, k)
+		}
+	}
+	if err := utils.WriteJSONFile(s.file, s.entries); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// Key returns the value the offenses of the event are counted by: the top
+// owner of the pod for 'workload', its namespace for 'namespace' and its node
+// for 'node', prefixed by the remote cluster of the event, as 'cluster/'. For
+// 'workload', the pod is returned with the error if its owners can't be
+// resolved. ErrNoKey is returned if the event has no value for the key.
+func Key(escalation *rules.Escalation, event *events.Event) (string, error) {
+	var key string
+	var err error
+	switch escalation.GetKey() {
+	case rules.NamespaceStr:
+		key = event.GetNamespaceName()
+	case rules.NodeStr:
+		key = event.GetHostname()
+	default:
+		key, err = Workload(event)
+	}
+	if key == "" {
+		return "", ErrNoKey
+	}
+	if cluster := k8s.ClusterOf(event); cluster != "" {
+		key = cluster + "/" + key
+	}
+	return key, err
+}
+
+// Workload returns the top owner of the pod of the event, as
+// 'Kind/namespace/name'. The pod is returned with the error if its owners
+// can't be resolved, ErrNoKey if the event has no pod.
+func Workload(event *events.Event) (string, error) {
+	var client lookup
+	if c := k8s.GetClientFor(event); c != nil {
+		client = c
+	}
+	return resolveWorkload(client, event)
+}
+
+func resolveWorkload(client lookup, event *events.Event) (string, error) {
+	pod, namespace := event.GetPodName(), event.GetNamespaceName()
+	if pod == "" {
+		return "", ErrNoKey
+	}
+	workload := fmt.Sprintf("%v/%v/%v", podKind, namespace, pod)
+	if client == nil {
+		return workload, errors.New("the kubernetes client is not initialized")
+	}
+	p, err := client.GetPod(pod, namespace)
+	if err != nil {
+		return workload, err
+	}
+
+	owners, err := k8s.ResolveOwners(client, namespace, p.OwnerReferences)
+	if len(owners) != 0 {
+		top := owners[len(owners)-1]
+		workload = fmt.Sprintf("%v/%v/%v", top.Kind, namespace, top.Name)
+	}
+	return workload, err
+}
@@ -0,0 +1,182 @@
+package escalation
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/events"
+	"github.com/falcosecurity/falco-talon/internal/rules"
+)
+
+func TestStoreCountsTheOffensesWithinTheDecay(t *testing.T) {
+	clock := time.Now()
+	now = func() time.Time { return clock }
+	t.Cleanup(func() { now = time.Now })
+
+	file := filepath.Join(t.TempDir(), "escalations.json")
+	s, err := NewStore(file)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+
+	for i, expected := range []int{1, 2, 3} {
+		if n := s.Record("rule", "Deployment/default/nginx", time.Hour, clock.Add(time.Duration(i)*20*time.Minute)); n != expected {
+			t.Fatalf("expected %v offense(s), got %v", expected, n)
+		}
+	}
+	if n := s.Record("other", "Deployment/default/nginx", time.Hour, clock); n != 1 {
+		t.Fatalf("expected the offenses to be counted by rule, got %v", n)
+	}
+
+	// the offenses are written by the flushes, and survive a restart
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Fatalf("expected the offenses to be written by the next flush only, got %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	s, err = NewStore(file)
+	if err != nil {
+		t.Fatalf("restore the store: %v", err)
+	}
+	if n := s.Record("rule", "Deployment/default/nginx", time.Hour, clock.Add(61*time.Minute)); n != 3 {
+		t.Fatalf("expected the first offense to decay, got %v offense(s)", n)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	// the keys expired during a restart are forgotten
+	clock = clock.Add(3 * time.Hour)
+	s, err = NewStore(file)
+	if err != nil {
+		t.Fatalf("restore the store: %v", err)
+	}
+	if len(s.entries) != 0 {
+		t.Fatalf("expected the offenses to be expired, got %v", s.entries)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+}
+
+type lookupStub struct {
+	pods        map[string]*corev1.Pod
+	replicaSets map[string]*appsv1.ReplicaSet
+	jobs        map[string]*batchv1.Job
+}
+
+func (l lookupStub) GetPod(name, _ string) (*corev1.Pod, error) {
+	if p, ok := l.pods[name]; ok {
+		return p, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (l lookupStub) GetOwner(ref metav1.OwnerReference, _ string) (metav1.Object, error) {
+	switch ref.Kind {
+	case "ReplicaSet":
+		if r, ok := l.replicaSets[ref.Name]; ok {
+			return r, nil
+		}
+	case "Job":
+		if j, ok := l.jobs[ref.Name]; ok {
+			return j, nil
+		}
+	default:
+		return nil, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func ownedBy(kind, name string) metav1.ObjectMeta {
+	controller := true
+	return metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}}
+}
+
+func TestResolveWorkload(t *testing.T) {
+	t.Parallel()
+
+	client := lookupStub{
+		pods: map[string]*corev1.Pod{
+			"nginx-7d9f-x2x4": {ObjectMeta: ownedBy("ReplicaSet", "nginx-7d9f")},
+			"backup-28311-ab": {ObjectMeta: ownedBy("Job", "backup-28311")},
+			"fluentd-k2x7":    {ObjectMeta: ownedBy("DaemonSet", "fluentd")},
+			"debug":           {},
+			"orphan-7d9f-x":   {ObjectMeta: ownedBy("ReplicaSet", "orphan-7d9f")},
+		},
+		replicaSets: map[string]*appsv1.ReplicaSet{"nginx-7d9f": {ObjectMeta: ownedBy("Deployment", "nginx")}},
+		jobs:        map[string]*batchv1.Job{"backup-28311": {ObjectMeta: ownedBy("CronJob", "backup")}},
+	}
+	inPod := func(pod string) *events.Event {
+		return &events.Event{OutputFields: map[string]any{"k8s.pod.name": pod, "k8s.ns.name": "default"}}
+	}
+
+	for pod, expected := range map[string]string{
+		"nginx-7d9f-x2x4": "Deployment/default/nginx",
+		"backup-28311-ab": "CronJob/default/backup",
+		"fluentd-k2x7":    "DaemonSet/default/fluentd",
+		"debug":           "Pod/default/debug",
+	} {
+		if got, err := resolveWorkload(client, inPod(pod)); err != nil || got != expected {
+			t.Errorf("%v: expected the workload '%v', got '%v' (%v)", pod, expected, got, err)
+		}
+	}
+
+	if got, err := resolveWorkload(client, inPod("orphan-7d9f-x")); err == nil || got != "ReplicaSet/default/orphan-7d9f" {
+		t.Errorf("expected the closest owner with an error, got '%v' (%v)", got, err)
+	}
+	if got, err := resolveWorkload(nil, inPod("debug")); err == nil || got != "Pod/default/debug" {
+		t.Errorf("expected the pod with an error without client, got '%v' (%v)", got, err)
+	}
+	if got, err := resolveWorkload(client, &events.Event{}); !errors.Is(err, ErrNoKey) || got != "" {
+		t.Errorf("expected no workload for an event without pod, got '%v' (%v)", got, err)
+	}
+}
+
+func TestKeyIsPrefixedByTheCluster(t *testing.T) {
+	configuration.CreateConfiguration("")
+	configuration.GetConfiguration().Clusters = configuration.Clusters{
+		Field:       "k8s.cluster.name",
+		Credentials: map[string]configuration.ClusterCredentials{"prod-eu": {}},
+	}
+	t.Cleanup(func() {
+		configuration.GetConfiguration().Clusters = configuration.Clusters{}
+	})
+
+	for _, c := range []struct {
+		cluster  any
+		key      string
+		expected string
+	}{
+		{key: rules.NamespaceStr, expected: "default"},
+		{key: rules.NodeStr, expected: "node-1"},
+		{cluster: "Prod-EU", key: rules.NamespaceStr, expected: "prod-eu/default"},
+		{cluster: "prod-eu", key: rules.NodeStr, expected: "prod-eu/node-1"},
+	} {
+		event := &events.Event{Hostname: "node-1", OutputFields: map[string]any{"k8s.ns.name": "default", "k8s.cluster.name": c.cluster}}
+		if got, err := Key(&rules.Escalation{Key: c.key}, event); err != nil || got != c.expected {
+			t.Errorf("expected the key '%v', got '%v' (%v)", c.expected, got, err)
+		}
+	}
+}
+
+func TestKeyFailsWithoutValue(t *testing.T) {
+	configuration.CreateConfiguration("")
+
+	for _, i := range []string{rules.WorkloadStr, rules.NamespaceStr, rules.NodeStr} {
+		if got, err := Key(&rules.Escalation{Key: i}, &events.Event{}); !errors.Is(err, ErrNoKey) || got != "" {
+			t.Errorf("%v: expected no key for an event without value, got '%v' (%v)", i, got, err)
+		}
+	}
+}
@@ -30,6 +30,11 @@ const (
 
 var regTrimPrefix *regexp.Regexp
 
+// envVarReplacer turns the keys of the fields and of the context into names
+// of env vars, e.g. 'falco-talon.previous.output' into
+// 'FALCO_TALON_PREVIOUS_OUTPUT'.
+var envVarReplacer = strings.NewReplacer(".", "_", "-", "_", "[", "_", "]", "")
+
 func init() {
 	regTrimPrefix = regexp.MustCompile(trimPrefix)
 }
@@ -102,6 +107,18 @@ func (event *Event) GetHostname() string {
 	return event.Hostname
 }
 
+// GetImage returns the image of the container, with its tag if it's known.
+func (event *Event) GetImage() string {
+	if v, ok := event.outputFieldAsString("container.image"); ok && v != "" {
+		return v
+	}
+	repository, _ := event.outputFieldAsString("container.image.repository")
+	if tag, ok := event.outputFieldAsString("container.image.tag"); ok && repository != "" && tag != "" {
+		return repository + ":" + tag
+	}
+	return repository
+}
+
 func (event *Event) GetTargetName() string {
 	if v, ok := event.outputFieldAsString("ka.target.name"); ok {
 		return v
@@ -170,26 +187,41 @@ func (event *Event) AddContext(elements map[string]any) {
 	}
 }
 
-func (event *Event) ExportEnvVars() {
+// EnvVars returns the env vars of the event, from its output fields, its
+// context and its priority, hostname, rule, source and tags. The names are in
+// upper case, with '_' instead of '.', '-' and '['.
+func (event *Event) EnvVars() map[string]string {
+	vars := make(map[string]string, len(event.OutputFields)+len(event.Context)+5)
 	for i, j := range event.OutputFields {
-		key := strings.ReplaceAll(strings.ToUpper(i), ".", "_")
-		key = strings.ReplaceAll(key, "[", "_")
-		key = strings.ReplaceAll(key, "]", "")
-		_ = os.Setenv(key, fmt.Sprintf("%v", j))
+		vars[envVarReplacer.Replace(strings.ToUpper(i))] = fmt.Sprintf("%v", j)
 	}
 	for i, j := range event.Context {
-		key := strings.ReplaceAll(strings.ToUpper(i), ".", "_")
-		_ = os.Setenv(key, fmt.Sprintf("%v", j))
+		vars[envVarReplacer.Replace(strings.ToUpper(i))] = fmt.Sprintf("%v", j)
 	}
-	_ = os.Setenv("PRIORITY", event.Priority)
-	_ = os.Setenv("HOSTNAME", event.Hostname)
-	_ = os.Setenv("RULE", event.Rule)
-	_ = os.Setenv("SOURCE", event.Source)
+	vars["PRIORITY"] = event.Priority
+	vars["HOSTNAME"] = event.Hostname
+	vars["RULE"] = event.Rule
+	vars["SOURCE"] = event.Source
 	var tags []string
 	for _, i := range event.Tags {
 		tags = append(tags, fmt.Sprintf("%v", i))
 	}
-	_ = os.Setenv("TAGS", strings.Join(tags, ","))
+	vars["TAGS"] = strings.Join(tags, ",")
+	return vars
+}
+
+// ExpandEnv replaces the env vars of the event in s, the other vars are the
+// ones of the process. The env of the process is not changed, the vars of an
+// event, like the results of its previous action, are seen by its actions
+// only.
+func (event *Event) ExpandEnv(s string) string {
+	vars := event.EnvVars()
+	return os.Expand(s, func(k string) string {
+		if v, ok := vars[k]; ok {
+			return v
+		}
+		return os.Getenv(k)
+	})
 }
 
 func (event *Event) String() string {
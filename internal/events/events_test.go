@@ -2,6 +2,7 @@ package events
 
 import (
 	"encoding/json"
+	"os"
 	"strings"
 	"testing"
 )
@@ -66,3 +67,25 @@ func TestGetRemoteProtocolReadsSourceProtocol(t *testing.T) {
 		t.Fatalf("GetRemoteProtocol() = %q, want %q", got, "tcp")
 	}
 }
+
+func TestExpandEnvDoesNotChangeTheEnvOfTheProcess(t *testing.T) {
+	t.Setenv("NODE_NAME", "node-1")
+	e := &Event{
+		Rule:         "Terminal shell in container",
+		OutputFields: map[string]any{"k8s.pod.name": "nginx", "proc.aname[2]": "bash"},
+		Context:      map[string]any{"falco-talon.previous.output": "file downloaded"},
+	}
+
+	got := e.ExpandEnv("${K8S_POD_NAME} ${PROC_ANAME_2} ${FALCO_TALON_PREVIOUS_OUTPUT} ${RULE} ${NODE_NAME}")
+	if want := "nginx bash file downloaded Terminal shell in container node-1"; got != want {
+		t.Fatalf("ExpandEnv() = %q, want %q", got, want)
+	}
+	for _, i := range []string{"K8S_POD_NAME", "FALCO_TALON_PREVIOUS_OUTPUT", "RULE"} {
+		if _, ok := os.LookupEnv(i); ok {
+			t.Errorf("expected '%v' not to be set in the env of the process", i)
+		}
+	}
+	if got := (&Event{}).ExpandEnv("${FALCO_TALON_PREVIOUS_OUTPUT}"); got != "" {
+		t.Fatalf("expected the vars of an event not to be seen by another one, got %q", got)
+	}
+}
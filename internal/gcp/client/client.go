@@ -53,66 +53,58 @@ type GcpGcsAPI interface {
 	Close() error
 }
 
-var (
-	gcpClient *GCPClient
-	once      sync.Once
-)
+var clients utils.Clients[configuration.GcpConfig, GCPClient]
 
-func Init() error {
-	if gcpClient != nil {
-		return nil
-	}
+// Init creates the client of the configuration, it's used once the
+// configuration is the current one, to take the reloads into account.
+func Init(gcpConfig configuration.GcpConfig) error {
+	return clients.Init(gcpConfig, configuration.GetConfiguration().GcpConfig, newClient)
+}
 
-	var initErr error
-	once.Do(func() {
-		gcpConfig := configuration.GetConfiguration().GcpConfig
-
-		var clientOptions []option.ClientOption
-		var creds *google.Credentials
-		var err error
-
-		if gcpConfig.CredentialsPath != "" {
-			creds, err = google.CredentialsFromJSON(context.Background(), []byte(gcpConfig.CredentialsPath), functionServiceScope)
-			if err != nil {
-				initErr = fmt.Errorf("unable to load credentials from file: %v", err)
-				return
-			}
-			clientOptions = append(clientOptions, option.WithCredentials(creds))
-		} else {
-			creds, err = google.FindDefaultCredentials(context.Background(), functionServiceScope)
-			if err != nil {
-				initErr = fmt.Errorf("unable to find default credentials: %v", err)
-				return
-			}
-			clientOptions = append(clientOptions, option.WithCredentials(creds))
-		}
+func newClient(gcpConfig configuration.GcpConfig) (*GCPClient, error) {
+	var clientOptions []option.ClientOption
+	var creds *google.Credentials
+	var err error
 
-		projectID, err := getProjectID(creds)
+	if gcpConfig.CredentialsPath != "" {
+		creds, err = google.CredentialsFromJSON(context.Background(), []byte(gcpConfig.CredentialsPath), functionServiceScope)
 		if err != nil {
-			initErr = err
-			return
+			return nil, fmt.Errorf("unable to load credentials from file: %v", err)
 		}
-
-		gcpClient = &GCPClient{
-			clientOpts: clientOptions,
-			projectID:  projectID,
-			httpClient: &http.Client{},
+		clientOptions = append(clientOptions, option.WithCredentials(creds))
+	} else {
+		creds, err = google.FindDefaultCredentials(context.Background(), functionServiceScope)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find default credentials: %v", err)
 		}
+		clientOptions = append(clientOptions, option.WithCredentials(creds))
+	}
 
-		utils.PrintLog(utils.InfoStr, utils.LogLine{Message: "init", Category: "gcp", Status: utils.SuccessStr})
-	})
+	projectID, err := getProjectID(creds)
+	if err != nil {
+		return nil, err
+	}
+
+	utils.PrintLog(utils.InfoStr, utils.LogLine{Message: "init", Category: "gcp", Status: utils.SuccessStr})
 
-	return initErr
+	return &GCPClient{
+		clientOpts: clientOptions,
+		projectID:  projectID,
+		httpClient: &http.Client{},
+	}, nil
 }
 
+// GetGCPClient returns the client of the current configuration, it's created
+// if needed.
 func GetGCPClient() (*GCPClient, error) {
-	if gcpClient == nil {
-		err := Init()
-		if err != nil {
-			return nil, err
-		}
+	current := configuration.GetConfiguration().GcpConfig
+	if c := clients.Get(current); c != nil {
+		return c, nil
+	}
+	if err := Init(current); err != nil {
+		return nil, err
 	}
-	return gcpClient, nil
+	return clients.Get(current), nil
 }
 
 func (c *GCPClient) GetGcpFunctionClient(ctx context.Context) (*functionsv2.FunctionClient, error) {
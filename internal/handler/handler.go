@@ -2,10 +2,16 @@ package handler
 
 import (
 	"crypto/md5" //nolint:gosec
+	"crypto/subtle"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -14,10 +20,15 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/approvals"
 	"github.com/falcosecurity/falco-talon/internal/events"
+	"github.com/falcosecurity/falco-talon/internal/history"
 	"github.com/falcosecurity/falco-talon/internal/nats"
 	"github.com/falcosecurity/falco-talon/internal/otlp/metrics"
 	"github.com/falcosecurity/falco-talon/internal/otlp/traces"
+	"github.com/falcosecurity/falco-talon/internal/pause"
+	"github.com/falcosecurity/falco-talon/internal/schedules"
+	"github.com/falcosecurity/falco-talon/internal/suppressions"
 	"github.com/falcosecurity/falco-talon/utils"
 )
 
@@ -94,3 +105,368 @@ func HealthHandler(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Add("Content-Type", "application/json")
 	_, _ = w.Write([]byte(`{"status": "ok"}`))
 }
+
+// ReloadHandler returns a handler triggering a reload of the configuration and
+// the rules. The requests must be authenticated with the token 'reload_token'
+// of the configuration in an 'Authorization: Bearer' header, the endpoint is
+// disabled if no token is set. The response waits for the end of the reload,
+// whatever the write timeout of the server.
+func ReloadHandler(reload func() error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authenticate(w, r, configuration.GetConfiguration().ReloadToken) {
+			return
+		}
+
+		if r.Method != http.MethodPost {
+			http.Error(w, "Please send with POST http method", http.StatusBadRequest)
+			return
+		}
+
+		// the clients of a new configuration can take longer than the write
+		// timeout to init
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+		if err := reload(); err != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"status": utils.FailureStr, "error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": utils.SuccessStr})
+	}
+}
+
+// ApprovalsHandler lists the pending approvals.
+func ApprovalsHandler(w http.ResponseWriter, r *http.Request) {
+	if !authenticate(w, r, configuration.GetConfiguration().Approvals.Token) {
+		return
+	}
+
+	store := approvals.GetStore()
+	if store == nil {
+		http.Error(w, "The approvals are not available", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, store.List())
+}
+
+// ApprovalDecisionHandler approves or denies a pending approval, the author of
+// the decision can be set with the field 'by' of the JSON body. The decision is
+// applied in the background, the approval is returned with the status 202.
+func ApprovalDecisionHandler(w http.ResponseWriter, r *http.Request) {
+	if !authenticate(w, r, configuration.GetConfiguration().Approvals.Token) {
+		return
+	}
+
+	store := approvals.GetStore()
+	if store == nil {
+		http.Error(w, "The approvals are not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var body struct {
+		By string `json:"by"`
+	}
+	if r.Body != nil && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Please send a valid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	if body.By == "" {
+		body.By = "api"
+	}
+
+	approval, err := store.Decide(r.PathValue("id"), r.PathValue("decision"), body.By)
+	switch {
+	case errors.Is(err, approvals.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		writeJSON(w, http.StatusAccepted, approval)
+	}
+}
+
+// SchedulesHandler lists the delayed actions waiting for their time.
+func SchedulesHandler(w http.ResponseWriter, r *http.Request) {
+	if !authenticate(w, r, configuration.GetConfiguration().Schedules.Token) {
+		return
+	}
+
+	store := schedules.GetStore()
+	if store == nil {
+		http.Error(w, "The delayed actions are not available", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, store.List())
+}
+
+// ScheduleCancelHandler cancels a delayed action and the rest of its chain,
+// the author of the cancellation can be set with the field 'by' of the JSON
+// body. The 'finally' actions are run in the background, the schedule is
+// returned with the status 202.
+func ScheduleCancelHandler(w http.ResponseWriter, r *http.Request) {
+	if !authenticate(w, r, configuration.GetConfiguration().Schedules.Token) {
+		return
+	}
+
+	store := schedules.GetStore()
+	if store == nil {
+		http.Error(w, "The delayed actions are not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var body struct {
+		By string `json:"by"`
+	}
+	if r.Body != nil && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Please send a valid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	if body.By == "" {
+		body.By = "api"
+	}
+
+	schedule, err := store.Cancel(r.PathValue("id"), body.By)
+	switch {
+	case errors.Is(err, schedules.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		writeJSON(w, http.StatusAccepted, schedule)
+	}
+}
+
+// HistoryHandler queries the results of the actions, with the parameters
+// 'rule', 'actionner', 'namespace', 'pod', 'status', 'since', 'until' and
+// 'limit'. The times are in RFC 3339 or durations before now.
+func HistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if !authenticate(w, r, configuration.GetConfiguration().History.Token) {
+		return
+	}
+
+	store := history.GetStore()
+	if store == nil {
+		http.Error(w, "The history is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	filter, err := parseHistoryFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	l, err := store.Query(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, l)
+}
+
+func parseHistoryFilter(q url.Values) (history.Filter, error) {
+	filter := history.Filter{
+		Rule:      q.Get("rule"),
+		Actionner: q.Get("actionner"),
+		Namespace: q.Get("namespace"),
+		Pod:       q.Get("pod"),
+		Status:    q.Get("status"),
+	}
+	var err error
+	if filter.Since, err = parseTime(q.Get("since")); err != nil {
+		return filter, fmt.Errorf("wrong value for 'since': %v", err)
+	}
+	if filter.Until, err = parseTime(q.Get("until")); err != nil {
+		return filter, fmt.Errorf("wrong value for 'until': %v", err)
+	}
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit < 0 {
+			return filter, fmt.Errorf("wrong value for 'limit': '%v'", v)
+		}
+	}
+	return filter, nil
+}
+
+// parseTime parses a time in RFC 3339, or a duration before now.
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// PauseStateHandler returns the pause of the actions.
+func PauseStateHandler(w http.ResponseWriter, r *http.Request) {
+	if !authenticate(w, r, configuration.GetConfiguration().Pause.Token) {
+		return
+	}
+
+	store := pause.GetStore()
+	if store == nil {
+		http.Error(w, "The pause is not available", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, store.Get())
+}
+
+// PauseHandler pauses all the actions, or the ones of the 'category' or of the
+// 'rule' of the JSON body, with a 'reason' and the author 'by'.
+func PauseHandler(w http.ResponseWriter, r *http.Request) {
+	updatePause(w, r, (*pause.Store).Pause)
+}
+
+// ResumeHandler resumes all the actions, or the ones of the 'category' or of
+// the 'rule' of the JSON body.
+func ResumeHandler(w http.ResponseWriter, r *http.Request) {
+	updatePause(w, r, (*pause.Store).Resume)
+}
+
+func updatePause(w http.ResponseWriter, r *http.Request, update func(*pause.Store, pause.Request) (pause.State, error)) {
+	if !authenticate(w, r, configuration.GetConfiguration().Pause.Token) {
+		return
+	}
+
+	store := pause.GetStore()
+	if store == nil {
+		http.Error(w, "The pause is not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var request pause.Request
+	if r.Body != nil && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, "Please send a valid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	if request.By == "" {
+		request.By = "api"
+	}
+
+	state, err := update(store, request)
+	switch {
+	case errors.Is(err, pause.ErrInvalidRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		writeJSON(w, http.StatusOK, state)
+	}
+}
+
+// SuppressionsHandler lists the suppressions not expired.
+func SuppressionsHandler(w http.ResponseWriter, r *http.Request) {
+	if !authenticate(w, r, configuration.GetConfiguration().Suppressions.Token) {
+		return
+	}
+
+	store := suppressions.GetStore()
+	if store == nil {
+		http.Error(w, "The suppressions are not available", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, store.List())
+}
+
+// SuppressionAddHandler adds a suppression, from the fields 'rule', 'key',
+// 'field', 'value', 'duration', 'reason' and 'by' of the JSON body.
+func SuppressionAddHandler(w http.ResponseWriter, r *http.Request) {
+	if !authenticate(w, r, configuration.GetConfiguration().Suppressions.Token) {
+		return
+	}
+
+	store := suppressions.GetStore()
+	if store == nil {
+		http.Error(w, "The suppressions are not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var body struct {
+		Rule     string `json:"rule"`
+		Key      string `json:"key"`
+		Field    string `json:"field"`
+		Value    string `json:"value"`
+		Duration string `json:"duration"`
+		Reason   string `json:"reason"`
+		By       string `json:"by"`
+	}
+	if r.Body == nil || json.NewDecoder(r.Body).Decode(&body) != nil {
+		http.Error(w, "Please send a valid request body", http.StatusBadRequest)
+		return
+	}
+	d, err := time.ParseDuration(body.Duration)
+	if err != nil || d <= 0 {
+		http.Error(w, fmt.Sprintf("wrong duration '%v'", body.Duration), http.StatusBadRequest)
+		return
+	}
+	if body.By == "" {
+		body.By = "api"
+	}
+
+	t := time.Now().UTC()
+	suppression := &suppressions.Suppression{
+		CreatedAt: t,
+		ExpiresAt: t.Add(d),
+		Rule:      body.Rule,
+		Key:       body.Key,
+		Field:     body.Field,
+		Value:     body.Value,
+		Reason:    body.Reason,
+		By:        body.By,
+	}
+	if err := store.Add(suppression); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, http.StatusOK, suppression)
+}
+
+// SuppressionDeleteHandler deletes a suppression before it expires.
+func SuppressionDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	if !authenticate(w, r, configuration.GetConfiguration().Suppressions.Token) {
+		return
+	}
+
+	store := suppressions.GetStore()
+	if store == nil {
+		http.Error(w, "The suppressions are not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	suppression, err := store.Delete(r.PathValue("id"))
+	switch {
+	case errors.Is(err, suppressions.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		writeJSON(w, http.StatusOK, suppression)
+	}
+}
+
+// authenticate checks the 'Authorization: Bearer' header of the request, the
+// endpoint is disabled if the token is empty.
+func authenticate(w http.ResponseWriter, r *http.Request, token string) bool {
+	if token == "" {
+		http.Error(w, "The endpoint is disabled", http.StatusNotFound)
+		return false
+	}
+
+	bearer, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
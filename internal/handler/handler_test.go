@@ -0,0 +1,302 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/approvals"
+	"github.com/falcosecurity/falco-talon/internal/history"
+	"github.com/falcosecurity/falco-talon/internal/pause"
+	"github.com/falcosecurity/falco-talon/internal/rules"
+	"github.com/falcosecurity/falco-talon/internal/schedules"
+	"github.com/falcosecurity/falco-talon/internal/suppressions"
+)
+
+func TestReloadHandler(t *testing.T) {
+	config := configuration.GetConfiguration()
+	t.Cleanup(func() { config.ReloadToken = "" })
+
+	calls := 0
+	var reloadErr error
+	h := ReloadHandler(func() error {
+		calls++
+		return reloadErr
+	})
+
+	request := func(method, authorization string) int {
+		req := httptest.NewRequest(method, "/reload", http.NoBody)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		h(w, req)
+		return w.Code
+	}
+
+	if code := request(http.MethodPost, "Bearer secret"); code != http.StatusNotFound {
+		t.Fatalf("expected the endpoint to be disabled without token, got %d", code)
+	}
+
+	config.ReloadToken = "secret"
+	if code := request(http.MethodPost, ""); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", code)
+	}
+	if code := request(http.MethodPost, "Bearer wrong"); code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 with a wrong token, got %d", code)
+	}
+	if code := request(http.MethodGet, "Bearer secret"); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 with GET, got %d", code)
+	}
+	if calls != 0 {
+		t.Fatalf("expected no reload for the rejected requests, got %d", calls)
+	}
+
+	if code := request(http.MethodPost, "Bearer secret"); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	reloadErr = errors.New("invalid rules")
+	if code := request(http.MethodPost, "Bearer secret"); code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for a failed reload, got %d", code)
+	}
+	if calls != 2 {
+		t.Fatalf("expected 2 reloads, got %d", calls)
+	}
+
+	// a reload longer than the write timeout still gets its response
+	server := httptest.NewUnstartedServer(ReloadHandler(func() error {
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	}))
+	server.Config.WriteTimeout = 50 * time.Millisecond
+	server.Start()
+	t.Cleanup(server.Close)
+	req, _ := http.NewRequest(http.MethodPost, server.URL, http.NoBody)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatalf("expected a response after the write timeout, got %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestApprovalDecisionHandler(t *testing.T) {
+	config := configuration.GetConfiguration()
+	config.Approvals.Token = "secret"
+	t.Cleanup(func() { config.Approvals.Token = "" })
+
+	// the decision blocks until the response is checked
+	release := make(chan struct{})
+	decided := make(chan string, 1)
+	if err := approvals.Init(filepath.Join(t.TempDir(), "approvals.json"), func(_ *approvals.Approval, decision, by string) {
+		<-release
+		decided <- decision + " by " + by
+	}); err != nil {
+		t.Fatalf("init the approvals: %v", err)
+	}
+	t.Cleanup(approvals.GetStore().Close)
+	approval := &approvals.Approval{CreatedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour), DefaultDecision: rules.DenyStr}
+	if err := approvals.GetStore().Add(approval); err != nil {
+		t.Fatalf("add the approval: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /approvals/{id}/{decision}", ApprovalDecisionHandler)
+	request := func(path, body string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := request("/approvals/"+approval.ID+"/maybe", ""); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid decision, got %d", code)
+	}
+	if code := request("/approvals/unknown/approve", ""); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown approval, got %d", code)
+	}
+	if code := request("/approvals/"+approval.ID+"/approve", `{"by":"alice"}`); code != http.StatusAccepted {
+		t.Fatalf("expected 202 before the decision is applied, got %d", code)
+	}
+	close(release)
+	if d := <-decided; d != "approve by alice" {
+		t.Fatalf("expected the approval to be approved by alice, got '%v'", d)
+	}
+	if code := request("/approvals/"+approval.ID+"/approve", ""); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an approval already decided, got %d", code)
+	}
+}
+
+func TestScheduleCancelHandler(t *testing.T) {
+	config := configuration.GetConfiguration()
+	config.Schedules.Token = "secret"
+	t.Cleanup(func() { config.Schedules.Token = "" })
+
+	// the end blocks until the response is checked
+	release := make(chan struct{})
+	cancelled := make(chan string, 1)
+	if err := schedules.Init(filepath.Join(t.TempDir(), "schedules.json"), func(_ *schedules.Schedule, status, by string) {
+		<-release
+		cancelled <- status + " by " + by
+	}); err != nil {
+		t.Fatalf("init the schedules: %v", err)
+	}
+	t.Cleanup(schedules.GetStore().Close)
+	schedule := &schedules.Schedule{CreatedAt: time.Now(), FiresAt: time.Now().Add(time.Hour)}
+	if err := schedules.GetStore().Add(schedule); err != nil {
+		t.Fatalf("add the schedule: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /schedules/{id}/cancel", ScheduleCancelHandler)
+	request := func(path, body string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := request("/schedules/unknown/cancel", ""); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown schedule, got %d", code)
+	}
+	if code := request("/schedules/"+schedule.ID+"/cancel", `{"by":"alice"}`); code != http.StatusAccepted {
+		t.Fatalf("expected 202 before the end of the chain, got %d", code)
+	}
+	close(release)
+	if c := <-cancelled; c != "cancelled by alice" {
+		t.Fatalf("expected the schedule to be cancelled by alice, got '%v'", c)
+	}
+	if code := request("/schedules/"+schedule.ID+"/cancel", ""); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a schedule already cancelled, got %d", code)
+	}
+}
+
+func TestHistoryHandler(t *testing.T) {
+	config := configuration.GetConfiguration()
+	config.History.Token = "secret"
+	t.Cleanup(func() { config.History.Token = "" })
+
+	if err := history.Init(filepath.Join(t.TempDir(), "history.db"), time.Hour); err != nil {
+		t.Fatalf("init the history: %v", err)
+	}
+	t.Cleanup(history.GetStore().Close)
+	for _, ns := range []string{"ns1", "ns2"} {
+		if err := history.GetStore().Add(&history.Entry{Time: time.Now(), Rule: "rule", Namespace: ns}); err != nil {
+			t.Fatalf("add the entry: %v", err)
+		}
+	}
+
+	request := func(query string) (int, string) {
+		req := httptest.NewRequest(http.MethodGet, "/history?"+query, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		HistoryHandler(w, req)
+		return w.Code, w.Body.String()
+	}
+
+	code, body := request("namespace=ns2&since=1h")
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if !strings.Contains(body, `"namespace":"ns2"`) || strings.Contains(body, `"namespace":"ns1"`) {
+		t.Fatalf("expected the entry of ns2 only, got %v", body)
+	}
+	if code, _ := request("since=yesterday"); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a wrong time, got %d", code)
+	}
+	if code, _ := request("limit=-1"); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a wrong limit, got %d", code)
+	}
+}
+
+func TestPauseHandler(t *testing.T) {
+	config := configuration.GetConfiguration()
+	config.Pause.Token = "secret"
+	t.Cleanup(func() { config.Pause.Token = "" })
+
+	if err := pause.Init(filepath.Join(t.TempDir(), "pause.json"), nil, "", 0); err != nil {
+		t.Fatalf("init the pause: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = pause.GetStore().Resume(pause.Request{})
+		pause.GetStore().Close()
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /pause", PauseStateHandler)
+	mux.HandleFunc("POST /pause", PauseHandler)
+	mux.HandleFunc("POST /resume", ResumeHandler)
+	request := func(method, path, body string) (int, string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w.Code, w.Body.String()
+	}
+
+	if code, _ := request(http.MethodPost, "/pause", `{"category":"kubernetes","rule":"rule"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a category and a rule, got %d", code)
+	}
+	if code, body := request(http.MethodPost, "/pause", `{"reason":"incident"}`); code != http.StatusOK || !strings.Contains(body, `"all":true`) {
+		t.Fatalf("expected the actions to be paused, got %d %v", code, body)
+	}
+	if _, body := request(http.MethodGet, "/pause", ""); !strings.Contains(body, `"updated_by":"api"`) {
+		t.Fatalf("expected the author of the pause, got %v", body)
+	}
+	if code, body := request(http.MethodPost, "/resume", ""); code != http.StatusOK || !strings.Contains(body, `"all":false`) {
+		t.Fatalf("expected the actions to be resumed, got %d %v", code, body)
+	}
+}
+
+func TestSuppressionHandlers(t *testing.T) {
+	config := configuration.GetConfiguration()
+	config.Suppressions.Token = "secret"
+	t.Cleanup(func() { config.Suppressions.Token = "" })
+
+	if err := suppressions.Init(filepath.Join(t.TempDir(), "suppressions.json"), false); err != nil {
+		t.Fatalf("init the suppressions: %v", err)
+	}
+	t.Cleanup(suppressions.GetStore().Close)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /suppressions", SuppressionAddHandler)
+	mux.HandleFunc("DELETE /suppressions/{id}", SuppressionDeleteHandler)
+	request := func(method, path, body string) (int, string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w.Code, w.Body.String()
+	}
+
+	if code, _ := request(http.MethodPost, "/suppressions", `{"key":"node","value":"node1"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 without duration, got %d", code)
+	}
+	if code, _ := request(http.MethodPost, "/suppressions", `{"key":"unknown","value":"node1","duration":"1h"}`); code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown key, got %d", code)
+	}
+	code, body := request(http.MethodPost, "/suppressions", `{"key":"node","value":"node1","duration":"1h"}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d %v", code, body)
+	}
+	l := suppressions.GetStore().List()
+	if len(l) != 1 || l[0].By != "api" {
+		t.Fatalf("expected the suppression to be added, got %+v", l)
+	}
+	if code, _ := request(http.MethodDelete, "/suppressions/"+l[0].ID, ""); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if code, _ := request(http.MethodDelete, "/suppressions/"+l[0].ID, ""); code != http.StatusNotFound {
+		t.Fatalf("expected 404 for a suppression already deleted, got %d", code)
+	}
+}
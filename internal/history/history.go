@@ -0,0 +1,298 @@
+package history
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/falcosecurity/falco-talon/internal/events"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+const (
+	historyStr string = "history"
+
+	// DefaultLimit is the number of entries returned by a query without limit.
+	DefaultLimit int = 100
+	// MaxLimit is the maximum number of entries returned by a query.
+	MaxLimit int = 10000
+
+	// pruneInterval is the maximum interval between two prunes of the
+	// entries older than the retention.
+	pruneInterval = 1 * time.Hour
+)
+
+var bucket = []byte("actions")
+
+// Entry is the result of an action with the metadata of its event.
+type Entry struct {
+	Time          time.Time         `json:"time"`
+	Objects       map[string]string `json:"objects,omitempty"`
+	Rule          string            `json:"rule"`
+	Action        string            `json:"action"`
+	Actionner     string            `json:"actionner"`
+	Status        string            `json:"status,omitempty"`
+	Output        string            `json:"output,omitempty"`
+	Error         string            `json:"error,omitempty"`
+	OutputTarget  string            `json:"output_target,omitempty"`
+	OutputStatus  string            `json:"output_status,omitempty"`
+	TraceID       string            `json:"trace_id,omitempty"`
+	RulesVersion  string            `json:"rules_version,omitempty"`
+	RulesChecksum string            `json:"rules_checksum,omitempty"`
+	Namespace     string            `json:"namespace,omitempty"`
+	Pod           string            `json:"pod,omitempty"`
+	Hostname      string            `json:"hostname,omitempty"`
+	Resource      string            `json:"resource,omitempty"`
+	EventRule     string            `json:"event_rule,omitempty"`
+	EventPriority string            `json:"event_priority,omitempty"`
+	EventSource   string            `json:"event_source,omitempty"`
+	EventOutput   string            `json:"event_output,omitempty"`
+	Mode          string            `json:"mode,omitempty"`
+	DryRun        bool              `json:"dry_run,omitempty"`
+}
+
+// Filter selects the entries of a query, the empty fields match all the
+// entries.
+type Filter struct {
+	Since     time.Time `json:"since"`
+	Until     time.Time `json:"until"`
+	Rule      string    `json:"rule"`
+	Actionner string    `json:"actionner"`
+	Namespace string    `json:"namespace"`
+	Pod       string    `json:"pod"`
+	Status    string    `json:"status"`
+	Limit     int       `json:"limit"`
+}
+
+// Store keeps the results of the actions in an embedded database, the entries
+// older than the retention are pruned.
+type Store struct {
+	db        *bolt.DB
+	retention time.Duration
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+var (
+	store *Store
+	now   = time.Now
+)
+
+// Init opens the database of the history and starts the prunes of the old
+// entries.
+func Init(file string, retention time.Duration) error {
+	s, err := NewStore(file, retention)
+	if err != nil {
+		return err
+	}
+	store = s
+	return nil
+}
+
+// GetStore returns the store initialized by Init, nil if the history is
+// disabled.
+func GetStore() *Store {
+	return store
+}
+
+func NewStore(file string, retention time.Duration) (*Store, error) {
+	if retention <= 0 {
+		return nil, fmt.Errorf("wrong retention '%v' for the history", retention)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+		return nil, err
+	}
+	// the timeout avoids to wait forever for the lock of another process
+	db, err := bolt.Open(file, 0o600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("can't open the history file '%v': %v", file, err)
+	}
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucket)
+		return err
+	}); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	s := &Store{
+		db:        db,
+		retention: retention,
+		done:      make(chan struct{}),
+	}
+	if _, err := s.Prune(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		t := time.NewTicker(min(retention, pruneInterval))
+		defer t.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-t.C:
+				if _, err := s.Prune(); err != nil {
+					utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: historyStr})
+				}
+			}
+		}
+	}()
+
+	return s, nil
+}
+
+// Close stops the prunes and closes the database.
+func (s *Store) Close() {
+	close(s.done)
+	s.wg.Wait()
+	_ = s.db.Close()
+}
+
+// Add stores a new entry, the entries are ordered by time.
+func (s *Store) Add(entry *Entry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(bucket)
+		seq, err := bk.NextSequence()
+		if err != nil {
+			return err
+		}
+		// the sequence keeps the entries with the same time apart
+		key := make([]byte, 16)
+		copy(key, timeKey(entry.Time))
+		binary.BigEndian.PutUint64(key[8:], seq)
+		return bk.Put(key, b)
+	})
+}
+
+// Query returns the entries matching the filter, the most recent first.
+func (s *Store) Query(filter Filter) ([]*Entry, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	limit = min(limit, MaxLimit)
+
+	l := []*Entry{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucket).Cursor()
+		var k, v []byte
+		if filter.Until.IsZero() {
+			k, v = c.Last()
+		} else {
+			// the first key after the until time, then the entry before it
+			k, v = c.Seek(timeKey(filter.Until.Add(1)))
+			if k == nil {
+				k, v = c.Last()
+			} else {
+				k, v = c.Prev()
+			}
+		}
+		since := timeKey(filter.Since)
+		for ; k != nil && len(l) < limit; k, v = c.Prev() {
+			if !filter.Since.IsZero() && bytes.Compare(k[:8], since) < 0 {
+				break
+			}
+			e := new(Entry)
+			if err := json.Unmarshal(v, e); err != nil {
+				return err
+			}
+			if filter.match(e) {
+				l = append(l, e)
+			}
+		}
+		return nil
+	})
+	return l, err
+}
+
+// Prune deletes the entries older than the retention, it returns the number
+// of entries deleted.
+func (s *Store) Prune() (int, error) {
+	limit := timeKey(now().Add(-s.retention))
+	n := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		bk := tx.Bucket(bucket)
+		// the keys are collected first, a deletion moves the cursor
+		var keys [][]byte
+		c := bk.Cursor()
+		for k, _ := c.First(); k != nil && bytes.Compare(k[:8], limit) < 0; k, _ = c.Next() {
+			keys = append(keys, k)
+		}
+		for _, k := range keys {
+			if err := bk.Delete(k); err != nil {
+				return err
+			}
+		}
+		n = len(keys)
+		return nil
+	})
+	return n, err
+}
+
+func (f Filter) match(e *Entry) bool {
+	return (f.Rule == "" || f.Rule == e.Rule) &&
+		(f.Actionner == "" || f.Actionner == e.Actionner) &&
+		(f.Namespace == "" || f.Namespace == e.Namespace) &&
+		(f.Pod == "" || f.Pod == e.Pod) &&
+		(f.Status == "" || f.Status == e.Status)
+}
+
+// Record stores the result of an action in the history, the failures are
+// logged, they never block the actions.
+func Record(event *events.Event, log utils.LogLine, output *utils.LogLine, dryRun bool) {
+	if store == nil {
+		return
+	}
+	e := &Entry{
+		Time:          now().UTC(),
+		Objects:       log.Objects,
+		Rule:          log.Rule,
+		Action:        log.Action,
+		Actionner:     log.Actionner,
+		Status:        log.Status,
+		Output:        log.Output,
+		Error:         log.Error,
+		TraceID:       log.TraceID,
+		RulesVersion:  log.RulesVersion,
+		RulesChecksum: log.RulesChecksum,
+		Namespace:     event.GetNamespaceName(),
+		Pod:           event.GetPodName(),
+		Hostname:      event.GetHostname(),
+		Resource:      event.GetTargetResource(),
+		EventRule:     event.Rule,
+		EventPriority: event.Priority,
+		EventSource:   event.Source,
+		EventOutput:   event.Output,
+		Mode:          log.Mode,
+		DryRun:        dryRun,
+	}
+	if output != nil {
+		e.OutputTarget, e.OutputStatus = output.OutputTarget, output.Status
+	}
+	if err := store.Add(e); err != nil {
+		utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Rule: log.Rule, Action: log.Action, TraceID: log.TraceID, Message: historyStr})
+	}
+}
+
+// timeKey encodes the time in a key sorted in chronological order, the times
+// before 1970 are clamped.
+func timeKey(t time.Time) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(max(t.UnixNano(), 0))) // #nosec G115 -- the value is positive
+	return b
+}
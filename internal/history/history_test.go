@@ -0,0 +1,72 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStoreQueriesAndPrunesTheEntries(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return start }
+	t.Cleanup(func() { now = time.Now })
+
+	s, err := NewStore(filepath.Join(t.TempDir(), "history.db"), 24*time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(s.Close)
+
+	for i, e := range []*Entry{
+		{Rule: "a", Actionner: "kubernetes:terminate", Namespace: "ns1", Pod: "p1", Status: "success"},
+		{Rule: "a", Actionner: "kubernetes:label", Namespace: "ns2", Pod: "p2", Status: "failure"},
+		{Rule: "b", Actionner: "kubernetes:terminate", Namespace: "ns1", Pod: "p3", Status: "success"},
+		{Rule: "b", Actionner: "kubernetes:label", Namespace: "ns1", Pod: "p1", Status: "success"},
+	} {
+		e.Time = start.Add(time.Duration(i) * time.Hour)
+		if err := s.Add(e); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	tests := map[string]struct {
+		filter Filter
+		pods   []string
+	}{
+		"all, the most recent first": {filter: Filter{}, pods: []string{"p1", "p3", "p2", "p1"}},
+		"by rule":                    {filter: Filter{Rule: "a"}, pods: []string{"p2", "p1"}},
+		"by actionner and namespace": {filter: Filter{Actionner: "kubernetes:terminate", Namespace: "ns1"}, pods: []string{"p3", "p1"}},
+		"by pod and status":          {filter: Filter{Pod: "p1", Status: "success"}, pods: []string{"p1", "p1"}},
+		"time range":                 {filter: Filter{Since: start.Add(time.Hour), Until: start.Add(2 * time.Hour)}, pods: []string{"p3", "p2"}},
+		"limit":                      {filter: Filter{Limit: 1}, pods: []string{"p1"}},
+		"until before all":           {filter: Filter{Until: start.Add(-time.Hour)}, pods: []string{}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			l, err := s.Query(tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(l) != len(tt.pods) {
+				t.Fatalf("expected %v entries, got %v", len(tt.pods), len(l))
+			}
+			for i := range l {
+				if l[i].Pod != tt.pods[i] {
+					t.Fatalf("expected the pod '%v' at %v, got '%v'", tt.pods[i], i, l[i].Pod)
+				}
+			}
+		})
+	}
+
+	now = func() time.Time { return start.Add(26 * time.Hour) }
+	n, err := s.Prune()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 entries pruned, got %v", n)
+	}
+	if l, _ := s.Query(Filter{}); len(l) != 2 {
+		t.Fatalf("expected 2 entries left, got %v", len(l))
+	}
+}
@@ -2,6 +2,7 @@ package checks
 
 import (
 	"errors"
+	"fmt"
 	"net"
 	"strconv"
 
@@ -33,11 +34,11 @@ func CheckPodExist(event *events.Event) error {
 		return err
 	}
 
-	client := k8s.GetClient()
-	if client == nil {
-		return errors.New("wrong k8s client")
+	client, err := ClientFor(event)
+	if err != nil {
+		return err
 	}
-	_, err := client.GetPod(event.GetPodName(), event.GetNamespaceName())
+	_, err = client.GetPod(event.GetPodName(), event.GetNamespaceName())
 	return err
 }
 
@@ -119,10 +120,23 @@ func CheckTargetExist(event *events.Event) error {
 		return err
 	}
 
-	client := k8s.GetClient()
-	if client == nil {
-		return errors.New("wrong k8s client")
+	client, err := ClientFor(event)
+	if err != nil {
+		return err
 	}
-	_, err := client.GetTarget(event.GetTargetResource(), event.GetTargetName(), event.GetTargetNamespace())
+	_, err = client.GetTarget(event.GetTargetResource(), event.GetTargetName(), event.GetTargetNamespace())
 	return err
 }
+
+// ClientFor returns the client of the cluster of the event, an error if its
+// cluster has no credentials.
+func ClientFor(event *events.Event) (*k8s.Client, error) {
+	client := k8s.GetClientFor(event)
+	if client != nil {
+		return client, nil
+	}
+	if cluster := k8s.ClusterOf(event); cluster != "" {
+		return nil, fmt.Errorf("no credentials for the cluster '%v'", cluster)
+	}
+	return nil, errors.New("wrong k8s client")
+}
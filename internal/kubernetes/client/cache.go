@@ -0,0 +1,81 @@
+package kubernetes
+
+import (
+	"sync/atomic"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/informers"
+	k8s "k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+// informerCache holds the listers of the shared informers of the pods, the
+// nodes and their owners. The lookups fall back to the API server until the
+// informers are synced and for the objects missing from the cache, e.g. a pod
+// created just before its event.
+type informerCache struct {
+	factory      informers.SharedInformerFactory
+	pods         corelisters.PodLister
+	nodes        corelisters.NodeLister
+	replicaSets  appslisters.ReplicaSetLister
+	deployments  appslisters.DeploymentLister
+	statefulSets appslisters.StatefulSetLister
+	daemonSets   appslisters.DaemonSetLister
+	synced       atomic.Bool
+}
+
+func newInformerCache(clientset k8s.Interface) *informerCache {
+	// the managed fields are never used by the actionners, they're dropped to
+	// reduce the memory of the cache
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0, informers.WithTransform(func(obj any) (any, error) {
+		if m, err := meta.Accessor(obj); err == nil {
+			m.SetManagedFields(nil)
+		}
+		return obj, nil
+	}))
+
+	// the listers register their informers, they must be created before the
+	// start of the factory
+	return &informerCache{
+		factory:      factory,
+		pods:         factory.Core().V1().Pods().Lister(),
+		nodes:        factory.Core().V1().Nodes().Lister(),
+		replicaSets:  factory.Apps().V1().ReplicaSets().Lister(),
+		deployments:  factory.Apps().V1().Deployments().Lister(),
+		statefulSets: factory.Apps().V1().StatefulSets().Lister(),
+		daemonSets:   factory.Apps().V1().DaemonSets().Lister(),
+	}
+}
+
+// start starts the informers and waits for their first sync, at most for the
+// timeout. It returns false if they're not synced yet, the sync goes on in
+// the background.
+func (c *informerCache) start(stop <-chan struct{}, timeout time.Duration) bool {
+	c.factory.Start(stop)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _, ok := range c.factory.WaitForCacheSync(stop) {
+			if !ok {
+				return
+			}
+		}
+		c.synced.Store(true)
+	}()
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case <-done:
+	case <-t.C:
+	}
+	return c.synced.Load()
+}
+
+// ready returns true if the lookups can use the cache.
+func (c *informerCache) ready() bool {
+	return c != nil && c.synced.Load()
+}
@@ -0,0 +1,86 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestLookupsUseTheCacheFirst(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path)
+		if r.URL.Path != "/api/v1/namespaces/default/pods/late" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(&corev1.Pod{
+			TypeMeta:   metav1.TypeMeta{Kind: "Pod", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "late", Namespace: "default"},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	clientset, err := k8s.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatalf("new clientset: %v", err)
+	}
+
+	c := Client{
+		Clientset: clientset,
+		cache: newInformerCache(fake.NewClientset(
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default", Labels: map[string]string{"app": "demo"}},
+				Spec:       corev1.PodSpec{NodeName: "node-1"},
+			},
+			&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+			&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "demo-1", Namespace: "default"}},
+		)),
+	}
+	if c.cache.ready() {
+		t.Fatal("expected the cache not to be ready before its sync")
+	}
+	stop := make(chan struct{})
+	t.Cleanup(func() { close(stop) })
+	if !c.cache.start(stop, 10*time.Second) {
+		t.Fatal("expected the cache to be synced")
+	}
+
+	pod, err := c.GetPod("demo", "default")
+	if err != nil {
+		t.Fatalf("get pod: %v", err)
+	}
+	pod.Labels["app"] = "changed"
+	if pod, _ = c.GetPod("demo", "default"); pod.Labels["app"] != "demo" {
+		t.Fatalf("expected the cached pod to be a copy, got the label %q", pod.Labels["app"])
+	}
+	if _, err := c.GetNodeFromPod(pod); err != nil {
+		t.Fatalf("get node: %v", err)
+	}
+	if _, err := c.GetReplicaSet("demo-1", "default"); err != nil {
+		t.Fatalf("get replicaset: %v", err)
+	}
+	if len(requests) != 0 {
+		t.Fatalf("expected no request to the API server, got %v", requests)
+	}
+
+	if _, err := c.GetPod("late", "default"); err != nil {
+		t.Fatalf("get the pod missing from the cache: %v", err)
+	}
+	if _, err := c.GetDeployment("unknown", "default"); err == nil {
+		t.Fatal("expected an error for an unknown deployment")
+	}
+	if len(requests) != 2 {
+		t.Fatalf("expected the misses to fall back to the API server, got %v", requests)
+	}
+}
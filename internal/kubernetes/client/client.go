@@ -19,12 +19,17 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
 	k8s "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/leaderelection"
@@ -41,6 +46,11 @@ import (
 type Client struct {
 	*k8s.Clientset
 	RestConfig *rest.Config
+	cache      *informerCache
+	dynamic    dynamic.Interface
+	mapper     meta.RESTMapper
+	// Cluster is the name of the cluster, empty for the local one
+	Cluster string
 }
 
 type targetLookup interface {
@@ -74,6 +84,8 @@ type KubernetesClient interface {
 	GetStatefulsetFromPod(pod *corev1.Pod) (*appsv1.StatefulSet, error)
 	GetReplicasetFromPod(pod *corev1.Pod) (*appsv1.ReplicaSet, error)
 	GetNodeFromPod(pod *corev1.Pod) (*corev1.Node, error)
+	GetOwner(ref metav1.OwnerReference, namespace string) (metav1.Object, error)
+	GetWorkload(pod *corev1.Pod) (*Workload, error)
 	GetTarget(resource, name, namespace string) (any, error)
 	GetNamespace(name string) (*corev1.Namespace, error)
 	GetConfigMap(name, namespace string) (*corev1.ConfigMap, error)
@@ -116,26 +128,26 @@ func Init() error {
 	var initErr error
 
 	once.Do(func() {
-		client = new(Client)
 		config := configuration.GetConfiguration()
+		var restConfig *rest.Config
 		var err error
 		if config.KubeConfig != "" {
-			client.RestConfig, err = clientcmd.BuildConfigFromFlags("", config.KubeConfig)
+			restConfig, err = clientcmd.BuildConfigFromFlags("", config.KubeConfig)
 		} else {
-			client.RestConfig, err = rest.InClusterConfig()
+			restConfig, err = rest.InClusterConfig()
 		}
 		if err != nil {
 			initErr = err
 			return
 		}
-
-		// creates the clientset
-		client.Clientset, err = k8s.NewForConfig(client.RestConfig)
+		c, err := newClient("", restConfig, config.Kubernetes)
 		if err != nil {
 			initErr = err
 			return
 		}
 
+		client, clusters = c, newClusterClients(config.Clusters, config.Kubernetes)
+
 		// // disable klog
 		klog.InitFlags(nil)
 		// Opt into fixed stderrthreshold behavior (kubernetes/klog#212).
@@ -159,6 +171,47 @@ func Init() error {
 	return initErr
 }
 
+// newClient creates the client of a cluster from its rest config, with its
+// cache if it's enabled. The name of the local cluster is empty.
+func newClient(cluster string, restConfig *rest.Config, config configuration.Kubernetes) (*Client, error) {
+	c := &Client{RestConfig: restConfig, Cluster: cluster}
+	if config.QPS > 0 {
+		c.RestConfig.QPS = config.QPS
+	}
+	if config.Burst > 0 {
+		c.RestConfig.Burst = config.Burst
+	}
+
+	// creates the clientset
+	var err error
+	c.Clientset, err = k8s.NewForConfig(c.RestConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	// the dynamic client fetches the owners of the pods of any kind, the
+	// CRDs included
+	c.dynamic, err = dynamic.NewForConfig(c.RestConfig)
+	if err != nil {
+		return nil, err
+	}
+	c.mapper = restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(c.Clientset.Discovery()))
+
+	if config.Cache {
+		timeout, err := time.ParseDuration(config.CacheSyncTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("wrong kubernetes.cache_sync_timeout '%v': %v", config.CacheSyncTimeout, err)
+		}
+		c.cache = newInformerCache(c.Clientset)
+		if c.cache.start(wait.NeverStop, timeout) {
+			utils.PrintLog(utils.InfoStr, utils.LogLine{Message: "init", Category: "kubernetes", Cluster: cluster, Result: "the cache is synced", Status: utils.SuccessStr})
+		} else {
+			utils.PrintLog(utils.WarningStr, utils.LogLine{Message: "init", Category: "kubernetes", Cluster: cluster, Error: "the cache is not synced yet, the lookups use the API server until it is"})
+		}
+	}
+	return c, nil
+}
+
 func GetClient() *Client {
 	if client == nil {
 		if err := Init(); err != nil {
@@ -168,7 +221,15 @@ func GetClient() *Client {
 	return client
 }
 
+// GetPod, as the lookups of the nodes and the owners of the pods, uses the
+// cache first if it's enabled, and else the API server. The objects of the
+// cache are copies, they can be modified.
 func (client Client) GetPod(pod, namespace string) (*corev1.Pod, error) {
+	if client.cache.ready() {
+		if p, err := client.cache.pods.Pods(namespace).Get(pod); err == nil {
+			return p.DeepCopy(), nil
+		}
+	}
 	p, err := client.Clientset.CoreV1().Pods(namespace).Get(context.Background(), pod, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("the pod '%v' in the namespace '%v' doesn't exist", pod, namespace)
@@ -185,6 +246,11 @@ func (client Client) GetJob(job, namespace string) (*batchv1.Job, error) {
 }
 
 func (client Client) GetDeployment(name, namespace string) (*appsv1.Deployment, error) {
+	if client.cache.ready() {
+		if p, err := client.cache.deployments.Deployments(namespace).Get(name); err == nil {
+			return p.DeepCopy(), nil
+		}
+	}
 	p, err := client.Clientset.AppsV1().Deployments(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("the deployment '%v' in the namespace '%v' doesn't exist", name, namespace)
@@ -193,6 +259,11 @@ func (client Client) GetDeployment(name, namespace string) (*appsv1.Deployment,
 }
 
 func (client Client) GetDaemonSet(name, namespace string) (*appsv1.DaemonSet, error) {
+	if client.cache.ready() {
+		if p, err := client.cache.daemonSets.DaemonSets(namespace).Get(name); err == nil {
+			return p.DeepCopy(), nil
+		}
+	}
 	p, err := client.Clientset.AppsV1().DaemonSets(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("the daemonset '%v' in the namespace '%v' doesn't exist", name, namespace)
@@ -201,6 +272,11 @@ func (client Client) GetDaemonSet(name, namespace string) (*appsv1.DaemonSet, er
 }
 
 func (client Client) GetStatefulSet(name, namespace string) (*appsv1.StatefulSet, error) {
+	if client.cache.ready() {
+		if p, err := client.cache.statefulSets.StatefulSets(namespace).Get(name); err == nil {
+			return p.DeepCopy(), nil
+		}
+	}
 	p, err := client.Clientset.AppsV1().StatefulSets(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("the statefulset '%v' in the namespace '%v' doesn't exist", name, namespace)
@@ -209,6 +285,11 @@ func (client Client) GetStatefulSet(name, namespace string) (*appsv1.StatefulSet
 }
 
 func (client Client) GetReplicaSet(name, namespace string) (*appsv1.ReplicaSet, error) {
+	if client.cache.ready() {
+		if p, err := client.cache.replicaSets.ReplicaSets(namespace).Get(name); err == nil {
+			return p.DeepCopy(), nil
+		}
+	}
 	p, err := client.Clientset.AppsV1().ReplicaSets(namespace).Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("the replicaset '%v' in the namespace '%v' doesn't exist", name, namespace)
@@ -217,6 +298,11 @@ func (client Client) GetReplicaSet(name, namespace string) (*appsv1.ReplicaSet,
 }
 
 func (client Client) GetNode(name string) (*corev1.Node, error) {
+	if client.cache.ready() {
+		if p, err := client.cache.nodes.Get(name); err == nil {
+			return p.DeepCopy(), nil
+		}
+	}
 	p, err := client.Clientset.CoreV1().Nodes().Get(context.Background(), name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("error getting node '%v': %v", name, err)
@@ -392,20 +478,25 @@ func (client Client) GetWatcherEndpointSlices(labelSelector, namespace string) (
 	return watcher.ResultChan(), nil
 }
 
+// CurrentNamespace returns the namespace of Falco Talon, from the env var
+// NAMESPACE or else from its service account, 'falco' by default.
+func CurrentNamespace() string {
+	if namespace := os.Getenv("NAMESPACE"); namespace != "" {
+		return namespace
+	}
+	if ns, err := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace"); err == nil {
+		return string(ns)
+	}
+	return "falco"
+}
+
 func (client Client) GetLeaseHolder() (<-chan string, error) {
 	if leaseHolderChan != nil {
 		return leaseHolderChan, nil
 	}
 
 	leaseHolderChan = make(chan string, 20)
-	namespace := os.Getenv("NAMESPACE")
-	if namespace == "" {
-		if ns, err := os.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace"); err == nil {
-			namespace = string(ns)
-		} else {
-			namespace = "falco"
-		}
-	}
+	namespace := CurrentNamespace()
 
 	leaderElectionConfig := leaderelection.LeaderElectionConfig{
 		Lock: &resourcelock.LeaseLock{
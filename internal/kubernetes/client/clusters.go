@@ -0,0 +1,90 @@
+package kubernetes
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/events"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+// clusters are the clients of the remote clusters, by lowercased name.
+var clusters map[string]*Client
+
+// newClusterClients creates the clients of the remote clusters. A cluster with
+// wrong credentials is logged and skipped, its events get no client, without
+// preventing the others and the local one from working.
+func newClusterClients(config configuration.Clusters, k configuration.Kubernetes) map[string]*Client {
+	c := make(map[string]*Client, len(config.Credentials))
+	for name, credentials := range config.Credentials {
+		name = strings.ToLower(name)
+		restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: credentials.KubeConfig},
+			&clientcmd.ConfigOverrides{CurrentContext: credentials.Context},
+		).ClientConfig()
+		if err != nil {
+			utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: fmt.Sprintf("wrong credentials: %v", err), Message: "init", Category: "kubernetes", Cluster: name, Status: utils.FailureStr})
+			continue
+		}
+		client, err := newClient(name, restConfig, k)
+		if err != nil {
+			utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: fmt.Sprintf("can't create the client: %v", err), Message: "init", Category: "kubernetes", Cluster: name, Status: utils.FailureStr})
+			continue
+		}
+		c[name] = client
+		utils.PrintLog(utils.InfoStr, utils.LogLine{Message: "init", Category: "kubernetes", Cluster: name, Status: utils.SuccessStr})
+	}
+	return c
+}
+
+// ClusterOf returns the name of the remote cluster of the event, from its
+// output field 'clusters.field', lowercased. It's empty for the local cluster,
+// and if no remote cluster is configured.
+func ClusterOf(event *events.Event) string {
+	config := configuration.GetConfiguration().Clusters
+	if len(config.Credentials) == 0 || config.Field == "" || event == nil {
+		return ""
+	}
+	v, ok := event.OutputFields[config.Field]
+	if !ok || v == nil {
+		return ""
+	}
+	name := strings.ToLower(fmt.Sprintf("%v", v))
+	if name == strings.ToLower(config.Local) {
+		return ""
+	}
+	return name
+}
+
+// GetClientFor returns the client of the cluster of the event, nil if its
+// cluster has no credentials.
+func GetClientFor(event *events.Event) *Client {
+	return GetClusterClient(ClusterOf(event))
+}
+
+// GetClusterClient returns the client of the named cluster, the local one if
+// the name is empty, nil if the cluster has no credentials.
+func GetClusterClient(name string) *Client {
+	c := GetClient()
+	if name == "" || c == nil {
+		return c
+	}
+	return clusters[strings.ToLower(name)]
+}
+
+// Clusters returns the names of the remote clusters.
+func Clusters() []string {
+	if GetClient() == nil {
+		return nil
+	}
+	s := make([]string, 0, len(clusters))
+	for i := range clusters {
+		s = append(s, i)
+	}
+	slices.Sort(s)
+	return s
+}
@@ -0,0 +1,91 @@
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/events"
+)
+
+const kubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: eu
+  cluster:
+    server: https://eu.example.com
+- name: us
+  cluster:
+    server: https://us.example.com
+users:
+- name: talon
+  user:
+    token: secret
+contexts:
+- name: eu
+  context:
+    cluster: eu
+    user: talon
+- name: us
+  context:
+    cluster: us
+    user: talon
+current-context: eu
+`
+
+func TestClustersAreSelectedByTheEvents(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "kubeconfig.yaml")
+	if err := os.WriteFile(file, []byte(kubeconfig), 0o600); err != nil {
+		t.Fatalf("write kubeconfig: %v", err)
+	}
+	configFile := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configFile, []byte(`
+clusters:
+  local: Management
+  credentials:
+    Prod-EU:
+      kubeconfig: `+file+`
+    prod-us:
+      kubeconfig: `+file+`
+      context: us
+`), 0o600); err != nil {
+		t.Fatalf("write config file: %v", err)
+	}
+	config := configuration.CreateConfiguration(configFile)
+
+	c := newClusterClients(config.Clusters, config.Kubernetes)
+	if c["prod-eu"] == nil || c["prod-eu"].RestConfig.Host != "https://eu.example.com" {
+		t.Fatalf("expected the client of the current context for prod-eu, got %+v", c["prod-eu"])
+	}
+	if c["prod-us"] == nil || c["prod-us"].RestConfig.Host != "https://us.example.com" || c["prod-us"].Cluster != "prod-us" {
+		t.Fatalf("expected the client of the context us for prod-us, got %+v", c["prod-us"])
+	}
+
+	for value, expected := range map[any]string{
+		"PROD-EU":    "prod-eu",
+		"management": "",
+		"unknown":    "unknown",
+		nil:          "",
+	} {
+		event := &events.Event{OutputFields: map[string]any{"k8s.cluster.name": value}}
+		if got := ClusterOf(event); got != expected {
+			t.Errorf("%v: expected the cluster %q, got %q", value, expected, got)
+		}
+	}
+	if got := ClusterOf(&events.Event{OutputFields: map[string]any{}}); got != "" {
+		t.Errorf("expected the local cluster for an event without the field, got %q", got)
+	}
+
+	c = newClusterClients(configuration.Clusters{Credentials: map[string]configuration.ClusterCredentials{
+		"missing": {KubeConfig: filepath.Join(dir, "missing.yaml")},
+		"prod-eu": {KubeConfig: file},
+	}}, config.Kubernetes)
+	if _, ok := c["missing"]; ok {
+		t.Fatal("expected no client for a missing kubeconfig")
+	}
+	if c["prod-eu"] == nil {
+		t.Fatal("expected the client of the other clusters despite a missing kubeconfig")
+	}
+}
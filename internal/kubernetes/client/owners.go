@@ -0,0 +1,237 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	podKind string = "Pod"
+
+	// maxOwnersDepth limits the walk of the owner references, a Pod owned by
+	// a Job owned by a CronJob is 2 levels deep.
+	maxOwnersDepth int = 5
+)
+
+// revisionLabels are set by the controllers on the pods of a single revision
+// or run of their workload, they're removed from the selectors of the
+// workloads.
+var revisionLabels = []string{
+	"pod-template-hash",
+	"pod-template-generation",
+	"controller-revision-hash",
+	"rollouts-pod-template-hash",
+	"controller-uid",
+	"job-name",
+	"batch.kubernetes.io/controller-uid",
+	"batch.kubernetes.io/job-name",
+}
+
+// OwnerLookup fetches the owners of the objects from their references. An
+// owner of a kind which can't be fetched is returned nil without error.
+type OwnerLookup interface {
+	GetOwner(ref metav1.OwnerReference, namespace string) (metav1.Object, error)
+}
+
+// Owner is a controller in the chain of the owners of an object.
+type Owner struct {
+	// Object is nil if the owner can't be fetched
+	Object    metav1.Object
+	Labels    map[string]string
+	Kind      string
+	Name      string
+	Namespace string
+}
+
+// Workload is the top-level controller of a pod, or the pod itself if it's
+// not controlled.
+type Workload struct {
+	// Labels are the labels of the workload
+	Labels map[string]string
+	// Selector selects the pods of all the revisions and runs of the workload
+	Selector  map[string]string
+	Kind      string
+	Name      string
+	Namespace string
+	// Owners is the chain of the controllers, from the one of the pod to the
+	// workload
+	Owners []Owner
+}
+
+// String returns the workload as 'Kind/namespace/name'.
+func (w *Workload) String() string {
+	return fmt.Sprintf("%v/%v/%v", w.Kind, w.Namespace, w.Name)
+}
+
+// GetOwner fetches the owner of the reference, with the cache for the kinds
+// of the cache and with the dynamic client for the others, the CRDs included.
+// The owners of the unknown kinds or which can't be read are returned nil.
+func (client Client) GetOwner(ref metav1.OwnerReference, namespace string) (metav1.Object, error) {
+	switch ref.Kind {
+	case "ReplicaSet":
+		return asObject(client.GetReplicaSet(ref.Name, namespace))
+	case "Deployment":
+		return asObject(client.GetDeployment(ref.Name, namespace))
+	case "DaemonSet":
+		return asObject(client.GetDaemonSet(ref.Name, namespace))
+	case "StatefulSet":
+		return asObject(client.GetStatefulSet(ref.Name, namespace))
+	case "Job":
+		return asObject(client.GetJob(ref.Name, namespace))
+	}
+
+	if client.dynamic == nil || client.mapper == nil {
+		return nil, nil
+	}
+	gv, err := schema.ParseGroupVersion(ref.APIVersion)
+	if err != nil {
+		return nil, nil
+	}
+	mapping, err := client.mapper.RESTMapping(schema.GroupKind{Group: gv.Group, Kind: ref.Kind}, gv.Version)
+	if err != nil {
+		return nil, nil
+	}
+	resource := client.dynamic.Resource(mapping.Resource)
+	var r dynamic.ResourceInterface = resource
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		r = resource.Namespace(namespace)
+	}
+	u, err := r.Get(context.Background(), ref.Name, metav1.GetOptions{})
+	if apierrors.IsForbidden(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("the %v '%v' in the namespace '%v' doesn't exist", strings.ToLower(ref.Kind), ref.Name, namespace)
+	}
+	return u, nil
+}
+
+// GetWorkload returns the top-level workload of the pod.
+func (client Client) GetWorkload(pod *corev1.Pod) (*Workload, error) {
+	return ResolveWorkload(client, pod)
+}
+
+// asObject avoids the nil pointers of the typed getters wrapped in non nil
+// interfaces.
+func asObject[T metav1.Object](obj T, err error) (metav1.Object, error) {
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// ResolveOwners walks the chain of the controllers of an object. The owners
+// which can't be fetched are returned without their labels and end the walk.
+// If an owner doesn't exist, the chain up to it is returned with the error.
+func ResolveOwners(client OwnerLookup, namespace string, refs []metav1.OwnerReference) ([]Owner, error) {
+	var owners []Owner
+	for range maxOwnersDepth {
+		ref := ControllerOf(refs)
+		if ref == nil {
+			break
+		}
+		o := Owner{Kind: ref.Kind, Name: ref.Name, Namespace: namespace}
+		obj, err := client.GetOwner(*ref, namespace)
+		if err != nil {
+			return append(owners, o), err
+		}
+		if obj == nil {
+			owners = append(owners, o)
+			break
+		}
+		o.Object = obj
+		o.Labels = obj.GetLabels()
+		owners = append(owners, o)
+		refs = obj.GetOwnerReferences()
+	}
+	return owners, nil
+}
+
+// ResolveWorkload returns the top-level workload of the pod. Its selector is
+// the one of the highest owner which can be fetched, or the labels of the
+// pod, without the labels of a single revision or run.
+func ResolveWorkload(client OwnerLookup, pod *corev1.Pod) (*Workload, error) {
+	owners, err := ResolveOwners(client, pod.Namespace, pod.OwnerReferences)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Workload{Kind: podKind, Name: pod.Name, Namespace: pod.Namespace, Labels: maps.Clone(pod.Labels), Owners: owners}
+	if len(owners) != 0 {
+		top := owners[len(owners)-1]
+		w.Kind, w.Name, w.Labels = top.Kind, top.Name, maps.Clone(top.Labels)
+	}
+
+	for i := len(owners) - 1; i >= 0 && len(w.Selector) == 0; i-- {
+		if owners[i].Object != nil {
+			w.Selector = selectorOf(owners[i].Object)
+		}
+	}
+	if len(w.Selector) == 0 {
+		w.Selector = withoutRevisionLabels(pod.Labels)
+	}
+	return w, nil
+}
+
+// ControllerOf returns the owner reference flagged as controller, or the first
+// one.
+func ControllerOf(refs []metav1.OwnerReference) *metav1.OwnerReference {
+	for i := range refs {
+		if refs[i].Controller != nil && *refs[i].Controller {
+			return &refs[i]
+		}
+	}
+	if len(refs) != 0 {
+		return &refs[0]
+	}
+	return nil
+}
+
+// selectorOf returns the match labels of the selector of the workload, or the
+// labels of the template of its pods, e.g. for the Jobs and the CronJobs.
+func selectorOf(obj metav1.Object) map[string]string {
+	var content map[string]any
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		content = u.Object
+	} else {
+		r, ok := obj.(runtime.Object)
+		if !ok {
+			return nil
+		}
+		var err error
+		if content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(r); err != nil {
+			return nil
+		}
+	}
+
+	for _, path := range [][]string{
+		{"spec", "selector", "matchLabels"},
+		{"spec", "template", "metadata", "labels"},
+		{"spec", "jobTemplate", "spec", "template", "metadata", "labels"},
+	} {
+		l, _, _ := unstructured.NestedStringMap(content, path...)
+		if s := withoutRevisionLabels(l); len(s) != 0 {
+			return s
+		}
+	}
+	return nil
+}
+
+func withoutRevisionLabels(l map[string]string) map[string]string {
+	s := maps.Clone(l)
+	for _, i := range revisionLabels {
+		delete(s, i)
+	}
+	return s
+}
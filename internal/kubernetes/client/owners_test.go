@@ -0,0 +1,148 @@
+package kubernetes
+
+import (
+	"errors"
+	"maps"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+type ownerLookupStub map[string]metav1.Object
+
+func (s ownerLookupStub) GetOwner(ref metav1.OwnerReference, _ string) (metav1.Object, error) {
+	if ref.Kind == "Rollout" {
+		return nil, nil
+	}
+	if o, ok := s[ref.Kind+"/"+ref.Name]; ok {
+		return o, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func controlledBy(kind, name string) []metav1.OwnerReference {
+	controller := true
+	return []metav1.OwnerReference{
+		{Kind: "Other", Name: "other"},
+		{Kind: kind, Name: name, Controller: &controller},
+	}
+}
+
+func TestResolveWorkload(t *testing.T) {
+	t.Parallel()
+
+	client := ownerLookupStub{
+		"ReplicaSet/nginx-7d9f": &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx-7d9f", OwnerReferences: controlledBy("Deployment", "nginx")},
+			Spec:       appsv1.ReplicaSetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "nginx", "pod-template-hash": "7d9f"}}},
+		},
+		"Deployment/nginx": &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "nginx", Labels: map[string]string{"team": "web"}},
+			Spec:       appsv1.DeploymentSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "nginx"}}},
+		},
+		"Job/backup-28311": &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "backup-28311", OwnerReferences: controlledBy("CronJob", "backup")},
+			Spec:       batchv1.JobSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"batch.kubernetes.io/controller-uid": "1234"}}},
+		},
+		"CronJob/backup": &unstructured.Unstructured{Object: map[string]any{
+			"metadata": map[string]any{"name": "backup"},
+			"spec": map[string]any{"jobTemplate": map[string]any{"spec": map[string]any{"template": map[string]any{
+				"metadata": map[string]any{"labels": map[string]any{"app": "backup"}},
+			}}}},
+		}},
+		"ReplicaSet/canary-5c8b": &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "canary-5c8b", OwnerReferences: controlledBy("Rollout", "canary")},
+			Spec:       appsv1.ReplicaSetSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "canary", "rollouts-pod-template-hash": "5c8b"}}},
+		},
+	}
+
+	cases := []struct {
+		pod      *corev1.Pod
+		selector map[string]string
+		workload string
+		owners   int
+	}{
+		{
+			pod:      &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "nginx-7d9f-x2x4", Namespace: "default", OwnerReferences: controlledBy("ReplicaSet", "nginx-7d9f")}},
+			workload: "Deployment/default/nginx",
+			selector: map[string]string{"app": "nginx"},
+			owners:   2,
+		},
+		{
+			pod:      &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "backup-28311-ab", Namespace: "default", OwnerReferences: controlledBy("Job", "backup-28311")}},
+			workload: "CronJob/default/backup",
+			selector: map[string]string{"app": "backup"},
+			owners:   2,
+		},
+		{
+			pod:      &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "canary-5c8b-x", Namespace: "default", OwnerReferences: controlledBy("ReplicaSet", "canary-5c8b")}},
+			workload: "Rollout/default/canary",
+			selector: map[string]string{"app": "canary"},
+			owners:   2,
+		},
+		{
+			pod:      &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "default", Labels: map[string]string{"run": "debug"}}},
+			workload: "Pod/default/debug",
+			selector: map[string]string{"run": "debug"},
+		},
+	}
+
+	for _, c := range cases {
+		w, err := ResolveWorkload(client, c.pod)
+		if err != nil {
+			t.Errorf("%v: resolve: %v", c.pod.Name, err)
+			continue
+		}
+		if w.String() != c.workload || !maps.Equal(w.Selector, c.selector) || len(w.Owners) != c.owners {
+			t.Errorf("%v: expected %v with the selector %v and %d owner(s), got %v with %v and %d", c.pod.Name, c.workload, c.selector, c.owners, w, w.Selector, len(w.Owners))
+		}
+	}
+
+	w, _ := ResolveWorkload(client, cases[0].pod)
+	w.Selector["changed"] = "true"
+	if _, ok := client["Deployment/nginx"].(*appsv1.Deployment).Spec.Selector.MatchLabels["changed"]; ok {
+		t.Fatal("expected the selector to be a copy")
+	}
+
+	owners, err := ResolveOwners(client, "default", controlledBy("ReplicaSet", "orphan-7d9f"))
+	if err == nil || len(owners) != 1 || owners[0].Name != "orphan-7d9f" {
+		t.Fatalf("expected the missing owner with an error, got %v (%v)", owners, err)
+	}
+}
+
+func TestGetOwnerUsesTheDynamicClient(t *testing.T) {
+	t.Parallel()
+
+	gvk := schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"}
+	rollout := &unstructured.Unstructured{}
+	rollout.SetGroupVersionKind(gvk)
+	rollout.SetName("canary")
+	rollout.SetNamespace("default")
+	rollout.SetLabels(map[string]string{"team": "web"})
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(gvk, meta.RESTScopeNamespace)
+	c := Client{
+		dynamic: dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), rollout),
+		mapper:  mapper,
+	}
+
+	o, err := c.GetOwner(metav1.OwnerReference{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", Name: "canary"}, "default")
+	if err != nil || o == nil || o.GetLabels()["team"] != "web" {
+		t.Fatalf("expected the rollout, got %v (%v)", o, err)
+	}
+	if _, err := c.GetOwner(metav1.OwnerReference{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", Name: "unknown"}, "default"); err == nil {
+		t.Fatal("expected an error for an unknown rollout")
+	}
+	if o, err := c.GetOwner(metav1.OwnerReference{APIVersion: "example.com/v1", Kind: "Unknown", Name: "x"}, "default"); o != nil || err != nil {
+		t.Fatalf("expected an unknown kind to be ignored, got %v (%v)", o, err)
+	}
+}
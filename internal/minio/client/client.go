@@ -1,8 +1,6 @@
 package client
 
 import (
-	"sync"
-
 	minio "github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
 
@@ -14,42 +12,31 @@ type MinioClient struct {
 	minioClient *minio.Client
 }
 
-var (
-	minioClient *MinioClient
-	once        sync.Once
-)
-
-func Init() error {
-	if minioClient != nil {
-		return nil
-	}
-
-	var initErr error
+var clients utils.Clients[configuration.MinioConfig, MinioClient]
 
-	once.Do(func() {
-		config := configuration.GetConfiguration().MinioConfig
-
-		c, err := minio.New(config.Endpoint, &minio.Options{
-			Creds:  credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
-			Secure: config.UseSSL,
-		})
-		if err != nil {
-			initErr = err
-			return
-		}
-
-		minioClient = &MinioClient{
-			minioClient: c,
-		}
+// Init creates the client of the configuration, it's used once the
+// configuration is the current one, to take the reloads into account.
+func Init(config configuration.MinioConfig) error {
+	return clients.Init(config, configuration.GetConfiguration().MinioConfig, newClient)
+}
 
-		if initErr == nil {
-			utils.PrintLog(utils.InfoStr, utils.LogLine{Message: "init", Category: "minio", Status: utils.SuccessStr})
-		}
+func newClient(config configuration.MinioConfig) (*MinioClient, error) {
+	c, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
+		Secure: config.UseSSL,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	utils.PrintLog(utils.InfoStr, utils.LogLine{Message: "init", Category: "minio", Status: utils.SuccessStr})
 
-	return initErr
+	return &MinioClient{
+		minioClient: c,
+	}, nil
 }
 
+// GetClient returns the client of the current configuration.
 func GetClient() *minio.Client {
-	return minioClient.minioClient
+	return clients.Get(configuration.GetConfiguration().MinioConfig).minioClient
 }
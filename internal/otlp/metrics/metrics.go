@@ -17,6 +17,8 @@ import (
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 
 	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/budget"
+	"github.com/falcosecurity/falco-talon/internal/rules"
 	"github.com/falcosecurity/falco-talon/utils"
 )
 
@@ -76,6 +78,23 @@ func Init() {
 	actionCounters, _ = meter.Int64Counter(metricPrefix+"actions", metric.WithDescription("number of actions"))
 	notificationCounters, _ = meter.Int64Counter(metricPrefix+"notifications", metric.WithDescription("number of notifications"))
 	outputCounters, _ = meter.Int64Counter(metricPrefix+"outputs", metric.WithDescription("number of outputs"))
+	_, _ = meter.Int64ObservableGauge(metricPrefix+"rules_version",
+		metric.WithDescription("version of the loaded rules, with their checksum as attribute"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			rs := rules.GetRuleSet()
+			o.Observe(int64(rs.Version), metric.WithAttributes(attribute.Key("checksum").String(rs.ShortChecksum()))) // #nosec G115 -- the version is a counter of reloads
+			return nil
+		}),
+	)
+	_, _ = meter.Int64ObservableGauge(metricPrefix+"budget_remaining",
+		metric.WithDescription("number of actions still allowed by the budgets, by budget, cluster and scope"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			for _, i := range budget.ListRemaining() {
+				o.Observe(int64(i.Remaining), metric.WithAttributes(attribute.Key("budget").String(i.Budget), attribute.Key("cluster").String(i.Cluster), attribute.Key("scope").String(i.Scope)))
+			}
+			return nil
+		}),
+	)
 }
 
 func newOtlpMetricExporter(cfg *configuration.Configuration) (sdk.Exporter, error) {
@@ -144,6 +163,9 @@ func getMeasurementOption(log utils.LogLine) metric.MeasurementOption {
 	if log.Status != "" {
 		attrs = append(attrs, attribute.Key("status").String(log.Status))
 	}
+	if log.Mode != "" {
+		attrs = append(attrs, attribute.Key("mode").String(log.Mode))
+	}
 	if log.OutputTarget != "" {
 		attrs = append(attrs, attribute.Key("target").String(log.OutputTarget))
 	}
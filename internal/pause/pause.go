@@ -0,0 +1,349 @@
+package pause
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/falcosecurity/falco-talon/internal/rules"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+const (
+	pauseStr string = "pause"
+
+	// stateKey is the key of the state in the data of the ConfigMap.
+	stateKey string = "state"
+)
+
+var ErrInvalidRequest = errors.New("the category and the rule can't be set together")
+
+// State is the pause of the actions, for all of them, for the categories of
+// actionners and for the rules.
+type State struct {
+	UpdatedAt time.Time `json:"updated_at"`
+	UpdatedBy string    `json:"updated_by,omitempty"`
+	// Reason is the reason of the last change.
+	Reason     string   `json:"reason,omitempty"`
+	Categories []string `json:"categories,omitempty"`
+	Rules      []string `json:"rules,omitempty"`
+	All        bool     `json:"all"`
+}
+
+// Request pauses or resumes all the actions, or the actions of a category of
+// actionners, or of a rule.
+type Request struct {
+	Category string `json:"category,omitempty"`
+	Rule     string `json:"rule,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	By       string `json:"by,omitempty"`
+}
+
+// backend persists the state, in a file for a single replica or in a
+// ConfigMap shared by all the replicas.
+type backend interface {
+	load(ctx context.Context) (*State, error)
+	save(ctx context.Context, state *State) error
+}
+
+// Store keeps the state in its backend and a copy of it in memory, refreshed
+// periodically to follow the changes made by the other replicas.
+type Store struct {
+	backend backend
+	state   *State
+	done    chan struct{}
+	wg      sync.WaitGroup
+	// mu protects the state, backendMu serializes the calls to the backend
+	// without blocking the checks of the actions
+	mu        sync.RWMutex
+	backendMu sync.Mutex
+}
+
+var (
+	store *Store
+	now   = time.Now
+)
+
+// Init loads the state from the ConfigMap if its name is set, from the file
+// else. The state is refreshed at the interval, 0 disables the refreshes.
+func Init(file string, configMaps typedcorev1.ConfigMapInterface, configMap string, interval time.Duration) error {
+	var b backend = &fileBackend{file: file}
+	if configMap != "" {
+		b = &configMapBackend{client: configMaps, name: configMap}
+	}
+	s, err := newStore(b, interval)
+	if err != nil {
+		return err
+	}
+	store = s
+	return nil
+}
+
+// GetStore returns the store initialized by Init, nil if the pause is
+// disabled.
+func GetStore() *Store {
+	return store
+}
+
+func newStore(b backend, interval time.Duration) (*Store, error) {
+	s := &Store{
+		backend: b,
+		state:   new(State),
+		done:    make(chan struct{}),
+	}
+	state, err := b.load(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	s.state = state
+	if state.paused() {
+		utils.PrintLog(utils.WarningStr, utils.LogLine{Result: state.String(), Message: pauseStr})
+	}
+
+	if interval > 0 {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			t := time.NewTicker(interval)
+			defer t.Stop()
+			for {
+				select {
+				case <-s.done:
+					return
+				case <-t.C:
+					s.refresh()
+				}
+			}
+		}()
+	}
+	return s, nil
+}
+
+// Close stops the refreshes.
+func (s *Store) Close() {
+	close(s.done)
+	s.wg.Wait()
+}
+
+// refresh reloads the state from the backend, the last state known is kept if
+// it fails.
+func (s *Store) refresh() {
+	s.backendMu.Lock()
+	defer s.backendMu.Unlock()
+	state, err := s.backend.load(context.Background())
+	if err != nil {
+		utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: pauseStr})
+		return
+	}
+	s.mu.Lock()
+	changed := !state.UpdatedAt.Equal(s.state.UpdatedAt)
+	s.state = state
+	s.mu.Unlock()
+	if changed {
+		utils.PrintLog(utils.InfoStr, utils.LogLine{Result: state.String(), Message: pauseStr})
+	}
+}
+
+// Get returns a copy of the current state.
+func (s *Store) Get() State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state := *s.state
+	state.Categories = slices.Clone(s.state.Categories)
+	state.Rules = slices.Clone(s.state.Rules)
+	return state
+}
+
+// Pause pauses all the actions, or the ones of the category or of the rule
+// of the request.
+func (s *Store) Pause(request Request) (State, error) {
+	return s.update(request, true)
+}
+
+// Resume resumes all the actions, or the ones of the category or of the rule
+// of the request. Resuming all the actions resumes the categories too, not the
+// rules.
+func (s *Store) Resume(request Request) (State, error) {
+	return s.update(request, false)
+}
+
+func (s *Store) update(request Request, paused bool) (State, error) {
+	if request.Category != "" && request.Rule != "" {
+		return State{}, ErrInvalidRequest
+	}
+
+	s.backendMu.Lock()
+	defer s.backendMu.Unlock()
+	var state *State
+	// the state is reloaded before the change, to not override the changes
+	// of the other replicas
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var err error
+		state, err = s.backend.load(context.Background())
+		if err != nil {
+			return err
+		}
+		switch {
+		case request.Category != "":
+			state.Categories = set(state.Categories, request.Category, paused)
+		case request.Rule != "":
+			state.Rules = set(state.Rules, request.Rule, paused)
+		default:
+			state.All = paused
+			if !paused {
+				state.Categories = nil
+			}
+		}
+		state.UpdatedAt, state.UpdatedBy, state.Reason = now().UTC(), request.By, request.Reason
+		return s.backend.save(context.Background(), state)
+	})
+	if err != nil {
+		return State{}, err
+	}
+	s.mu.Lock()
+	s.state = state
+	s.mu.Unlock()
+	utils.PrintLog(utils.WarningStr, utils.LogLine{Result: state.String(), Message: pauseStr})
+	return *state, nil
+}
+
+// Check returns why the action of the rule is paused, empty if it's not.
+func Check(rule string, action *rules.Action) string {
+	if store == nil {
+		return ""
+	}
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	return store.state.check(rule, action.GetActionnerCategory())
+}
+
+func (state *State) check(rule, category string) string {
+	var reason string
+	switch {
+	case state.All:
+		reason = "all the actions are paused"
+	case slices.Contains(state.Rules, rule):
+		reason = fmt.Sprintf("the rule '%v' is disabled", rule)
+	case slices.Contains(state.Categories, category):
+		reason = fmt.Sprintf("the actions of the category '%v' are paused", category)
+	default:
+		return ""
+	}
+	if state.Reason != "" {
+		reason += ": " + state.Reason
+	}
+	return reason
+}
+
+func (state *State) paused() bool {
+	return state.All || len(state.Categories) != 0 || len(state.Rules) != 0
+}
+
+func (state *State) String() string {
+	if !state.paused() {
+		return "the actions are not paused"
+	}
+	s := "the actions are paused"
+	if !state.All {
+		s = "the actions are not paused globally"
+	}
+	if len(state.Categories) != 0 {
+		s += fmt.Sprintf(", paused categories: %v", state.Categories)
+	}
+	if len(state.Rules) != 0 {
+		s += fmt.Sprintf(", disabled rules: %v", state.Rules)
+	}
+	return s
+}
+
+// set adds or removes the value of the sorted list.
+func set(l []string, value string, present bool) []string {
+	l = slices.DeleteFunc(l, func(s string) bool { return s == value })
+	if present {
+		l = append(l, value)
+		slices.Sort(l)
+	}
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+type fileBackend struct {
+	file string
+}
+
+func (b *fileBackend) load(context.Context) (*State, error) {
+	state := new(State)
+	if err := utils.ReadJSONFile(b.file, state); err != nil {
+		return nil, fmt.Errorf("can't read the pause file '%v': %v", b.file, err)
+	}
+	return state, nil
+}
+
+func (b *fileBackend) save(_ context.Context, state *State) error {
+	return utils.WriteJSONFile(b.file, state)
+}
+
+type configMapBackend struct {
+	client typedcorev1.ConfigMapInterface
+	// resourceVersion is the version of the ConfigMap loaded last, the saves
+	// fail with a conflict if it has been changed since.
+	resourceVersion string
+	name            string
+	exists          bool
+}
+
+func (b *configMapBackend) load(ctx context.Context) (*State, error) {
+	state := new(State)
+	cm, err := b.client.Get(ctx, b.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		b.resourceVersion, b.exists = "", false
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	b.resourceVersion, b.exists = cm.ResourceVersion, true
+	if d := cm.Data[stateKey]; d != "" {
+		if err := json.Unmarshal([]byte(d), state); err != nil {
+			return nil, fmt.Errorf("can't read the state of the ConfigMap '%v': %v", b.name, err)
+		}
+	}
+	return state, nil
+}
+
+func (b *configMapBackend) save(ctx context.Context, state *State) error {
+	d, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            b.name,
+			ResourceVersion: b.resourceVersion,
+			Labels:          map[string]string{"app.kubernetes.io/managed-by": utils.FalcoTalonStr},
+		},
+		Data: map[string]string{stateKey: string(d)},
+	}
+	if !b.exists {
+		_, err = b.client.Create(ctx, cm, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			// created by another replica in the meantime, retried as a conflict
+			return apierrors.NewConflict(corev1.Resource("configmaps"), b.name, err)
+		}
+		return err
+	}
+	_, err = b.client.Update(ctx, cm, metav1.UpdateOptions{})
+	return err
+}
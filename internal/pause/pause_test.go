@@ -0,0 +1,55 @@
+package pause
+
+import (
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTheReplicasShareThePauseThroughTheConfigMap(t *testing.T) {
+	configMaps := fake.NewSimpleClientset().CoreV1().ConfigMaps("falco")
+	first, err := newStore(&configMapBackend{client: configMaps, name: "falco-talon-pause"}, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := newStore(&configMapBackend{client: configMaps, name: "falco-talon-pause"}, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := first.Pause(Request{Category: "kubernetes", Reason: "incident", By: "alice"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the second replica changes the state without having refreshed it
+	if _, err := second.Pause(Request{Rule: "rule"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first.refresh()
+
+	for _, s := range []*Store{first, second} {
+		state := s.Get()
+		if state.All || len(state.Categories) != 1 || len(state.Rules) != 1 {
+			t.Fatalf("expected the changes of both replicas, got %+v", state)
+		}
+		if reason := state.check("other", "kubernetes"); reason != "the actions of the category 'kubernetes' are paused" {
+			t.Fatalf("unexpected reason '%v'", reason)
+		}
+		if reason := state.check("rule", "cilium"); reason != "the rule 'rule' is disabled" {
+			t.Fatalf("unexpected reason '%v'", reason)
+		}
+		if reason := state.check("other", "cilium"); reason != "" {
+			t.Fatalf("expected the action to not be paused, got '%v'", reason)
+		}
+	}
+
+	state, err := first.Resume(Request{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(state.Categories) != 0 || len(state.Rules) != 1 {
+		t.Fatalf("expected the categories to be resumed and not the rules, got %+v", state)
+	}
+	if _, err := first.Pause(Request{Category: "kubernetes", Rule: "rule"}); err != ErrInvalidRequest {
+		t.Fatalf("expected an invalid request, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+package plan
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Diff returns the YAML of the object after the change, its lines prefixed by
+// '+' if they're added, by ' ' if they're unchanged, and the lines of the
+// object before the change prefixed by '-' if they're removed. before is nil
+// for a creation.
+func Diff(before, after any) (string, error) {
+	var a, b []string
+	if before != nil {
+		y, err := yaml.Marshal(before)
+		if err != nil {
+			return "", err
+		}
+		a = lines(string(y))
+	}
+	y, err := yaml.Marshal(after)
+	if err != nil {
+		return "", err
+	}
+	b = lines(string(y))
+	return diff(a, b), nil
+}
+
+// Maps returns the changes of the keys of a map, like the labels or the
+// annotations, empty if there's none.
+func Maps(before, after map[string]string) string {
+	keys := make([]string, 0, len(before)+len(after))
+	for k := range before {
+		keys = append(keys, k)
+	}
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	var s strings.Builder
+	for _, k := range keys {
+		v1, ok1 := before[k]
+		v2, ok2 := after[k]
+		if ok1 && ok2 && v1 == v2 {
+			continue
+		}
+		if ok1 {
+			fmt.Fprintf(&s, "- %v: %v\n", k, v1)
+		}
+		if ok2 {
+			fmt.Fprintf(&s, "+ %v: %v\n", k, v2)
+		}
+	}
+	return strings.TrimSuffix(s.String(), "\n")
+}
+
+// Changed returns true if a diff returned by Diff has added or removed lines.
+func Changed(diff string) bool {
+	for _, i := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(i, "+") || strings.HasPrefix(i, "-") {
+			return true
+		}
+	}
+	return false
+}
+
+func lines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" || s == "null" || s == "{}" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// diff is a line diff based on the longest common subsequence, the objects
+// of the plans are small enough for its quadratic cost.
+func diff(a, b []string) string {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var s strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			s.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] > lcs[i+1][j]):
+			s.WriteString("+ " + b[j] + "\n")
+			j++
+		default:
+			s.WriteString("- " + a[i] + "\n")
+			i++
+		}
+	}
+	return strings.TrimSuffix(s.String(), "\n")
+}
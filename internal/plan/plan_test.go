@@ -0,0 +1,49 @@
+package plan
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	type object struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels,omitempty"`
+	}
+
+	d, err := Diff(nil, object{Name: "demo"})
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	if d != "+ name: demo" {
+		t.Fatalf("expected the object to be added, got %q", d)
+	}
+
+	d, err = Diff(object{Name: "demo", Labels: map[string]string{"a": "1", "b": "2"}}, object{Name: "demo", Labels: map[string]string{"a": "1", "b": "3"}})
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	expected := "  labels:\n    a: \"1\"\n-   b: \"2\"\n+   b: \"3\"\n  name: demo"
+	if d != expected {
+		t.Fatalf("expected the diff\n%v\ngot\n%v", expected, d)
+	}
+	if !Changed(d) {
+		t.Fatal("expected the diff to have changes")
+	}
+
+	d, err = Diff(object{Name: "demo"}, object{Name: "demo"})
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+	if Changed(d) {
+		t.Fatalf("expected no change, got %q", d)
+	}
+}
+
+func TestMaps(t *testing.T) {
+	d := Maps(map[string]string{"keep": "1", "change": "old", "remove": "x"}, map[string]string{"keep": "1", "change": "new", "add": "y"})
+	expected := "+ add: y\n- change: old\n+ change: new\n- remove: x"
+	if d != expected {
+		t.Fatalf("expected the diff\n%v\ngot\n%v", expected, d)
+	}
+	if d := Maps(map[string]string{"a": "1"}, map[string]string{"a": "1"}); d != "" {
+		t.Fatalf("expected no change, got %q", d)
+	}
+}
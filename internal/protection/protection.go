@@ -0,0 +1,332 @@
+package protection
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/events"
+	k8s "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
+	"github.com/falcosecurity/falco-talon/internal/rules"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+const (
+	podKind       string = "Pod"
+	nodeKind      string = "Node"
+	namespaceKind string = "Namespace"
+
+	deleteActionner string = "kubernetes:delete"
+	cordonActionner string = "kubernetes:cordon"
+	drainActionner  string = "kubernetes:drain"
+)
+
+// categories of the actionners which act on the pod of the event.
+var podCategories = map[string]bool{
+	"kubernetes": true,
+	"calico":     true,
+	"cilium":     true,
+}
+
+type lookup interface {
+	GetPod(pod, namespace string) (*corev1.Pod, error)
+	GetNamespace(name string) (*corev1.Namespace, error)
+	GetNode(name string) (*corev1.Node, error)
+	GetOwner(ref metav1.OwnerReference, namespace string) (metav1.Object, error)
+	GetTarget(resource, name, namespace string) (any, error)
+	ListPods(opts metav1.ListOptions) (*corev1.PodList, error)
+}
+
+// Target is an object affected by an action.
+type Target struct {
+	Labels    map[string]string
+	Kind      string
+	Name      string
+	Namespace string
+	// Owners are the kinds of the owners of the object, the closest first.
+	Owners []string
+}
+
+func (t Target) String() string {
+	if t.Namespace == "" {
+		return fmt.Sprintf("%v '%v'", t.Kind, t.Name)
+	}
+	return fmt.Sprintf("%v '%v/%v'", t.Kind, t.Namespace, t.Name)
+}
+
+// Policy is the set of the protected resources.
+type Policy struct {
+	namespaces     map[string]bool
+	ownerKinds     map[string]bool
+	labelSelectors []labels.Selector
+	nodeSelectors  []labels.Selector
+}
+
+var policy atomic.Pointer[Policy]
+
+func init() {
+	policy.Store(new(Policy))
+}
+
+// Store makes the policy the one in use.
+func Store(p *Policy) {
+	policy.Store(p)
+}
+
+func GetPolicy() *Policy {
+	return policy.Load()
+}
+
+func NewPolicy(c configuration.ProtectedResources) (*Policy, error) {
+	p := &Policy{
+		namespaces: map[string]bool{},
+		ownerKinds: map[string]bool{},
+	}
+	for _, i := range c.Namespaces {
+		p.namespaces[i] = true
+	}
+	for _, i := range c.OwnerKinds {
+		p.ownerKinds[strings.ToLower(i)] = true
+	}
+	for _, i := range c.LabelSelectors {
+		s, err := labels.Parse(i)
+		if err != nil {
+			return nil, fmt.Errorf("wrong label selector '%v': %v", i, err)
+		}
+		p.labelSelectors = append(p.labelSelectors, s)
+	}
+	for _, i := range c.NodeSelectors {
+		s, err := labels.Parse(i)
+		if err != nil {
+			return nil, fmt.Errorf("wrong node selector '%v': %v", i, err)
+		}
+		p.nodeSelectors = append(p.nodeSelectors, s)
+	}
+	return p, nil
+}
+
+// IsEmpty returns true if nothing is protected.
+func (p *Policy) IsEmpty() bool {
+	return len(p.namespaces) == 0 && len(p.ownerKinds) == 0 && len(p.labelSelectors) == 0 && len(p.nodeSelectors) == 0
+}
+
+// Check returns the reason of the veto if the action would affect a protected
+// resource, an empty string otherwise. The action is vetoed too if its targets
+// can't be resolved, the protection never fails open.
+func Check(event *events.Event, action *rules.Action) string {
+	p := GetPolicy()
+	if p.IsEmpty() {
+		return ""
+	}
+	if !affectsKubernetes(action) {
+		return ""
+	}
+	var client lookup
+	if c := k8s.GetClientFor(event); c != nil {
+		client = c
+	}
+	targets, err := p.Resolve(client, event, action)
+	if err != nil {
+		return fmt.Sprintf("can't resolve the targets of the action: %v", err)
+	}
+	return p.Veto(targets)
+}
+
+// Veto returns the reason why the targets can't be affected, an empty string
+// if none of them is protected.
+func (p *Policy) Veto(targets []Target) string {
+	for _, t := range targets {
+		if p.namespaces[t.Namespace] {
+			return fmt.Sprintf("the %v is in the protected namespace '%v'", t, t.Namespace)
+		}
+		if t.Kind == namespaceKind && p.namespaces[t.Name] {
+			return fmt.Sprintf("the %v is protected", t)
+		}
+		selectors := p.labelSelectors
+		if t.Kind == nodeKind {
+			selectors = p.nodeSelectors
+		}
+		for _, s := range selectors {
+			if s.Matches(labels.Set(t.Labels)) {
+				return fmt.Sprintf("the %v matches the protected selector '%v'", t, s)
+			}
+		}
+		if p.ownerKinds[strings.ToLower(t.Kind)] {
+			return fmt.Sprintf("the %v is of a protected kind", t)
+		}
+		for _, i := range t.Owners {
+			if p.ownerKinds[strings.ToLower(i)] {
+				return fmt.Sprintf("the %v is owned by a protected %v", t, i)
+			}
+		}
+	}
+	return ""
+}
+
+// Resolve returns the objects affected by the action: the object of the event
+// for the deletions, the node of the pod for the cordons, the node with the
+// pods it runs for the drains, the pod with its namespace and its owners for
+// the other kubernetes, calico and cilium actions. The other actions don't
+// affect any Kubernetes object. The namespaces and the owners are fetched only
+// if the policy needs them.
+func (p *Policy) Resolve(client lookup, event *events.Event, action *rules.Action) ([]Target, error) {
+	switch {
+	case action.GetActionner() == deleteActionner:
+		if client == nil {
+			return nil, errors.New("the kubernetes client is not initialized")
+		}
+		return p.resolveObject(client, event.GetTargetResource(), event.GetTargetName(), event.GetTargetNamespace())
+	case action.GetActionner() == cordonActionner, action.GetActionner() == drainActionner:
+		if client == nil {
+			return nil, errors.New("the kubernetes client is not initialized")
+		}
+		pod, err := client.GetPod(event.GetPodName(), event.GetNamespaceName())
+		if err != nil {
+			return nil, err
+		}
+		node, err := client.GetNode(pod.Spec.NodeName)
+		if err != nil {
+			return nil, err
+		}
+		targets := []Target{{Kind: nodeKind, Name: node.Name, Labels: node.Labels}}
+		if action.GetActionner() == cordonActionner {
+			return targets, nil
+		}
+		pods, err := p.resolveEvicted(client, node.Name, action)
+		if err != nil {
+			return nil, err
+		}
+		return append(targets, pods...), nil
+	case affectsKubernetes(action):
+		if event.GetPodName() == "" || event.GetNamespaceName() == "" {
+			return nil, nil
+		}
+		if client == nil {
+			return nil, errors.New("the kubernetes client is not initialized")
+		}
+		pod, err := client.GetPod(event.GetPodName(), event.GetNamespaceName())
+		if err != nil {
+			return nil, err
+		}
+		return p.resolveWithOwners(client, podKind, pod)
+	}
+	return nil, nil
+}
+
+func affectsKubernetes(action *rules.Action) bool {
+	return podCategories[action.GetActionnerCategory()]
+}
+
+func (p *Policy) resolveObject(client lookup, resource, name, namespace string) ([]Target, error) {
+	o, err := client.GetTarget(resource, name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := meta.Accessor(o)
+	if err != nil {
+		return nil, err
+	}
+	return p.resolveWithOwners(client, kindOf(o), obj)
+}
+
+// resolveEvicted returns the pods of the node evicted by the drain, with their
+// owners and their namespaces. The pods of the DaemonSets and StatefulSets
+// ignored by the parameters of the drain are skipped.
+func (p *Policy) resolveEvicted(client lookup, node string, action *rules.Action) ([]Target, error) {
+	pods, err := client.ListPods(metav1.ListOptions{FieldSelector: "spec.nodeName=" + node})
+	if err != nil {
+		return nil, err
+	}
+	ignoreDaemonSets, _ := action.GetParameters()["ignore_daemonsets"].(bool)
+	ignoreStatefulSets, _ := action.GetParameters()["ignore_statefulsets"].(bool)
+
+	targets := []Target{}
+	namespaces := map[string]bool{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		switch k8s.PodKind(*pod) {
+		case utils.DaemonSetStr:
+			if ignoreDaemonSets {
+				continue
+			}
+		case utils.StatefulSetStr:
+			if ignoreStatefulSets {
+				continue
+			}
+		}
+		t, err := p.resolveOwners(client, podKind, pod)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t...)
+		if namespaces[pod.Namespace] {
+			continue
+		}
+		namespaces[pod.Namespace] = true
+		ns, err := p.resolveNamespace(client, podKind, pod)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, ns...)
+	}
+	return targets, nil
+}
+
+func (p *Policy) resolveWithOwners(client lookup, kind string, obj metav1.Object) ([]Target, error) {
+	targets, err := p.resolveOwners(client, kind, obj)
+	if err != nil {
+		return nil, err
+	}
+	ns, err := p.resolveNamespace(client, kind, obj)
+	if err != nil {
+		return nil, err
+	}
+	return append(targets, ns...), nil
+}
+
+// resolveOwners returns the object and its owners.
+func (p *Policy) resolveOwners(client lookup, kind string, obj metav1.Object) ([]Target, error) {
+	t := Target{Kind: kind, Name: obj.GetName(), Namespace: obj.GetNamespace(), Labels: obj.GetLabels()}
+	targets := []Target{}
+
+	if len(p.ownerKinds) != 0 || len(p.labelSelectors) != 0 {
+		owners, err := k8s.ResolveOwners(client, obj.GetNamespace(), obj.GetOwnerReferences())
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range owners {
+			t.Owners = append(t.Owners, i.Kind)
+			targets = append(targets, Target{Kind: i.Kind, Name: i.Name, Namespace: i.Namespace, Labels: i.Labels})
+		}
+	}
+	return append([]Target{t}, targets...), nil
+}
+
+// resolveNamespace returns the namespace of the object, if the label selectors
+// need it.
+func (p *Policy) resolveNamespace(client lookup, kind string, obj metav1.Object) ([]Target, error) {
+	if kind == namespaceKind || obj.GetNamespace() == "" || len(p.labelSelectors) == 0 {
+		return nil, nil
+	}
+	ns, err := client.GetNamespace(obj.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+	return []Target{{Kind: namespaceKind, Name: ns.Name, Labels: ns.Labels}}, nil
+}
+
+func kindOf(o any) string {
+	t := reflect.TypeOf(o)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
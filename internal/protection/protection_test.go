@@ -0,0 +1,220 @@
+package protection
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/falcosecurity/falco-talon/configuration"
+	"github.com/falcosecurity/falco-talon/internal/events"
+	"github.com/falcosecurity/falco-talon/internal/rules"
+)
+
+type lookupStub struct {
+	namespaceCalls int
+}
+
+func (*lookupStub) GetPod(name, namespace string) (*corev1.Pod, error) {
+	controller := true
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       namespace,
+			Labels:          map[string]string{"app": name},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: name + "-rs", Controller: &controller}},
+		},
+		Spec: corev1.PodSpec{NodeName: "control-plane"},
+	}, nil
+}
+
+func (s *lookupStub) GetNamespace(name string) (*corev1.Namespace, error) {
+	s.namespaceCalls++
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"team": name}}}, nil
+}
+
+func (*lookupStub) GetNode(name string) (*corev1.Node, error) {
+	return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"node-role.kubernetes.io/control-plane": ""}}}, nil
+}
+
+func (*lookupStub) GetOwner(ref metav1.OwnerReference, namespace string) (metav1.Object, error) {
+	switch ref.Kind {
+	case "ReplicaSet":
+		return &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name:            ref.Name,
+			Namespace:       namespace,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Deployment", Name: strings.TrimSuffix(ref.Name, "-rs")}},
+		}}, nil
+	case "Deployment":
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Name:      ref.Name,
+			Namespace: namespace,
+			Labels:    map[string]string{"talon.falco.org/protected": "true"},
+		}}, nil
+	case "DaemonSet":
+		return &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: ref.Name, Namespace: namespace}}, nil
+	case "StatefulSet":
+		return &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: ref.Name, Namespace: namespace}}, nil
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (*lookupStub) GetTarget(_, name, namespace string) (any, error) {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}, nil
+}
+
+func (*lookupStub) ListPods(_ metav1.ListOptions) (*corev1.PodList, error) {
+	return &corev1.PodList{Items: []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:            "kube-proxy-x2x4",
+			Namespace:       "kube-system",
+			OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: "kube-proxy"}},
+		}},
+		{ObjectMeta: metav1.ObjectMeta{
+			Name:            "postgres-0",
+			Namespace:       "data",
+			Labels:          map[string]string{"talon.falco.org/protected": "true"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "postgres"}},
+		}},
+	}}, nil
+}
+
+func check(t *testing.T, c configuration.ProtectedResources, client lookup, action *rules.Action) string {
+	t.Helper()
+	p, err := NewPolicy(c)
+	if err != nil {
+		t.Fatalf("new policy: %v", err)
+	}
+	event := &events.Event{OutputFields: map[string]any{
+		"k8s.pod.name":        "nginx",
+		"k8s.ns.name":         "default",
+		"ka.target.resource":  "configmaps",
+		"ka.target.name":      "settings",
+		"ka.target.namespace": "kube-system",
+	}}
+	targets, err := p.Resolve(client, event, action)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	return p.Veto(targets)
+}
+
+func TestPolicyVetoesTheProtectedResources(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		config     configuration.ProtectedResources
+		parameters map[string]any
+		name       string
+		actionner  string
+		vetoed     string
+	}{
+		{
+			name:      "namespace of the pod",
+			config:    configuration.ProtectedResources{Namespaces: []string{"default"}},
+			actionner: "kubernetes:terminate",
+			vetoed:    "protected namespace 'default'",
+		},
+		{
+			name:      "namespace of the deleted object",
+			config:    configuration.ProtectedResources{Namespaces: []string{"kube-system"}},
+			actionner: "kubernetes:delete",
+			vetoed:    "ConfigMap 'kube-system/settings'",
+		},
+		{
+			name:      "label of the owner",
+			config:    configuration.ProtectedResources{LabelSelectors: []string{"talon.falco.org/protected=true"}},
+			actionner: "kubernetes:label",
+			vetoed:    "Deployment 'default/nginx'",
+		},
+		{
+			name:      "label of the namespace",
+			config:    configuration.ProtectedResources{LabelSelectors: []string{"team in (default)"}},
+			actionner: "cilium:networkpolicy",
+			vetoed:    "Namespace 'default'",
+		},
+		{
+			name:      "owner kind",
+			config:    configuration.ProtectedResources{OwnerKinds: []string{"deployment"}},
+			actionner: "kubernetes:terminate",
+			vetoed:    "owned by a protected Deployment",
+		},
+		{
+			name:      "node",
+			config:    configuration.ProtectedResources{NodeSelectors: []string{"node-role.kubernetes.io/control-plane"}},
+			actionner: "kubernetes:drain",
+			vetoed:    "Node 'control-plane'",
+		},
+		{
+			name:      "namespace of a pod of the drained node",
+			config:    configuration.ProtectedResources{Namespaces: []string{"kube-system"}},
+			actionner: "kubernetes:drain",
+			vetoed:    "Pod 'kube-system/kube-proxy-x2x4' is in the protected namespace",
+		},
+		{
+			name:      "label of a pod of the drained node",
+			config:    configuration.ProtectedResources{LabelSelectors: []string{"talon.falco.org/protected=true"}},
+			actionner: "kubernetes:drain",
+			vetoed:    "Pod 'data/postgres-0'",
+		},
+		{
+			name:      "owner kind of a pod of the drained node",
+			config:    configuration.ProtectedResources{OwnerKinds: []string{"statefulset"}},
+			actionner: "kubernetes:drain",
+			vetoed:    "owned by a protected StatefulSet",
+		},
+		{
+			name:       "pods ignored by the drain",
+			config:     configuration.ProtectedResources{Namespaces: []string{"kube-system", "data"}},
+			parameters: map[string]any{"ignore_daemonsets": true, "ignore_statefulsets": true},
+			actionner:  "kubernetes:drain",
+		},
+		{
+			name:      "the pods of the cordoned node are not affected",
+			config:    configuration.ProtectedResources{Namespaces: []string{"kube-system"}},
+			actionner: "kubernetes:cordon",
+		},
+		{
+			name:      "node selectors don't apply to the pods",
+			config:    configuration.ProtectedResources{NodeSelectors: []string{"node-role.kubernetes.io/control-plane"}},
+			actionner: "kubernetes:terminate",
+		},
+		{
+			name:      "not a kubernetes action",
+			config:    configuration.ProtectedResources{Namespaces: []string{"default"}},
+			actionner: "aws:lambda",
+		},
+	}
+
+	for _, c := range cases {
+		reason := check(t, c.config, new(lookupStub), &rules.Action{Actionner: c.actionner, Parameters: c.parameters})
+		if c.vetoed == "" && reason != "" {
+			t.Errorf("%v: expected no veto, got '%v'", c.name, reason)
+		}
+		if !strings.Contains(reason, c.vetoed) {
+			t.Errorf("%v: expected a veto with '%v', got '%v'", c.name, c.vetoed, reason)
+		}
+	}
+}
+
+func TestPolicyFetchesOnlyWhatItNeeds(t *testing.T) {
+	t.Parallel()
+
+	client := new(lookupStub)
+	if reason := check(t, configuration.ProtectedResources{Namespaces: []string{"kube-system"}}, client, &rules.Action{Actionner: "kubernetes:terminate"}); reason != "" {
+		t.Fatalf("expected no veto, got '%v'", reason)
+	}
+	if client.namespaceCalls != 0 {
+		t.Fatalf("expected the namespace to not be fetched without label selectors, got %d call(s)", client.namespaceCalls)
+	}
+
+	if _, err := NewPolicy(configuration.ProtectedResources{LabelSelectors: []string{"a=b=c"}}); err == nil {
+		t.Fatal("expected an error for a wrong selector")
+	}
+	if !new(Policy).IsEmpty() {
+		t.Fatal("expected an empty policy")
+	}
+}
@@ -0,0 +1,91 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExpandRulesFiles returns the list of the rules files for the paths of the
+// configuration. A path can be a file, a directory, where all the .yaml and
+// .yml files are used in the lexical order, or a glob pattern. The hidden entries are ignored, like
+// the '..data' folders of the Kubernetes ConfigMaps volumes.
+func ExpandRulesFiles(paths []string) ([]string, error) {
+	files := []string{}
+	seen := map[string]bool{}
+	add := func(file string) {
+		if !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+
+	for _, i := range paths {
+		if IsGlob(i) {
+			matches, err := filepath.Glob(i)
+			if err != nil {
+				return nil, fmt.Errorf("wrong pattern for the rules files '%v': %v", i, err)
+			}
+			found := false
+			for _, j := range matches {
+				if isRulesFile(j) {
+					add(j)
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("no rules file matches the pattern '%v'", i)
+			}
+			continue
+		}
+
+		info, err := os.Stat(i)
+		if err != nil || !info.IsDir() {
+			// the errors for the missing files are returned when they're read
+			add(i)
+			continue
+		}
+		entries, err := os.ReadDir(i)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, j := range entries {
+			if f := filepath.Join(i, j.Name()); isRulesFile(f) {
+				add(f)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no rules file found in the directory '%v'", i)
+		}
+	}
+
+	return files, nil
+}
+
+// IsGlob returns true if the path is a glob pattern.
+func IsGlob(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// IsRulesFileName returns true if the name of the file is the one of a rules
+// file: a visible .yaml or .yml file.
+func IsRulesFileName(name string) bool {
+	base := filepath.Base(name)
+	if strings.HasPrefix(base, ".") {
+		return false
+	}
+	ext := strings.ToLower(filepath.Ext(base))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// isRulesFile returns true for the rules files, the symlinks are followed.
+func isRulesFile(path string) bool {
+	if !IsRulesFileName(path) {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
@@ -0,0 +1,68 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestExpandRulesFiles(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+	write := func(name string) string {
+		p := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+			t.Fatalf("create dir: %v", err)
+		}
+		if err := os.WriteFile(p, []byte("[]"), 0o600); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		return p
+	}
+
+	// layout of a ConfigMap volume
+	write("configmap/..2024_01_01/a.yaml")
+	write("configmap/..2024_01_01/b.yml")
+	if err := os.Symlink("..2024_01_01", filepath.Join(tmpDir, "configmap", "..data")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	for _, i := range []string{"a.yaml", "b.yml"} {
+		if err := os.Symlink(filepath.Join("..data", i), filepath.Join(tmpDir, "configmap", i)); err != nil {
+			t.Fatalf("symlink: %v", err)
+		}
+	}
+	write("configmap/.hidden.yaml")
+	write("configmap/README.md")
+
+	single := write("single.yaml")
+	write("glob/x-rules.yaml")
+	write("glob/y-rules.yaml")
+	write("glob/other.yaml")
+
+	files, err := ExpandRulesFiles([]string{
+		filepath.Join(tmpDir, "configmap"),
+		single,
+		filepath.Join(tmpDir, "glob", "*-rules.yaml"),
+		single,
+	})
+	if err != nil {
+		t.Fatalf("expand rules files: %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(tmpDir, "configmap", "a.yaml"),
+		filepath.Join(tmpDir, "configmap", "b.yml"),
+		single,
+		filepath.Join(tmpDir, "glob", "x-rules.yaml"),
+		filepath.Join(tmpDir, "glob", "y-rules.yaml"),
+	}
+	if !slices.Equal(files, expected) {
+		t.Fatalf("expected %v, got %v", expected, files)
+	}
+
+	if _, err := ExpandRulesFiles([]string{filepath.Join(tmpDir, "glob", "*.json")}); err == nil {
+		t.Fatal("expected an error for a pattern without match")
+	}
+}
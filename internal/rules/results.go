@@ -0,0 +1,61 @@
+package rules
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/falcosecurity/falco-talon/internal/events"
+	"github.com/falcosecurity/falco-talon/internal/models"
+)
+
+// maxContextDataSize is the max size of the data of an action added in the
+// context of the next one, the bigger data are referenced by their name, size
+// and hash only.
+const maxContextDataSize int = 4 * 1024
+
+// ActionResult is the result of an action, available to the conditions and
+// in the context of the next actions of the rule.
+type ActionResult struct {
+	Objects map[string]string `json:"objects,omitempty"`
+	// Data is the artifact created by the action, it's not kept with the
+	// pending approvals.
+	Data   *models.Data `json:"-"`
+	Action string       `json:"action"`
+	Status string       `json:"status"`
+	Output string       `json:"output,omitempty"`
+	Error  string       `json:"error,omitempty"`
+}
+
+// Results are the results of the actions of a rule, by action name.
+type Results map[string]*ActionResult
+
+// AddPreviousContext adds the result of the previous action to the context of
+// the event, with the keys 'falco-talon.previous.*'. The context is replaced
+// as env vars in the commands of the actionners, see events.ExpandEnv, and is
+// sent with the event to the functions.
+func (result *ActionResult) AddPreviousContext(event *events.Event) {
+	if result == nil {
+		return
+	}
+	prefix := falcoTalonContextPrefix + "previous."
+	elements := map[string]any{
+		prefix + "action": result.Action,
+		prefix + "status": result.Status,
+		prefix + "output": result.Output,
+		prefix + "error":  result.Error,
+	}
+	for k, v := range result.Objects {
+		elements[prefix+"objects."+k] = v
+	}
+	if result.Data != nil {
+		h := sha256.Sum256(result.Data.Bytes)
+		elements[prefix+"data.name"] = result.Data.Name
+		elements[prefix+"data.size"] = len(result.Data.Bytes)
+		elements[prefix+"data.sha256"] = hex.EncodeToString(h[:])
+		if len(result.Data.Bytes) <= maxContextDataSize {
+			elements[prefix+"data"] = base64.StdEncoding.EncodeToString(result.Data.Bytes)
+		}
+	}
+	event.AddContext(elements)
+}
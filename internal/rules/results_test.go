@@ -0,0 +1,52 @@
+package rules
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/falcosecurity/falco-talon/internal/events"
+	"github.com/falcosecurity/falco-talon/internal/models"
+)
+
+func TestAddPreviousContext(t *testing.T) {
+	t.Parallel()
+
+	event := new(events.Event)
+	result := &ActionResult{
+		Action:  "Download",
+		Status:  "success",
+		Output:  "file downloaded",
+		Objects: map[string]string{"pod": "nginx"},
+		Data:    &models.Data{Name: "passwd", Bytes: []byte("root:x:0:0")},
+	}
+	result.AddPreviousContext(event)
+
+	expected := map[string]any{
+		"falco-talon.previous.action":      "Download",
+		"falco-talon.previous.status":      "success",
+		"falco-talon.previous.output":      "file downloaded",
+		"falco-talon.previous.objects.pod": "nginx",
+		"falco-talon.previous.data.name":   "passwd",
+		"falco-talon.previous.data.size":   10,
+		"falco-talon.previous.data.sha256": "89ef98f859f634507b321f5540785bedb26696c45ab130a70d9c61655ddfc5cc",
+		"falco-talon.previous.data":        "cm9vdDp4OjA6MA==",
+	}
+	for k, v := range expected {
+		if event.Context[k] != v {
+			t.Errorf("expected '%v' for '%v', got '%v'", v, k, event.Context[k])
+		}
+	}
+	if _, ok := event.Context["falco-talon.previous.error"]; ok {
+		t.Error("expected no empty error in the context")
+	}
+
+	event = new(events.Event)
+	result.Data.Bytes = bytes.Repeat([]byte("a"), maxContextDataSize+1)
+	result.AddPreviousContext(event)
+	if _, ok := event.Context["falco-talon.previous.data"]; ok {
+		t.Error("expected the big data to be referenced only")
+	}
+	if event.Context["falco-talon.previous.data.size"] != maxContextDataSize+1 {
+		t.Errorf("expected the size of the big data, got %v", event.Context["falco-talon.previous.data.size"])
+	}
+}
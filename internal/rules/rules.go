@@ -7,7 +7,10 @@ import (
 	"os"
 	"reflect"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	yaml "gopkg.in/yaml.v3"
 
@@ -24,16 +27,65 @@ type Action struct {
 	Continue           string         `yaml:"continue,omitempty"`      // can't be a bool because an omitted value == false by default
 	IgnoreErrors       string         `yaml:"ignore_errors,omitempty"` // can't be a bool because an omitted value == false by default
 	AdditionalContexts []string       `yaml:"additional_contexts,omitempty"`
+	RequireApproval    *Approval      `yaml:"require_approval,omitempty"`
+	Delay              string         `yaml:"delay,omitempty"`
+	When               string         `yaml:"when,omitempty"`
+	OnFailure          []*Action      `yaml:"on_failure,omitempty"`
+	// Parallel is set for a parallel group in the rules files only, the
+	// groups are replaced by their actions when the rules are loaded.
+	Parallel  *Parallel `yaml:"parallel,omitempty"`
+	Location  Location  `yaml:"-"`
+	condition *Condition
+	// group is the parallel group of the action, nil if it runs in sequence.
+	group *Parallel
+	// step is the escalation step of the action, nil if the rule has no
+	// escalation.
+	step *EscalationStep
+}
+
+// Parallel is a group of actions of a rule run concurrently, the chain
+// continues when all of them are done or when the timeout is reached.
+type Parallel struct {
+	Timeout  string    `yaml:"timeout,omitempty"`
+	Actions  []*Action `yaml:"actions"`
+	Location Location  `yaml:"-"`
+}
+
+// Approval is the setting of an action which has to be approved by a human
+// before being run. Without decision before the timeout, the default decision
+// is applied.
+type Approval struct {
+	Timeout         string `yaml:"timeout,omitempty"`
+	DefaultDecision string `yaml:"default,omitempty"`
+}
+
+// Escalation replaces the actions of a rule by graduated steps, the step run
+// for an event depends on the number of offenses counted for its key within
+// the decay.
+type Escalation struct {
+	Key   string            `yaml:"key,omitempty"`
+	Decay string            `yaml:"decay,omitempty"`
+	Steps []*EscalationStep `yaml:"steps"`
+}
+
+// EscalationStep is the list of actions run from the offense number Offense.
+type EscalationStep struct {
+	Actions  []*Action `yaml:"actions"`
+	Offense  int       `yaml:"offense"`
+	Location Location  `yaml:"-"`
 }
 
 type Rule struct {
-	Name        string    `yaml:"rule"`
-	Description string    `yaml:"description"`
-	Continue    string    `yaml:"continue"`          // can't be a bool because an omitted value == false by default
-	DryRun      string    `yaml:"dry_run,omitempty"` // can't be a bool because an omitted value == false by default
-	Actions     []*Action `yaml:"actions"`
-	Notifiers   []string  `yaml:"notifiers"`
-	Match       Match     `yaml:"match"`
+	Escalation  *Escalation `yaml:"escalation,omitempty"`
+	Name        string      `yaml:"rule"`
+	Description string      `yaml:"description"`
+	Continue    string      `yaml:"continue"`          // can't be a bool because an omitted value == false by default
+	DryRun      string      `yaml:"dry_run,omitempty"` // can't be a bool because an omitted value == false by default
+	Actions     []*Action   `yaml:"actions"`
+	Finally     []*Action   `yaml:"finally,omitempty"`
+	Notifiers   []string    `yaml:"notifiers"`
+	Match       Match       `yaml:"match"`
+	Location    Location    `yaml:"-"`
 }
 
 type Match struct {
@@ -53,6 +105,13 @@ type Output struct {
 	Target     string         `yaml:"target"`
 }
 
+// Location is the position of an item in the rules files.
+type Location struct {
+	File   string
+	Line   int
+	Column int
+}
+
 type outputfield struct {
 	Key        string
 	Comparator string
@@ -67,9 +126,24 @@ const (
 	operatorNotEqual        string = "!="
 	errMismatchParamType    string = "mismatch of type for a parameter"
 	errContinueSetting      string = "'continue' setting can be 'true' or 'false' only"
+
+	ApproveStr             string        = "approve"
+	DenyStr                string        = "deny"
+	defaultApprovalTimeout time.Duration = time.Hour
+	defaultParallelTimeout time.Duration = 5 * time.Minute
+
+	WorkloadStr            string        = "workload"
+	NamespaceStr           string        = "namespace"
+	NodeStr                string        = "node"
+	defaultEscalationDecay time.Duration = time.Hour
 )
 
-var rules *[]*Rule
+// diagnostics lists the errors found during the last parsing of the rules.
+var diagnostics []utils.LogLine
+
+// overrideMismatches lists the overrides of parameters ignored during the last
+// parsing because their type differs from the one of the overridden value.
+var overrideMismatches []utils.LogLine
 
 var (
 	priorityCheckRegex       *regexp.Regexp
@@ -86,106 +160,135 @@ func init() {
 	tagCheckRegex = regexp.MustCompile(`(?i)^[a-z_0-9.]*[a-z0-9]$`)
 	outputFieldKeyCheckRegex = regexp.MustCompile(`(?i)^[a-z0-9.\[\]]*(!)?(=)`)
 
-	rules = new([]*Rule)
+	ruleSet.Store(&RuleSet{Rules: []*Rule{}})
 }
 
 func ParseRules(files []string) *[]*Rule {
+	r := LoadRules(files)
+	if r == nil {
+		return nil
+	}
+
+	SetRules(r)
+
+	return r
+}
+
+// LoadRules parses and checks the rules files, without replacing the current
+// rules, it returns nil if they're not valid.
+func LoadRules(files []string) *[]*Rule {
+	diagnostics = nil
+	overrideMismatches = nil
 	a, r, err := extractActionsRules(files)
 	if err != nil {
-		utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: rulesStr})
+		log := utils.LogLine{Error: err.Error(), Message: rulesStr}
+		var errFile *fileError
+		if errors.As(err, &errFile) {
+			log.Location = errFile.location.String()
+		}
+		addDiagnostic(log)
 		return nil
 	}
 
 	for _, rule := range *r {
-		for n := range rule.Actions {
+		for _, i := range rule.ListAllActions() {
 			for _, action := range *a {
-				if rule.Actions[n].Name == action.Name {
-					if rule.Actions[n].Description == "" && action.Description != "" {
-						rule.Actions[n].Description = action.Description
+				if i.Name == action.Name {
+					if i.Description == "" && action.Description != "" {
+						i.Description = action.Description
 					}
-					if rule.Actions[n].Actionner == "" && action.Actionner != "" {
-						rule.Actions[n].Actionner = action.Actionner
+					if i.Actionner == "" && action.Actionner != "" {
+						i.Actionner = action.Actionner
 					}
-					if rule.Actions[n].IgnoreErrors == "" && action.IgnoreErrors != "" {
-						rule.Actions[n].IgnoreErrors = action.IgnoreErrors
+					if i.IgnoreErrors == "" && action.IgnoreErrors != "" {
+						i.IgnoreErrors = action.IgnoreErrors
 					}
-					if rule.Actions[n].Continue == "" && action.Continue != "" {
-						rule.Actions[n].Continue = action.Continue
+					if i.Continue == "" && action.Continue != "" {
+						i.Continue = action.Continue
 					}
-					if len(rule.Actions[n].AdditionalContexts) == 0 && len(action.AdditionalContexts) != 0 {
-						rule.Actions[n].AdditionalContexts = make([]string, len(action.AdditionalContexts))
-						rule.Actions[n].AdditionalContexts = action.AdditionalContexts
+					if i.RequireApproval == nil && action.RequireApproval != nil {
+						i.RequireApproval = action.RequireApproval
 					}
-					if rule.Actions[n].Parameters == nil && len(action.Parameters) != 0 {
-						rule.Actions[n].Parameters = make(map[string]any)
+					if i.Delay == "" && action.Delay != "" {
+						i.Delay = action.Delay
+					}
+					if i.When == "" && action.When != "" {
+						i.When = action.When
+					}
+					if len(i.AdditionalContexts) == 0 && len(action.AdditionalContexts) != 0 {
+						i.AdditionalContexts = make([]string, len(action.AdditionalContexts))
+						i.AdditionalContexts = action.AdditionalContexts
+					}
+					if i.Parameters == nil && len(action.Parameters) != 0 {
+						i.Parameters = make(map[string]any)
 					}
 					for k, v := range action.Parameters {
 						rt := reflect.TypeOf(v)
-						ru := reflect.TypeOf(rule.Actions[n].Parameters[k])
+						ru := reflect.TypeOf(i.Parameters[k])
 						if v == nil {
 							continue
 						}
-						if rule.Actions[n].Parameters[k] != nil && ru.Kind() != rt.Kind() {
-							utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: errMismatchParamType, Message: rulesStr, Rule: rule.GetName(), Action: action.GetName()})
+						if i.Parameters[k] != nil && ru.Kind() != rt.Kind() {
+							addOverrideMismatch(utils.LogLine{Error: fmt.Sprintf("%v '%v'", errMismatchParamType, k), Message: rulesStr, Rule: rule.GetName(), Action: action.GetName(), Location: i.Location.String()})
 							continue
 						}
 						switch rt.Kind() {
 						case reflect.Slice, reflect.Array:
 							w := v
-							if rule.Actions[n].Parameters[k] == nil {
-								rule.Actions[n].Parameters[k] = []any{w}
+							if i.Parameters[k] == nil {
+								i.Parameters[k] = []any{w}
 							} else {
-								w = append(w.([]any), rule.Actions[n].Parameters[k].([]any)...)
+								w = append(w.([]any), i.Parameters[k].([]any)...)
 							}
-							rule.Actions[n].Parameters[k] = w
+							i.Parameters[k] = w
 						case reflect.Map:
 							for s, t := range v.(map[string]any) {
-								if rule.Actions[n].Parameters[k] == nil {
-									rule.Actions[n].Parameters[k] = make(map[string]any)
+								if i.Parameters[k] == nil {
+									i.Parameters[k] = make(map[string]any)
 								}
-								rule.Actions[n].Parameters[k].(map[string]any)[s] = t
+								i.Parameters[k].(map[string]any)[s] = t
 							}
 						default:
-							if rule.Actions[n].Parameters[k] == nil {
-								rule.Actions[n].Parameters[k] = v
+							if i.Parameters[k] == nil {
+								i.Parameters[k] = v
 							}
 						}
 					}
-					if rule.Actions[n].Output.Target == "" && action.Output.Target != "" {
-						rule.Actions[n].Output.Target = action.Output.Target
+					if i.Output.Target == "" && action.Output.Target != "" {
+						i.Output.Target = action.Output.Target
 					}
 					for k, v := range action.Output.Parameters {
 						rt := reflect.TypeOf(v)
-						ru := reflect.TypeOf(rule.Actions[n].Output.Parameters[k])
+						ru := reflect.TypeOf(i.Output.Parameters[k])
 						if v == nil {
 							continue
 						}
-						if rule.Actions[n].Output.Parameters[k] != nil && ru.Kind() != rt.Kind() {
-							utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: errMismatchParamType, Message: rulesStr, Rule: rule.GetName(), Action: action.GetName(), OutputTarget: action.Output.GetTarget()})
+						if i.Output.Parameters[k] != nil && ru.Kind() != rt.Kind() {
+							addOverrideMismatch(utils.LogLine{Error: fmt.Sprintf("%v '%v'", errMismatchParamType, k), Message: rulesStr, Rule: rule.GetName(), Action: action.GetName(), OutputTarget: action.Output.GetTarget(), Location: i.Location.String()})
 							continue
 						}
 						switch rt.Kind() {
 						case reflect.Slice, reflect.Array:
 							w := v
-							if rule.Actions[n].Output.Parameters[k] == nil {
-								rule.Actions[n].Output.Parameters[k] = []any{w}
+							if i.Output.Parameters[k] == nil {
+								i.Output.Parameters[k] = []any{w}
 							} else {
-								w = append(w.([]any), rule.Actions[n].Output.Parameters[k].([]any)...)
+								w = append(w.([]any), i.Output.Parameters[k].([]any)...)
 							}
-							rule.Actions[n].Output.Parameters[k] = w
+							i.Output.Parameters[k] = w
 						case reflect.Map:
 							for s, t := range v.(map[string]any) {
-								if rule.Actions[n].Output.Parameters[k] == nil {
-									rule.Actions[n].Output.Parameters[k] = make(map[string]any)
+								if i.Output.Parameters[k] == nil {
+									i.Output.Parameters[k] = make(map[string]any)
 								}
-								rule.Actions[n].Output.Parameters[k].(map[string]any)[s] = t
+								i.Output.Parameters[k].(map[string]any)[s] = t
 							}
 						default:
-							if rule.Actions[n].Output.Parameters[k] == nil {
-								if rule.Actions[n].Output.Parameters == nil {
-									rule.Actions[n].Output.Parameters = make(map[string]any)
+							if i.Output.Parameters[k] == nil {
+								if i.Output.Parameters == nil {
+									i.Output.Parameters = make(map[string]any)
 								}
-								rule.Actions[n].Output.Parameters[k] = v
+								i.Output.Parameters[k] = v
 							}
 						}
 					}
@@ -228,12 +331,14 @@ func ParseRules(files []string) *[]*Rule {
 		return nil
 	}
 
-	rules = r
-
-	return rules
+	return r
 }
 
-func extractActionsRules(files []string) (*[]*Action, *[]*Rule, error) {
+func extractActionsRules(paths []string) (*[]*Action, *[]*Rule, error) {
+	files, err := ExpandRulesFiles(paths)
+	if err != nil {
+		return nil, nil, err
+	}
 	if len(files) == 0 {
 		return nil, nil, errors.New("no rule file is provided")
 	}
@@ -250,10 +355,26 @@ func extractActionsRules(files []string) (*[]*Action, *[]*Rule, error) {
 		}
 
 		if err := yaml.Unmarshal(f, &at); err != nil {
-			return nil, nil, fmt.Errorf("wrong syntax for the rule file '%v': %v", i, err.Error())
+			return nil, nil, newFileError(i, err)
 		}
 		if err := yaml.Unmarshal(f, &rt); err != nil {
-			return nil, nil, fmt.Errorf("wrong syntax for the rule file '%v': %v", i, err.Error())
+			return nil, nil, newFileError(i, err)
+		}
+
+		for _, j := range at {
+			j.Location.File = i
+		}
+		for _, j := range rt {
+			j.Location.File = i
+			for _, k := range j.ListAllActions() {
+				k.Location.File = i
+				if k.group != nil {
+					k.group.Location.File = i
+				}
+				if k.step != nil {
+					k.step.Location.File = i
+				}
+			}
 		}
 
 		a = append(a, at...)
@@ -281,6 +402,15 @@ func extractActionsRules(files []string) (*[]*Action, *[]*Rule, error) {
 				if l.IgnoreErrors != "" {
 					i.IgnoreErrors = l.IgnoreErrors
 				}
+				if l.RequireApproval != nil {
+					i.RequireApproval = l.RequireApproval
+				}
+				if l.Delay != "" {
+					i.Delay = l.Delay
+				}
+				if l.When != "" {
+					i.When = l.When
+				}
 				if i.Output.Target == "" && l.Output.Target != "" {
 					i.Output.Target = l.Output.Target
 				}
@@ -298,6 +428,7 @@ func extractActionsRules(files []string) (*[]*Action, *[]*Rule, error) {
 						continue
 					}
 					if i.Parameters[k] != nil && ru.Kind() != rt.Kind() {
+						addOverrideMismatch(utils.LogLine{Error: fmt.Sprintf("%v '%v'", errMismatchParamType, k), Message: rulesStr, Action: i.GetName(), Location: l.Location.String()})
 						continue
 					}
 					switch rt.Kind() {
@@ -325,6 +456,7 @@ func extractActionsRules(files []string) (*[]*Action, *[]*Rule, error) {
 						continue
 					}
 					if i.Output.Parameters[k] != nil && ru.Kind() != rt.Kind() {
+						addOverrideMismatch(utils.LogLine{Error: fmt.Sprintf("%v '%v'", errMismatchParamType, k), Message: rulesStr, Action: i.GetName(), OutputTarget: i.Output.GetTarget(), Location: l.Location.String()})
 						continue
 					}
 					switch rt.Kind() {
@@ -374,7 +506,14 @@ func extractActionsRules(files []string) (*[]*Action, *[]*Rule, error) {
 				i.Match.Source = l.Match.Source
 				i.Match.Rules = append(i.Match.Rules, l.Match.Rules...)
 				i.Match.Tags = append(i.Match.Tags, l.Match.Tags...)
-				i.Actions = append(i.Actions, l.Actions...)
+				if l.Escalation != nil {
+					// the actions are the ones of the steps, replaced too
+					i.Escalation = l.Escalation
+					i.Actions = l.Actions
+				} else {
+					i.Actions = append(i.Actions, l.Actions...)
+				}
+				i.Finally = append(i.Finally, l.Finally...)
 				l.Name = ""
 			}
 		}
@@ -397,60 +536,111 @@ func extractActionsRules(files []string) (*[]*Action, *[]*Rule, error) {
 	return &af, &rf, nil
 }
 
+func addDiagnostic(log utils.LogLine) {
+	utils.PrintLog(utils.ErrorStr, log)
+	diagnostics = append(diagnostics, log)
+}
+
+// GetDiagnostics returns the errors found during the last parsing of the rules.
+func GetDiagnostics() []utils.LogLine {
+	return diagnostics
+}
+
+func addOverrideMismatch(log utils.LogLine) {
+	utils.PrintLog(utils.ErrorStr, log)
+	overrideMismatches = append(overrideMismatches, log)
+}
+
+// GetOverrideMismatches returns the overrides of parameters which have been
+// ignored during the last parsing of the rules, because of a mismatch of type.
+func GetOverrideMismatches() []utils.LogLine {
+	return overrideMismatches
+}
+
 func (rule *Rule) isValid() bool {
 	valid := true
 	if rule.Name == "" {
-		utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: "all rules must have a name", Message: rulesStr})
+		addDiagnostic(utils.LogLine{Error: "all rules must have a name", Message: rulesStr, Location: rule.Location.String()})
 		valid = false
 	}
 	if rule.Continue != "" && rule.Continue != trueStr && rule.Continue != falseStr {
-		utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: errContinueSetting, Message: rulesStr, Rule: rule.Name})
+		addDiagnostic(utils.LogLine{Error: errContinueSetting, Message: rulesStr, Rule: rule.Name, Location: rule.Location.String()})
 		valid = false
 	}
 	if rule.DryRun != "" && rule.DryRun != trueStr && rule.DryRun != falseStr {
-		utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: "'dry_run' setting can be 'true' or 'false' only", Message: rulesStr, Rule: rule.Name})
+		addDiagnostic(utils.LogLine{Error: "'dry_run' setting can be 'true' or 'false' only", Message: rulesStr, Rule: rule.Name, Location: rule.Location.String()})
 		valid = false
 	}
 	if len(rule.Actions) == 0 {
-		utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: "no action specified", Message: rulesStr, Rule: rule.Name})
+		addDiagnostic(utils.LogLine{Error: "no action specified", Message: rulesStr, Rule: rule.Name, Location: rule.Location.String()})
 		valid = false
 	}
-	if len(rule.Actions) != 0 {
-		for _, i := range rule.Actions {
-			if i.Name == "" {
-				utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: "action without a name", Message: rulesStr, Rule: rule.Name})
-				valid = false
-			}
-			if i.Actionner == "" {
-				utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: "missing actionner", Message: rulesStr, Action: i.Name, Rule: rule.Name})
-				valid = false
-			}
-			if !actionCheckRegex.MatchString(i.Actionner) {
-				utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: "incorrect actionner", Message: rulesStr, Action: i.Name, Actionner: i.Actionner, Rule: rule.Name})
-				valid = false
-			}
-			if i.Continue != "" && i.Continue != trueStr && i.Continue != falseStr {
-				utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: errContinueSetting, Message: rulesStr, Action: i.Name, Actionner: i.Actionner, Rule: rule.Name})
-				valid = false
-			}
-			if i.IgnoreErrors != "" && i.IgnoreErrors != trueStr && i.IgnoreErrors != falseStr {
-				utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: "'ignore_errors' setting can be 'true' or 'false' only", Message: rulesStr, Action: i.Name, Actionner: i.Actionner, Rule: rule.Name})
-				valid = false
-			}
-			if i.Output.Target != "" && len(i.Output.Parameters) == 0 {
-				utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: "missing 'parameters' for the output", Message: rulesStr, Action: i.Name, Actionner: i.Actionner, Rule: rule.Name, OutputTarget: i.Output.Target})
+	if rule.Escalation != nil {
+		if err := rule.Escalation.check(); err != nil {
+			addDiagnostic(utils.LogLine{Error: err.Error(), Message: rulesStr, Rule: rule.Name, Location: rule.Location.String()})
+			valid = false
+		}
+		if slices.ContainsFunc(rule.Actions, func(a *Action) bool { return a.step == nil }) {
+			addDiagnostic(utils.LogLine{Error: "a rule with an escalation can't have actions out of its steps", Message: rulesStr, Rule: rule.Name, Location: rule.Location.String()})
+			valid = false
+		}
+	}
+	for n, i := range rule.Actions {
+		first := n
+		for first > 0 && i.group != nil && rule.Actions[first-1].group == i.group {
+			first--
+		}
+		// the actions of the other escalation steps are never run with it
+		begin := first
+		for begin > 0 && rule.Actions[begin-1].step == i.step {
+			begin--
+		}
+		if i.group != nil && first == n {
+			if err := i.group.check(); err != nil {
+				addDiagnostic(utils.LogLine{Error: err.Error(), Message: rulesStr, Rule: rule.Name, Location: i.group.Location.String()})
 				valid = false
 			}
 		}
+		if i.group != nil && i.RequireApproval != nil {
+			addDiagnostic(utils.LogLine{Error: "an action of a parallel group can't require an approval", Message: rulesStr, Action: i.Name, Actionner: i.Actionner, Rule: rule.Name, Location: i.Location.String()})
+			valid = false
+		}
+		if i.group != nil && i.Delay != "" {
+			addDiagnostic(utils.LogLine{Error: "an action of a parallel group can't be delayed", Message: rulesStr, Action: i.Name, Actionner: i.Actionner, Rule: rule.Name, Location: i.Location.String()})
+			valid = false
+		}
+		if i.group != nil && len(i.OnFailure) != 0 {
+			addDiagnostic(utils.LogLine{Error: "an action of a parallel group can't have 'on_failure' actions", Message: rulesStr, Action: i.Name, Actionner: i.Actionner, Rule: rule.Name, Location: i.Location.String()})
+			valid = false
+		}
+		// the actions of the same parallel group can't be referenced
+		if !rule.isValidAction(i, listActions(rule.Actions[begin:first])) {
+			valid = false
+		}
+	}
+	previous := listActions(rule.Actions)
+	for _, i := range rule.Finally {
+		if i.RequireApproval != nil {
+			addDiagnostic(utils.LogLine{Error: "a 'finally' action can't require an approval", Message: rulesStr, Action: i.Name, Actionner: i.Actionner, Rule: rule.Name, Location: i.Location.String()})
+			valid = false
+		}
+		if i.Delay != "" {
+			addDiagnostic(utils.LogLine{Error: "a 'finally' action can't be delayed", Message: rulesStr, Action: i.Name, Actionner: i.Actionner, Rule: rule.Name, Location: i.Location.String()})
+			valid = false
+		}
+		if !rule.isValidAction(i, previous) {
+			valid = false
+		}
+		previous = append(previous, listActions([]*Action{i})...)
 	}
 	if !priorityCheckRegex.MatchString(rule.Match.Priority) {
-		utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: fmt.Sprintf("incorrect priority '%v'", rule.Match.Priority), Message: rulesStr, Rule: rule.Name})
+		addDiagnostic(utils.LogLine{Error: fmt.Sprintf("incorrect priority '%v'", rule.Match.Priority), Message: rulesStr, Rule: rule.Name, Location: rule.Location.String()})
 		valid = false
 	}
 	for _, i := range rule.Match.TagsC {
 		for _, j := range i {
 			if !tagCheckRegex.MatchString(j) {
-				utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: fmt.Sprintf("incorrect tag '%v'", j), Message: rulesStr, Rule: rule.Name})
+				addDiagnostic(utils.LogLine{Error: fmt.Sprintf("incorrect tag '%v'", j), Message: rulesStr, Rule: rule.Name, Location: rule.Location.String()})
 				valid = false
 			}
 		}
@@ -459,18 +649,88 @@ func (rule *Rule) isValid() bool {
 		t := strings.Split(strings.ReplaceAll(i, ", ", ","), ",")
 		for _, j := range t {
 			if !outputFieldKeyCheckRegex.MatchString(j) {
-				utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: fmt.Sprintf("incorrect output field key '%v'", j), Message: rulesStr, Rule: rule.Name})
+				addDiagnostic(utils.LogLine{Error: fmt.Sprintf("incorrect output field key '%v'", j), Message: rulesStr, Rule: rule.Name, Location: rule.Location.String()})
 				valid = false
 			}
 		}
 	}
 	if err := rule.setPriorityNumberComparator(); err != nil {
-		utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: fmt.Sprintf("incorrect priority comparator '%v'", rule.Match.PriorityComparator), Message: rulesStr, Rule: rule.Name})
+		addDiagnostic(utils.LogLine{Error: fmt.Sprintf("incorrect priority comparator '%v'", rule.Match.PriorityComparator), Message: rulesStr, Rule: rule.Name, Location: rule.Location.String()})
 		valid = false
 	}
 	return valid
 }
 
+// isValidAction checks an action of the rule and its 'on_failure' actions,
+// previous are the actions its 'when' condition can reference.
+func (rule *Rule) isValidAction(i *Action, previous []*Action) bool {
+	valid := true
+	if i.Parallel != nil {
+		addDiagnostic(utils.LogLine{Error: "the parallel groups are allowed in the actions of the rule only", Message: rulesStr, Rule: rule.Name, Location: i.Location.String()})
+		return false
+	}
+	if i.Name == "" {
+		addDiagnostic(utils.LogLine{Error: "action without a name", Message: rulesStr, Rule: rule.Name, Location: i.Location.String()})
+		valid = false
+	}
+	if i.Actionner == "" {
+		addDiagnostic(utils.LogLine{Error: "missing actionner", Message: rulesStr, Action: i.Name, Rule: rule.Name, Location: i.Location.String()})
+		valid = false
+	}
+	if !actionCheckRegex.MatchString(i.Actionner) {
+		addDiagnostic(utils.LogLine{Error: "incorrect actionner", Message: rulesStr, Action: i.Name, Actionner: i.Actionner, Rule: rule.Name, Location: i.Location.String()})
+		valid = false
+	}
+	if i.Continue != "" && i.Continue != trueStr && i.Continue != falseStr {
+		addDiagnostic(utils.LogLine{Error: errContinueSetting, Message: rulesStr, Action: i.Name, Actionner: i.Actionner, Rule: rule.Name, Location: i.Location.String()})
+		valid = false
+	}
+	if i.IgnoreErrors != "" && i.IgnoreErrors != trueStr && i.IgnoreErrors != falseStr {
+		addDiagnostic(utils.LogLine{Error: "'ignore_errors' setting can be 'true' or 'false' only", Message: rulesStr, Action: i.Name, Actionner: i.Actionner, Rule: rule.Name, Location: i.Location.String()})
+		valid = false
+	}
+	if i.Output.Target != "" && len(i.Output.Parameters) == 0 {
+		addDiagnostic(utils.LogLine{Error: "missing 'parameters' for the output", Message: rulesStr, Action: i.Name, Actionner: i.Actionner, Rule: rule.Name, OutputTarget: i.Output.Target, Location: i.Location.String()})
+		valid = false
+	}
+	if i.RequireApproval != nil {
+		if err := i.RequireApproval.check(); err != nil {
+			addDiagnostic(utils.LogLine{Error: err.Error(), Message: rulesStr, Action: i.Name, Actionner: i.Actionner, Rule: rule.Name, Location: i.Location.String()})
+			valid = false
+		}
+	}
+	if i.Delay != "" {
+		if d, err := time.ParseDuration(i.Delay); err != nil || d <= 0 {
+			addDiagnostic(utils.LogLine{Error: fmt.Sprintf("incorrect delay '%v'", i.Delay), Message: rulesStr, Action: i.Name, Actionner: i.Actionner, Rule: rule.Name, Location: i.Location.String()})
+			valid = false
+		}
+	}
+	if i.When != "" {
+		if err := i.setCondition(previous); err != nil {
+			addDiagnostic(utils.LogLine{Error: err.Error(), Message: rulesStr, Action: i.Name, Actionner: i.Actionner, Rule: rule.Name, Location: i.Location.String()})
+			valid = false
+		}
+	}
+	// the 'on_failure' actions can reference the failed action and the
+	// 'on_failure' actions before them
+	previous = append(slices.Clone(previous), i)
+	for _, j := range i.OnFailure {
+		if j.RequireApproval != nil {
+			addDiagnostic(utils.LogLine{Error: "an 'on_failure' action can't require an approval", Message: rulesStr, Action: j.Name, Actionner: j.Actionner, Rule: rule.Name, Location: j.Location.String()})
+			valid = false
+		}
+		if j.Delay != "" {
+			addDiagnostic(utils.LogLine{Error: "an 'on_failure' action can't be delayed", Message: rulesStr, Action: j.Name, Actionner: j.Actionner, Rule: rule.Name, Location: j.Location.String()})
+			valid = false
+		}
+		if !rule.isValidAction(j, previous) {
+			valid = false
+		}
+		previous = append(previous, listActions([]*Action{j})...)
+	}
+	return valid
+}
+
 func (rule *Rule) setPriorityNumberComparator() error {
 	if rule.Match.Priority == "" {
 		return nil
@@ -480,8 +740,235 @@ func (rule *Rule) setPriorityNumberComparator() error {
 	return nil
 }
 
-func GetRules() *[]*Rule {
-	return rules
+func (location Location) String() string {
+	switch {
+	case location.File == "":
+		return ""
+	case location.Line == 0:
+		return location.File
+	case location.Column == 0:
+		return fmt.Sprintf("%v:%v", location.File, location.Line)
+	default:
+		return fmt.Sprintf("%v:%v:%v", location.File, location.Line, location.Column)
+	}
+}
+
+// UnmarshalYAML keeps the position of the action in the rules file.
+func (action *Action) UnmarshalYAML(node *yaml.Node) error {
+	type plain Action
+	if err := node.Decode((*plain)(action)); err != nil {
+		return err
+	}
+	action.Location = Location{Line: node.Line, Column: node.Column}
+	return nil
+}
+
+// UnmarshalYAML keeps the position of the rule in the rules file and replaces
+// the parallel groups and the escalation steps by their actions.
+func (rule *Rule) UnmarshalYAML(node *yaml.Node) error {
+	type plain Rule
+	if err := node.Decode((*plain)(rule)); err != nil {
+		return err
+	}
+	rule.Location = Location{Line: node.Line, Column: node.Column}
+	actions, err := expandParallelGroups(rule.Actions)
+	if err != nil {
+		return err
+	}
+	rule.Actions = actions
+	return rule.expandEscalationSteps()
+}
+
+// UnmarshalYAML keeps the position of the step in the rules file.
+func (step *EscalationStep) UnmarshalYAML(node *yaml.Node) error {
+	type plain EscalationStep
+	if err := node.Decode((*plain)(step)); err != nil {
+		return err
+	}
+	step.Location = Location{Line: node.Line, Column: node.Column}
+	return nil
+}
+
+// expandEscalationSteps replaces the actions of the rule by the ones of the
+// escalation steps, which keep a reference to their step. The actions of a
+// step are contiguous, in the order of the steps.
+func (rule *Rule) expandEscalationSteps() error {
+	if rule.Escalation == nil {
+		return nil
+	}
+	if len(rule.Actions) != 0 {
+		return fmt.Errorf("line %v: a rule with an escalation can't have actions out of its steps", rule.Location.Line)
+	}
+	for _, i := range rule.Escalation.Steps {
+		if len(i.Actions) == 0 {
+			return fmt.Errorf("line %v: the escalation step has no action", i.Location.Line)
+		}
+		actions, err := expandParallelGroups(i.Actions)
+		if err != nil {
+			return err
+		}
+		for _, j := range actions {
+			j.step = i
+		}
+		rule.Actions = append(rule.Actions, actions...)
+	}
+	return nil
+}
+
+// expandParallelGroups replaces the parallel groups by their actions, which
+// keep a reference to their group. The errors mention the line, to be located
+// like the syntax errors.
+func expandParallelGroups(l []*Action) ([]*Action, error) {
+	actions := make([]*Action, 0, len(l))
+	for _, i := range l {
+		if i.Parallel == nil {
+			actions = append(actions, i)
+			continue
+		}
+		if i.Name != "" || i.Actionner != "" {
+			return nil, fmt.Errorf("line %v: a parallel group can't have the settings of an action", i.Location.Line)
+		}
+		if len(i.Parallel.Actions) == 0 {
+			return nil, fmt.Errorf("line %v: the parallel group has no action", i.Location.Line)
+		}
+		i.Parallel.Location = i.Location
+		for _, j := range i.Parallel.Actions {
+			if j.Parallel != nil {
+				return nil, fmt.Errorf("line %v: the parallel groups can't be nested", j.Location.Line)
+			}
+			j.group = i.Parallel
+			actions = append(actions, j)
+		}
+	}
+	return actions, nil
+}
+
+// fileError is an error of syntax in a rules file.
+type fileError struct {
+	err      error
+	location Location
+}
+
+var yamlLineRegex = regexp.MustCompile(`line (\d+)`)
+
+func newFileError(file string, err error) *fileError {
+	e := &fileError{err: err, location: Location{File: file}}
+	if m := yamlLineRegex.FindStringSubmatch(err.Error()); len(m) == 2 {
+		e.location.Line, _ = strconv.Atoi(m[1])
+	}
+	return e
+}
+
+func (e *fileError) Error() string {
+	return fmt.Sprintf("wrong syntax for the rule file '%v': %v", e.location.File, e.err.Error())
+}
+
+func (e *fileError) Unwrap() error {
+	return e.err
+}
+
+func (approval *Approval) check() error {
+	if approval.Timeout != "" {
+		d, err := time.ParseDuration(approval.Timeout)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("incorrect approval timeout '%v'", approval.Timeout)
+		}
+	}
+	if approval.DefaultDecision != "" && approval.DefaultDecision != ApproveStr && approval.DefaultDecision != DenyStr {
+		return fmt.Errorf("the approval default decision can be '%v' or '%v' only", ApproveStr, DenyStr)
+	}
+	return nil
+}
+
+func (parallel *Parallel) check() error {
+	if parallel.Timeout != "" {
+		d, err := time.ParseDuration(parallel.Timeout)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("incorrect parallel timeout '%v'", parallel.Timeout)
+		}
+	}
+	return nil
+}
+
+// GetTimeout returns the duration to wait for the actions of the group, 5m by
+// default.
+func (parallel *Parallel) GetTimeout() time.Duration {
+	d, err := time.ParseDuration(parallel.Timeout)
+	if err != nil || d <= 0 {
+		return defaultParallelTimeout
+	}
+	return d
+}
+
+func (escalation *Escalation) check() error {
+	switch escalation.Key {
+	case "", WorkloadStr, NamespaceStr, NodeStr:
+	default:
+		return fmt.Errorf("the escalation key can be '%v', '%v' or '%v' only", WorkloadStr, NamespaceStr, NodeStr)
+	}
+	if escalation.Decay != "" {
+		d, err := time.ParseDuration(escalation.Decay)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("incorrect escalation decay '%v'", escalation.Decay)
+		}
+	}
+	if len(escalation.Steps) == 0 {
+		return errors.New("the escalation has no step")
+	}
+	for n, i := range escalation.Steps {
+		if i.Offense < 1 || n > 0 && i.Offense <= escalation.Steps[n-1].Offense {
+			return fmt.Errorf("the offenses of the escalation steps must be increasing from 1, got %v", i.Offense)
+		}
+	}
+	return nil
+}
+
+// GetKey returns what the offenses are counted by, 'workload' by default.
+func (escalation *Escalation) GetKey() string {
+	if escalation.Key == "" {
+		return WorkloadStr
+	}
+	return escalation.Key
+}
+
+// GetDecay returns the duration after which an offense is forgotten, 1h by
+// default.
+func (escalation *Escalation) GetDecay() time.Duration {
+	d, err := time.ParseDuration(escalation.Decay)
+	if err != nil || d <= 0 {
+		return defaultEscalationDecay
+	}
+	return d
+}
+
+// GetStep returns the step for the number of offenses, the one with the
+// highest offense reached, nil if none is reached.
+func (escalation *Escalation) GetStep(offenses int) *EscalationStep {
+	var step *EscalationStep
+	for _, i := range escalation.Steps {
+		if i.Offense <= offenses {
+			step = i
+		}
+	}
+	return step
+}
+
+// GetTimeout returns the duration to wait for a decision, 1h by default.
+func (approval *Approval) GetTimeout() time.Duration {
+	d, err := time.ParseDuration(approval.Timeout)
+	if err != nil || d <= 0 {
+		return defaultApprovalTimeout
+	}
+	return d
+}
+
+// GetDefaultDecision returns the decision applied at the timeout, 'deny' by
+// default.
+func (approval *Approval) GetDefaultDecision() string {
+	if approval.DefaultDecision == "" {
+		return DenyStr
+	}
+	return approval.DefaultDecision
 }
 
 func (rule *Rule) GetName() string {
@@ -496,6 +983,32 @@ func (rule *Rule) GetActions() []*Action {
 	return rule.Actions
 }
 
+// GetEscalation returns the escalation of the rule, nil if all its actions
+// are run for every event.
+func (rule *Rule) GetEscalation() *Escalation {
+	return rule.Escalation
+}
+
+// GetFinally returns the actions run at the end of the chain.
+func (rule *Rule) GetFinally() []*Action {
+	return rule.Finally
+}
+
+// ListAllActions returns the actions of the rule, each one followed by its
+// 'on_failure' actions, then the 'finally' actions.
+func (rule *Rule) ListAllActions() []*Action {
+	return append(listActions(rule.Actions), listActions(rule.Finally)...)
+}
+
+func listActions(actions []*Action) []*Action {
+	l := make([]*Action, 0, len(actions))
+	for _, i := range actions {
+		l = append(l, i)
+		l = append(l, listActions(i.OnFailure)...)
+	}
+	return l
+}
+
 func (rule *Rule) ListNotifiers() []string {
 	return rule.Notifiers
 }
@@ -528,6 +1041,50 @@ func (action *Action) GetAdditionalContexts() []string {
 	return action.AdditionalContexts
 }
 
+// GetCondition returns the compiled 'when' condition, nil if the action has
+// none.
+func (action *Action) GetCondition() *Condition {
+	return action.condition
+}
+
+// GetDelay returns the duration to wait before running the action and the
+// rest of the chain, 0 if the action isn't delayed.
+func (action *Action) GetDelay() time.Duration {
+	d, err := time.ParseDuration(action.Delay)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
+
+// GetParallel returns the parallel group of the action, nil if the action runs
+// in sequence.
+func (action *Action) GetParallel() *Parallel {
+	return action.group
+}
+
+// GetStep returns the escalation step of the action, nil if the rule has no
+// escalation.
+func (action *Action) GetStep() *EscalationStep {
+	return action.step
+}
+
+// setCondition compiles the 'when' condition, it can reference the previous
+// actions of the rule only.
+func (action *Action) setCondition(previous []*Action) error {
+	c, err := ParseCondition(action.When)
+	if err != nil {
+		return fmt.Errorf("incorrect 'when' condition: %v", err)
+	}
+	for _, i := range c.actions {
+		if !slices.ContainsFunc(previous, func(a *Action) bool { return a.Name == i }) {
+			return fmt.Errorf("incorrect 'when' condition: '%v' is not a previous action of the rule", i)
+		}
+	}
+	action.condition = c
+	return nil
+}
+
 func (action *Action) GetOutput() *Output {
 	if action.Output.Target == "" {
 		return nil
@@ -630,34 +1187,91 @@ func (rule *Rule) compareSource(event *events.Event) bool {
 }
 
 func (rule *Rule) comparePriority(event *events.Event) bool {
+	return rule.comparePriorityNumber(getPriorityNumber(event.Priority))
+}
+
+func (rule *Rule) comparePriorityNumber(priority int) bool {
 	if rule.Match.PriorityNumber == 0 {
 		return true
 	}
 	switch rule.Match.PriorityComparator {
 	case ">":
-		if getPriorityNumber(event.Priority) > rule.Match.PriorityNumber {
+		if priority > rule.Match.PriorityNumber {
 			return true
 		}
 	case ">=":
-		if getPriorityNumber(event.Priority) >= rule.Match.PriorityNumber {
+		if priority >= rule.Match.PriorityNumber {
 			return true
 		}
 	case "<":
-		if getPriorityNumber(event.Priority) < rule.Match.PriorityNumber {
+		if priority < rule.Match.PriorityNumber {
 			return true
 		}
 	case "<=":
-		if getPriorityNumber(event.Priority) <= rule.Match.PriorityNumber {
+		if priority <= rule.Match.PriorityNumber {
 			return true
 		}
 	default:
-		if getPriorityNumber(event.Priority) == rule.Match.PriorityNumber {
+		if priority == rule.Match.PriorityNumber {
 			return true
 		}
 	}
 	return false
 }
 
+// Covers returns true if all the events matched by the other rule are also
+// matched by the rule, ie the match of the rule is broader or equal.
+func (rule *Rule) Covers(other *Rule) bool {
+	if len(rule.Match.Rules) != 0 {
+		if len(other.Match.Rules) == 0 {
+			return false
+		}
+		for _, i := range other.Match.Rules {
+			if !slices.Contains(rule.Match.Rules, i) {
+				return false
+			}
+		}
+	}
+	if rule.Match.Source != "" && rule.Match.Source != other.Match.Source {
+		return false
+	}
+	for i := Default; i <= Emergency; i++ {
+		if other.comparePriorityNumber(i) && !rule.comparePriorityNumber(i) {
+			return false
+		}
+	}
+	if len(rule.Match.TagsC) != 0 {
+		if len(other.Match.TagsC) == 0 {
+			return false
+		}
+		for _, i := range other.Match.TagsC {
+			if !slices.ContainsFunc(rule.Match.TagsC, func(j []string) bool { return isSubset(j, i) }) {
+				return false
+			}
+		}
+	}
+	if len(rule.Match.OutputFieldsC) != 0 {
+		if len(other.Match.OutputFieldsC) == 0 {
+			return false
+		}
+		for _, i := range other.Match.OutputFieldsC {
+			if !slices.ContainsFunc(rule.Match.OutputFieldsC, func(j []outputfield) bool { return isSubset(j, i) }) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isSubset[T comparable](a, b []T) bool {
+	for _, i := range a {
+		if !slices.Contains(b, i) {
+			return false
+		}
+	}
+	return true
+}
+
 func (rule *Rule) AddFalcoTalonContext(event *events.Event, action *Action) {
 	elements := make(map[string]any)
 	elements[falcoTalonContextPrefix+"rule"] = rule.Name
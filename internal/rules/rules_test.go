@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestExtractActionsRulesReportsTheBrokenFileName(t *testing.T) {
@@ -97,3 +98,578 @@ func TestExtractActionsRulesMergesOutputParametersIntoActionsWithoutPanicking(t
 		t.Fatalf("expected 1 rule, got %d", len(*rules))
 	}
 }
+
+func TestRuleCovers(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rulesFile, []byte(`
+- rule: Broad
+  match:
+    rules:
+      - A
+      - B
+    priority: ">=Warning"
+    output_fields:
+      - k8s.ns.name!=kube-system
+  actions:
+    - action: Label
+      actionner: kubernetes:label
+
+- rule: Narrow
+  match:
+    rules:
+      - A
+    priority: Critical
+    tags:
+      - container
+    output_fields:
+      - k8s.ns.name!=kube-system, k8s.pod.name=nginx
+  actions:
+    - action: Label
+      actionner: kubernetes:label
+
+- rule: Other
+  match:
+    rules:
+      - A
+    priority: Notice
+  actions:
+    - action: Label
+      actionner: kubernetes:label
+`), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+
+	rules := ParseRules([]string{rulesFile})
+	if rules == nil {
+		t.Fatal("expected the rules to be valid")
+	}
+	broad, narrow, other := (*rules)[0], (*rules)[1], (*rules)[2]
+
+	if !broad.Covers(narrow) {
+		t.Fatal("expected the broad rule to cover the narrow one")
+	}
+	if narrow.Covers(broad) {
+		t.Fatal("did not expect the narrow rule to cover the broad one")
+	}
+	if broad.Covers(other) {
+		t.Fatal("did not expect the broad rule to cover a rule with a lower priority")
+	}
+}
+
+func TestExtractActionsRulesKeepsTheLocations(t *testing.T) {
+	t.Parallel()
+
+	tmpDir := t.TempDir()
+
+	baseRulesFile := filepath.Join(tmpDir, "base-rules.yaml")
+	if err := os.WriteFile(baseRulesFile, []byte(`- action: Label pod
+  actionner: kubernetes:label
+
+- rule: Shell
+  match:
+    rules:
+      - Terminal shell in container
+  actions:
+    - action: Label pod
+`), 0o600); err != nil {
+		t.Fatalf("write base rules file: %v", err)
+	}
+
+	overrideRulesFile := filepath.Join(tmpDir, "override-rules.yaml")
+	if err := os.WriteFile(overrideRulesFile, []byte(`- rule: Shell
+  actions:
+    - action: Terminate pod
+      actionner: kubernetes:terminate
+`), 0o600); err != nil {
+		t.Fatalf("write override rules file: %v", err)
+	}
+
+	actions, rules, err := extractActionsRules([]string{baseRulesFile, overrideRulesFile})
+	if err != nil {
+		t.Fatalf("extract rules: %v", err)
+	}
+
+	if got, want := (*actions)[0].Location.String(), baseRulesFile+":1:3"; got != want {
+		t.Fatalf("expected action location %q, got %q", want, got)
+	}
+	rule := (*rules)[0]
+	if got, want := rule.Location.String(), baseRulesFile+":4:3"; got != want {
+		t.Fatalf("expected rule location %q, got %q", want, got)
+	}
+	if len(rule.Actions) != 2 {
+		t.Fatalf("expected 2 merged actions, got %d", len(rule.Actions))
+	}
+	if got, want := rule.Actions[0].Location.String(), baseRulesFile+":9:7"; got != want {
+		t.Fatalf("expected first action location %q, got %q", want, got)
+	}
+	if got, want := rule.Actions[1].Location.String(), overrideRulesFile+":3:7"; got != want {
+		t.Fatalf("expected second action location %q, got %q", want, got)
+	}
+}
+
+func TestParseRulesReportsTheLocationOfTheErrors(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rulesFile, []byte(`- rule: Shell
+  match:
+    rules:
+      - Terminal shell in container
+  actions:
+    - action: Terminate pod
+      actionner: kubernetes:terminate
+      ignore_errors: maybe
+`), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+
+	if ParseRules([]string{rulesFile}) != nil {
+		t.Fatal("expected the rules to be invalid")
+	}
+	diagnostics := GetDiagnostics()
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic, got %#v", diagnostics)
+	}
+	if got, want := diagnostics[0].Location, rulesFile+":6:7"; got != want {
+		t.Fatalf("expected location %q, got %q", want, got)
+	}
+
+	if err := os.WriteFile(rulesFile, []byte(`- rule: Shell
+  continue: [
+`), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+
+	if ParseRules([]string{rulesFile}) != nil {
+		t.Fatal("expected the rules to be invalid")
+	}
+	diagnostics = GetDiagnostics()
+	if len(diagnostics) != 1 || !strings.HasPrefix(diagnostics[0].Location, rulesFile+":") {
+		t.Fatalf("expected the location of the syntax error, got %#v", diagnostics)
+	}
+}
+
+func TestParseRulesChecksTheApprovals(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rulesFile, []byte(`- action: Terminate pod
+  actionner: kubernetes:terminate
+  require_approval:
+    timeout: 15m
+    default: approve
+
+- rule: Shell
+  match:
+    rules:
+      - Terminal shell in container
+  actions:
+    - action: Terminate pod
+`), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+
+	r := ParseRules([]string{rulesFile})
+	if r == nil {
+		t.Fatalf("expected the rules to be valid, got %v", GetDiagnostics())
+	}
+	approval := (*r)[0].Actions[0].RequireApproval
+	if approval == nil || approval.GetTimeout() != 15*time.Minute || approval.GetDefaultDecision() != ApproveStr {
+		t.Fatalf("expected the approval of the action to be inherited, got %#v", approval)
+	}
+
+	if err := os.WriteFile(rulesFile, []byte(`- rule: Shell
+  match:
+    rules:
+      - Terminal shell in container
+  actions:
+    - action: Terminate pod
+      actionner: kubernetes:terminate
+      require_approval:
+        timeout: soon
+        default: maybe
+`), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+	if ParseRules([]string{rulesFile}) != nil {
+		t.Fatal("expected the rules to be invalid")
+	}
+
+	if d := (&Approval{}).GetTimeout(); d != time.Hour {
+		t.Fatalf("expected a default timeout of 1h, got %v", d)
+	}
+	if d := (&Approval{}).GetDefaultDecision(); d != DenyStr {
+		t.Fatalf("expected a default decision 'deny', got %v", d)
+	}
+}
+
+func TestParseRulesChecksTheDelays(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rulesFile, []byte(`- action: Terminate pod
+  actionner: kubernetes:terminate
+  delay: 10m
+
+- rule: Shell
+  match:
+    rules:
+      - Terminal shell in container
+  actions:
+    - action: Label pod
+      actionner: kubernetes:label
+    - action: Terminate pod
+`), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+
+	r := ParseRules([]string{rulesFile})
+	if r == nil {
+		t.Fatalf("expected the rules to be valid, got %v", GetDiagnostics())
+	}
+	if d := (*r)[0].Actions[1].GetDelay(); d != 10*time.Minute {
+		t.Fatalf("expected the delay of the action to be inherited, got %v", d)
+	}
+	if d := (*r)[0].Actions[0].GetDelay(); d != 0 {
+		t.Fatalf("expected no delay, got %v", d)
+	}
+
+	for _, i := range []string{
+		`
+  actions:
+    - action: Terminate pod
+      actionner: kubernetes:terminate
+      delay: soon`,
+		`
+  actions:
+    - action: Terminate pod
+      actionner: kubernetes:terminate
+      delay: -1m`,
+		`
+  actions:
+    - parallel:
+        actions:
+          - action: Terminate pod
+            actionner: kubernetes:terminate
+            delay: 10m`,
+		`
+  actions:
+    - action: Label pod
+      actionner: kubernetes:label
+  finally:
+    - action: Terminate pod
+      actionner: kubernetes:terminate
+      delay: 10m`,
+	} {
+		if err := os.WriteFile(rulesFile, []byte(`- rule: Shell
+  match:
+    rules:
+      - Terminal shell in container`+i+"\n"), 0o600); err != nil {
+			t.Fatalf("write rules file: %v", err)
+		}
+		if ParseRules([]string{rulesFile}) != nil {
+			t.Errorf("expected the rules to be invalid:%v", i)
+		}
+	}
+}
+
+func TestParseRulesExpandsTheEscalationSteps(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rulesFile, []byte(`- action: Terminate pod
+  actionner: kubernetes:terminate
+
+- rule: Shell
+  match:
+    rules:
+      - Terminal shell in container
+  escalation:
+    key: namespace
+    decay: 2h
+    steps:
+      - offense: 1
+        actions:
+          - action: Label pod
+            actionner: kubernetes:label
+      - offense: 3
+        actions:
+          - parallel:
+              actions:
+                - action: Label pod
+                  actionner: kubernetes:label
+                - action: Cilium netpol
+                  actionner: cilium:networkpolicy
+          - action: Terminate pod
+            when: action[Label pod].status = success
+`), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+
+	r := LoadRules([]string{rulesFile})
+	if r == nil {
+		t.Fatalf("expected the rules to be valid, got %v", GetDiagnostics())
+	}
+	rule := (*r)[0]
+	e := rule.GetEscalation()
+	if e.GetKey() != NamespaceStr || e.GetDecay() != 2*time.Hour {
+		t.Fatalf("unexpected escalation %#v", e)
+	}
+	actions := rule.GetActions()
+	if len(actions) != 4 || actions[0].GetStep() != e.Steps[0] || actions[1].GetStep() != e.Steps[1] ||
+		actions[1].GetParallel() == nil || actions[3].GetStep() != e.Steps[1] || actions[3].GetActionner() != "kubernetes:terminate" {
+		t.Fatalf("unexpected actions %#v", actions)
+	}
+	if e.Steps[1].Location.File != rulesFile || e.Steps[1].Location.Line != 16 {
+		t.Fatalf("unexpected location of the step %v", e.Steps[1].Location)
+	}
+	for offenses, expected := range map[int]*EscalationStep{0: nil, 1: e.Steps[0], 2: e.Steps[0], 3: e.Steps[1], 10: e.Steps[1]} {
+		if step := e.GetStep(offenses); step != expected {
+			t.Errorf("expected the step %#v for %v offense(s), got %#v", expected, offenses, step)
+		}
+	}
+	if (&Escalation{}).GetKey() != WorkloadStr || (&Escalation{}).GetDecay() != time.Hour {
+		t.Fatal("expected the offenses to be counted by workload for 1h by default")
+	}
+
+	for _, i := range []string{
+		`
+  actions:
+    - action: Label pod
+      actionner: kubernetes:label
+  escalation:
+    steps:
+      - offense: 1
+        actions:
+          - action: Terminate pod
+            actionner: kubernetes:terminate`,
+		`
+  escalation:
+    key: container
+    steps:
+      - offense: 1
+        actions:
+          - action: Terminate pod
+            actionner: kubernetes:terminate`,
+		`
+  escalation:
+    decay: soon
+    steps:
+      - offense: 1
+        actions:
+          - action: Terminate pod
+            actionner: kubernetes:terminate`,
+		`
+  escalation:
+    steps:
+      - offense: 2
+        actions:
+          - action: Label pod
+            actionner: kubernetes:label
+      - offense: 2
+        actions:
+          - action: Terminate pod
+            actionner: kubernetes:terminate`,
+		`
+  escalation:
+    steps:
+      - offense: 1
+        actions: []`,
+		`
+  escalation:
+    steps:
+      - offense: 1
+        actions:
+          - action: Label pod
+            actionner: kubernetes:label
+      - offense: 2
+        actions:
+          - action: Terminate pod
+            actionner: kubernetes:terminate
+            when: action[Label pod].status = success`,
+	} {
+		if err := os.WriteFile(rulesFile, []byte(`- rule: Shell
+  match:
+    rules:
+      - Terminal shell in container`+i+"\n"), 0o600); err != nil {
+			t.Fatalf("write rules file: %v", err)
+		}
+		if ParseRules([]string{rulesFile}) != nil {
+			t.Errorf("expected the rules to be invalid:%v", i)
+		}
+	}
+}
+
+func TestParseRulesExpandsTheParallelGroups(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rulesFile, []byte(`- action: Get logs
+  actionner: kubernetes:log
+
+- rule: Shell
+  match:
+    rules:
+      - Terminal shell in container
+  actions:
+    - parallel:
+        timeout: 30s
+        actions:
+          - action: Get logs
+          - action: Tcpdump
+            actionner: kubernetes:tcpdump
+    - action: Terminate pod
+      actionner: kubernetes:terminate
+      when: action[Tcpdump].status = success
+`), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+
+	r := LoadRules([]string{rulesFile})
+	if r == nil {
+		t.Fatalf("expected the rules to be valid, got %v", GetDiagnostics())
+	}
+	actions := (*r)[0].GetActions()
+	if len(actions) != 3 || actions[0].GetActionner() != "kubernetes:log" {
+		t.Fatalf("expected the group to be replaced by its actions, got %#v", actions)
+	}
+	group := actions[0].GetParallel()
+	if group == nil || actions[1].GetParallel() != group || actions[2].GetParallel() != nil {
+		t.Fatal("expected the actions of the group to keep their group")
+	}
+	if group.GetTimeout() != 30*time.Second || group.Location.String() != rulesFile+":9:7" {
+		t.Fatalf("unexpected group %+v", group)
+	}
+	if d := (&Parallel{}).GetTimeout(); d != 5*time.Minute {
+		t.Fatalf("expected a default timeout of 5m, got %v", d)
+	}
+
+	for _, i := range []string{
+		`
+    - parallel:
+        actions: []`,
+		`
+    - parallel:
+        actions:
+          - parallel:
+              actions:
+                - action: Get logs
+                  actionner: kubernetes:log`,
+		`
+    - parallel:
+        timeout: soon
+        actions:
+          - action: Get logs
+            actionner: kubernetes:log`,
+		`
+    - parallel:
+        actions:
+          - action: Get logs
+            actionner: kubernetes:log
+            require_approval: {}`,
+		`
+    - parallel:
+        actions:
+          - action: Get logs
+            actionner: kubernetes:log
+          - action: Tcpdump
+            actionner: kubernetes:tcpdump
+            when: action[Get logs].status = success`,
+	} {
+		if err := os.WriteFile(rulesFile, []byte(`- rule: Shell
+  match:
+    rules:
+      - Terminal shell in container
+  actions:`+i+"\n"), 0o600); err != nil {
+			t.Fatalf("write rules file: %v", err)
+		}
+		if LoadRules([]string{rulesFile}) != nil {
+			t.Errorf("expected the rules to be invalid for %v", i)
+		}
+	}
+}
+
+func TestParseRulesChecksTheFallbacksAndTheFinallyActions(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	if err := os.WriteFile(rulesFile, []byte(`- action: Terminate pod
+  actionner: kubernetes:terminate
+
+- rule: Shell
+  match:
+    rules:
+      - Terminal shell in container
+  actions:
+    - action: Cilium netpol
+      actionner: cilium:networkpolicy
+      on_failure:
+        - action: Kubernetes netpol
+          actionner: kubernetes:networkpolicy
+          when: previous.error contains forbidden
+          on_failure:
+            - action: Terminate pod
+  finally:
+    - action: Summary
+      actionner: aws:lambda
+      when: action[Terminate pod].status exists
+`), 0o600); err != nil {
+		t.Fatalf("write rules file: %v", err)
+	}
+
+	r := LoadRules([]string{rulesFile})
+	if r == nil {
+		t.Fatalf("expected the rules to be valid, got %v", GetDiagnostics())
+	}
+	rule := (*r)[0]
+	fallback := rule.GetActions()[0].OnFailure[0].OnFailure[0]
+	if fallback.GetActionner() != "kubernetes:terminate" || fallback.Location.File != rulesFile {
+		t.Fatalf("expected the 'on_failure' action to be inherited, got %#v", fallback)
+	}
+	if l := rule.ListAllActions(); len(l) != 4 || l[3] != rule.GetFinally()[0] {
+		t.Fatalf("expected all the actions of the rule, got %#v", l)
+	}
+
+	for _, i := range []string{
+		`
+  actions:
+    - action: Cilium netpol
+      actionner: cilium:networkpolicy
+      on_failure:
+        - action: Terminate pod
+          actionner: kubernetes:terminate
+          require_approval: {}`,
+		`
+  actions:
+    - parallel:
+        actions:
+          - action: Cilium netpol
+            actionner: cilium:networkpolicy
+            on_failure:
+              - action: Terminate pod
+                actionner: kubernetes:terminate`,
+		`
+  actions:
+    - action: Cilium netpol
+      actionner: cilium:networkpolicy
+      on_failure:
+        - parallel:
+            actions:
+              - action: Terminate pod
+                actionner: kubernetes:terminate`,
+		`
+  actions:
+    - action: Cilium netpol
+      actionner: cilium:networkpolicy
+      when: action[Terminate pod].status = failure
+      on_failure:
+        - action: Terminate pod
+          actionner: kubernetes:terminate`,
+		`
+  actions:
+    - action: Cilium netpol
+      actionner: cilium:networkpolicy
+  finally:
+    - action: Terminate pod
+      actionner: kubernetes:terminate
+      require_approval: {}`,
+	} {
+		if err := os.WriteFile(rulesFile, []byte(`- rule: Shell
+  match:
+    rules:
+      - Terminal shell in container`+i+"\n"), 0o600); err != nil {
+			t.Fatalf("write rules file: %v", err)
+		}
+		if LoadRules([]string{rulesFile}) != nil {
+			t.Errorf("expected the rules to be invalid for %v", i)
+		}
+	}
+}
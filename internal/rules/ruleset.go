@@ -0,0 +1,71 @@
+package rules
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync/atomic"
+	"time"
+)
+
+// RuleSet is an immutable snapshot of the loaded rules. A new snapshot is
+// created at each load and swapped atomically, an event is evaluated against a
+// single snapshot from the start to the end, even if a reload happens.
+type RuleSet struct {
+	LoadedAt time.Time
+	Checksum string
+	Rules    []*Rule
+	Version  uint64
+}
+
+var (
+	ruleSet        atomic.Pointer[RuleSet]
+	ruleSetVersion atomic.Uint64
+)
+
+// SetRules creates a new snapshot of the rules with the next version and
+// makes it the current one.
+func SetRules(r *[]*Rule) *RuleSet {
+	rs := &RuleSet{
+		Rules:    *r,
+		Version:  ruleSetVersion.Add(1),
+		Checksum: checksum(*r),
+		LoadedAt: time.Now(),
+	}
+	ruleSet.Store(rs)
+	return rs
+}
+
+// RestoreRuleSet makes a previous snapshot, even nil, the current one again,
+// without a new version. It's for the tests, to undo their SetRules.
+func RestoreRuleSet(rs *RuleSet) {
+	ruleSet.Store(rs)
+}
+
+// GetRuleSet returns the current snapshot of the rules.
+func GetRuleSet() *RuleSet {
+	return ruleSet.Load()
+}
+
+// GetRules returns the rules of the current snapshot.
+func GetRules() *[]*Rule {
+	return &GetRuleSet().Rules
+}
+
+// ShortChecksum returns the first characters of the checksum, enough to
+// identify a revision of the rules in the logs.
+func (rs *RuleSet) ShortChecksum() string {
+	if len(rs.Checksum) < 12 {
+		return rs.Checksum
+	}
+	return rs.Checksum[:12]
+}
+
+func checksum(r []*Rule) string {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return ""
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
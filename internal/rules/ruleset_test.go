@@ -0,0 +1,57 @@
+package rules
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSetRulesCreatesVersionedSnapshots(t *testing.T) {
+	previous := GetRuleSet()
+	t.Cleanup(func() { RestoreRuleSet(previous) })
+
+	first := SetRules(&[]*Rule{{Name: "a"}})
+	same := SetRules(&[]*Rule{{Name: "a"}})
+	other := SetRules(&[]*Rule{{Name: "b"}})
+
+	if same.Version != first.Version+1 || other.Version != same.Version+1 {
+		t.Fatalf("expected increasing versions, got %d, %d and %d", first.Version, same.Version, other.Version)
+	}
+	if first.Checksum == "" || first.Checksum != same.Checksum {
+		t.Fatalf("expected the same checksum for the same rules, got %q and %q", first.Checksum, same.Checksum)
+	}
+	if first.Checksum == other.Checksum {
+		t.Fatal("expected different checksums for different rules")
+	}
+	if GetRuleSet() != other || (*GetRules())[0].Name != "b" {
+		t.Fatal("expected the last snapshot to be the current one")
+	}
+
+	RestoreRuleSet(first)
+	if GetRuleSet() != first {
+		t.Fatal("expected the restored snapshot to be the current one")
+	}
+}
+
+func TestRuleSetSwapIsConcurrencySafe(t *testing.T) {
+	previous := GetRuleSet()
+	t.Cleanup(func() { RestoreRuleSet(previous) })
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			SetRules(&[]*Rule{{Name: "a"}, {Name: "b"}})
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			rs := GetRuleSet()
+			for _, j := range rs.Rules {
+				_ = j.GetName()
+			}
+		}
+	}()
+	wg.Wait()
+}
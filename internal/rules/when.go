@@ -0,0 +1,399 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/falcosecurity/falco-talon/internal/events"
+)
+
+// Condition is a compiled 'when' expression, with the syntax of the Falco
+// conditions:
+//
+//	action[Create netpol].status = failure and not event.output_fields[k8s.ns.name] in (kube-system, falco)
+//	previous.output contains MALICIOUS
+//
+// The fields are:
+//   - previous.status, previous.output, previous.error and
+//     previous.objects[<key>] for the action just before;
+//   - action[<name>].status, .output, .error and .objects[<key>] for a
+//     previous action of the rule, by name;
+//   - event.rule, event.priority, event.source, event.hostname, event.output
+//     and event.output_fields[<key>] for the event.
+//
+// The operators are =, !=, contains, icontains, startswith, endswith, in and
+// exists, combined with and, or, not and the parentheses.
+type Condition struct {
+	root node
+	// actions lists the names of the actions referenced by the condition.
+	actions []string
+}
+
+// conditionEnv is what the fields of a condition are evaluated against.
+type conditionEnv struct {
+	event    *events.Event
+	results  Results
+	previous *ActionResult
+}
+
+type node interface {
+	eval(env *conditionEnv) bool
+}
+
+type andNode struct{ left, right node }
+type orNode struct{ left, right node }
+type notNode struct{ node node }
+type comparisonNode struct {
+	field    field
+	operator string
+	values   []string
+}
+
+func (n andNode) eval(env *conditionEnv) bool { return n.left.eval(env) && n.right.eval(env) }
+func (n orNode) eval(env *conditionEnv) bool  { return n.left.eval(env) || n.right.eval(env) }
+func (n notNode) eval(env *conditionEnv) bool { return !n.node.eval(env) }
+
+func (n comparisonNode) eval(env *conditionEnv) bool {
+	v, ok := n.field.value(env)
+	switch n.operator {
+	case "exists":
+		return ok
+	case "=":
+		return v == n.values[0]
+	case "!=":
+		return v != n.values[0]
+	case "contains":
+		return strings.Contains(v, n.values[0])
+	case "icontains":
+		return strings.Contains(strings.ToLower(v), strings.ToLower(n.values[0]))
+	case "startswith":
+		return strings.HasPrefix(v, n.values[0])
+	case "endswith":
+		return strings.HasSuffix(v, n.values[0])
+	case "in":
+		for _, i := range n.values {
+			if v == i {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// field is a reference to a value of the event or of the result of an action.
+type field struct {
+	// action is the name of the referenced action, empty for previous.
+	action string
+	// source is 'previous', 'action' or 'event'.
+	source string
+	name   string
+	key    string
+}
+
+func (f field) value(env *conditionEnv) (string, bool) {
+	if f.source == "event" {
+		event := env.event
+		switch f.name {
+		case "rule":
+			return event.Rule, event.Rule != ""
+		case "priority":
+			return event.Priority, event.Priority != ""
+		case "source":
+			return event.Source, event.Source != ""
+		case "hostname":
+			return event.Hostname, event.Hostname != ""
+		case "output":
+			return event.Output, event.Output != ""
+		case "output_fields":
+			v, ok := event.OutputFields[f.key]
+			if !ok || v == nil {
+				return "", false
+			}
+			return fmt.Sprintf("%v", v), true
+		}
+		return "", false
+	}
+
+	result := env.previous
+	if f.source == "action" {
+		result = env.results[f.action]
+	}
+	if result == nil {
+		return "", false
+	}
+	switch f.name {
+	case "status":
+		return result.Status, result.Status != ""
+	case "output":
+		return result.Output, result.Output != ""
+	case "error":
+		return result.Error, result.Error != ""
+	case "objects":
+		v, ok := result.Objects[f.key]
+		return v, ok
+	}
+	return "", false
+}
+
+// Evaluate returns true if the condition is true for the event and the
+// results of the previous actions.
+func (c *Condition) Evaluate(event *events.Event, results Results, previous *ActionResult) bool {
+	if c == nil || c.root == nil {
+		return true
+	}
+	return c.root.eval(&conditionEnv{event: event, results: results, previous: previous})
+}
+
+// ParseCondition compiles a 'when' expression.
+func ParseCondition(expression string) (*Condition, error) {
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty condition")
+	}
+	p := &conditionParser{tokens: tokens, condition: new(Condition)}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected '%v'", p.tokens[p.pos])
+	}
+	p.condition.root = root
+	return p.condition, nil
+}
+
+type conditionParser struct {
+	condition *Condition
+	tokens    []string
+	pos       int
+}
+
+func (p *conditionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *conditionParser) next() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", errors.New("unexpected end of the condition")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+func (p *conditionParser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "or" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "and" {
+		p.pos++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *conditionParser) parseNot() (node, error) {
+	if p.peek() == "not" {
+		p.pos++
+		n, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{n}, nil
+	}
+	if p.peek() == "(" {
+		p.pos++
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t, err := p.next(); err != nil || t != ")" {
+			return nil, errors.New("missing ')'")
+		}
+		return n, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *conditionParser) parseComparison() (node, error) {
+	t, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	f, err := p.parseField(t)
+	if err != nil {
+		return nil, err
+	}
+	operator, err := p.next()
+	if err != nil {
+		return nil, fmt.Errorf("missing operator after '%v'", t)
+	}
+	n := comparisonNode{field: f, operator: operator}
+	switch operator {
+	case "exists":
+		return n, nil
+	case "=", "!=", "contains", "icontains", "startswith", "endswith":
+		v, err := p.next()
+		if err != nil || isConditionSymbol(v) {
+			return nil, fmt.Errorf("missing value after '%v'", operator)
+		}
+		n.values = []string{unquote(v)}
+		return n, nil
+	case "in":
+		if t, err := p.next(); err != nil || t != "(" {
+			return nil, errors.New("missing '(' after 'in'")
+		}
+		for {
+			v, err := p.next()
+			if err != nil || isConditionSymbol(v) {
+				return nil, errors.New("missing value in the list")
+			}
+			n.values = append(n.values, unquote(v))
+			t, err := p.next()
+			if err != nil {
+				return nil, errors.New("missing ')'")
+			}
+			if t == ")" {
+				return n, nil
+			}
+			if t != "," {
+				return nil, fmt.Errorf("unexpected '%v' in the list", t)
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown operator '%v'", operator)
+}
+
+func (p *conditionParser) parseField(t string) (field, error) {
+	var f field
+	var rest string
+	switch {
+	case strings.HasPrefix(t, "previous."):
+		f.source = "previous"
+		rest = strings.TrimPrefix(t, "previous.")
+	case strings.HasPrefix(t, "action["):
+		end := strings.Index(t, "].")
+		if end < 0 {
+			return f, fmt.Errorf("unknown field '%v'", t)
+		}
+		f.source = "action"
+		f.action = t[len("action["):end]
+		rest = t[end+2:]
+		p.condition.actions = append(p.condition.actions, f.action)
+	case strings.HasPrefix(t, "event."):
+		f.source = "event"
+		rest = strings.TrimPrefix(t, "event.")
+	default:
+		return f, fmt.Errorf("unknown field '%v'", t)
+	}
+
+	f.name = rest
+	if i := strings.Index(rest, "["); i >= 0 && strings.HasSuffix(rest, "]") {
+		f.name, f.key = rest[:i], rest[i+1:len(rest)-1]
+	}
+
+	valid := map[string][]string{
+		"previous": {"status", "output", "error", "objects"},
+		"action":   {"status", "output", "error", "objects"},
+		"event":    {"rule", "priority", "source", "hostname", "output", "output_fields"},
+	}
+	for _, i := range valid[f.source] {
+		if f.name != i {
+			continue
+		}
+		hasKey := i == "objects" || i == "output_fields"
+		if hasKey != (f.key != "") {
+			break
+		}
+		return f, nil
+	}
+	return f, fmt.Errorf("unknown field '%v'", t)
+}
+
+func isConditionSymbol(t string) bool {
+	return t == "(" || t == ")" || t == "," || t == "=" || t == "!="
+}
+
+func unquote(t string) string {
+	if len(t) >= 2 && (t[0] == '"' || t[0] == '\'') && t[len(t)-1] == t[0] {
+		return t[1 : len(t)-1]
+	}
+	return t
+}
+
+// tokenize splits an expression into the symbols, the operators, the quoted
+// strings and the words. The brackets of the fields can contain spaces.
+func tokenize(expression string) ([]string, error) {
+	var tokens []string
+	r := []rune(expression)
+	for i := 0; i < len(r); {
+		switch c := r[i]; {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(' || c == ')' || c == ',' || c == '=':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '!' && i+1 < len(r) && r[i+1] == '=':
+			tokens = append(tokens, "!=")
+			i += 2
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(r) && r[j] != c {
+				j++
+			}
+			if j == len(r) {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, string(r[i:j+1]))
+			i = j + 1
+		default:
+			j := i
+			depth := 0
+			for j < len(r) {
+				if r[j] == '[' {
+					depth++
+				} else if r[j] == ']' {
+					depth--
+				} else if depth == 0 && (unicode.IsSpace(r[j]) || r[j] == '(' || r[j] == ')' || r[j] == ',' || r[j] == '=' ||
+					r[j] == '!' && j+1 < len(r) && r[j+1] == '=') {
+					break
+				}
+				j++
+			}
+			if depth != 0 {
+				return nil, errors.New("missing ']'")
+			}
+			tokens = append(tokens, string(r[i:j]))
+			i = j
+		}
+	}
+	return tokens, nil
+}
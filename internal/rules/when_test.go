@@ -0,0 +1,80 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/falcosecurity/falco-talon/internal/events"
+)
+
+func TestConditionEvaluate(t *testing.T) {
+	t.Parallel()
+
+	event := &events.Event{
+		Rule:         "Terminal shell in container",
+		Priority:     "Warning",
+		OutputFields: map[string]any{"k8s.ns.name": "default", "fd.sport": 443.0},
+	}
+	results := Results{
+		"Create netpol": {Status: "failure", Error: "forbidden"},
+		"Run script":    {Status: "success", Output: "result: MALICIOUS", Objects: map[string]string{"pod": "nginx"}},
+	}
+	previous := results["Run script"]
+
+	cases := map[string]bool{
+		"action[Create netpol].status = failure":                            true,
+		"action[Create netpol].status=failure":                              true,
+		"action[Create netpol].status != failure":                           false,
+		"previous.output contains MALICIOUS":                                true,
+		"previous.output icontains malicious and previous.status = success": true,
+		"previous.objects[pod] = nginx":                                     true,
+		"previous.objects[node] exists":                                     false,
+		"previous.error exists":                                             false,
+		"action[Run script].output startswith 'result:'":                    true,
+		"event.output_fields[k8s.ns.name] in (kube-system, falco)":          false,
+		"not event.output_fields[k8s.ns.name] in (kube-system, falco)":      true,
+		"event.output_fields[fd.sport] = 443":                               true,
+		"event.rule = \"Terminal shell in container\"":                      true,
+		"event.priority = Critical or (event.priority = Warning)":           true,
+		"event.output_fields[k8s.pod.name] exists":                          false,
+	}
+	for expression, expected := range cases {
+		c, err := ParseCondition(expression)
+		if err != nil {
+			if expected {
+				t.Errorf("'%v': unexpected error %v", expression, err)
+			}
+			continue
+		}
+		if got := c.Evaluate(event, results, previous); got != expected {
+			t.Errorf("'%v': expected %v, got %v", expression, expected, got)
+		}
+	}
+
+	var c *Condition
+	if !c.Evaluate(event, results, previous) {
+		t.Error("expected no condition to be true")
+	}
+}
+
+func TestParseConditionErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, i := range []string{
+		"",
+		"status = failure",
+		"previous.status",
+		"previous.status ==",
+		"previous.status = failure and",
+		"previous.status matches failure",
+		"previous.objects = pod",
+		"event.output_fields exists",
+		"action[Create netpol.status = failure",
+		"event.output_fields[k8s.ns.name] in (default",
+		"(previous.status = failure",
+		"previous.output contains 'MALICIOUS",
+	} {
+		if _, err := ParseCondition(i); err == nil {
+			t.Errorf("'%v': expected an error", i)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package schedules
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/falcosecurity/falco-talon/internal/events"
+	"github.com/falcosecurity/falco-talon/internal/rules"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+const (
+	scheduleStr string = "schedule"
+	// TimerStr is the author of the scheduled actions fired at their time.
+	TimerStr string = "timer"
+)
+
+var ErrNotFound = errors.New("scheduled action not found")
+
+// Schedule is a delayed action waiting for its time to run, with the remaining
+// actions of the chain of its rule.
+type Schedule struct {
+	CreatedAt     time.Time     `json:"created_at"`
+	FiresAt       time.Time     `json:"fires_at"`
+	Event         *events.Event `json:"event"`
+	Results       rules.Results `json:"results,omitempty"`
+	ID            string        `json:"id"`
+	Rule          string        `json:"rule"`
+	Action        string        `json:"action"`
+	Actionner     string        `json:"actionner"`
+	RulesVersion  string        `json:"rules_version,omitempty"`
+	RulesChecksum string        `json:"rules_checksum,omitempty"`
+	ActionIndex   int           `json:"action_index"`
+}
+
+// EndFunc is called once a scheduled action fires or is cancelled, in its own
+// goroutine, status is utils.FiredStr or utils.CancelledStr.
+type EndFunc func(schedule *Schedule, status, by string)
+
+// Store keeps the scheduled actions in a file, to survive the restarts.
+type Store struct {
+	pending map[string]*Schedule
+	timers  map[string]*time.Timer
+	onEnd   EndFunc
+	file    string
+	// wg waits for the scheduled actions being fired or cancelled
+	wg sync.WaitGroup
+	mu sync.Mutex
+}
+
+var store *Store
+
+// Init loads the scheduled actions from the file and starts their timers, the
+// ones which should have fired during a restart fire immediately.
+func Init(file string, onEnd EndFunc) error {
+	s, err := NewStore(file, onEnd)
+	if err != nil {
+		return err
+	}
+	store = s
+	return nil
+}
+
+// GetStore returns the store initialized by Init, nil if the delayed actions
+// are disabled.
+func GetStore() *Store {
+	return store
+}
+
+func NewStore(file string, onEnd EndFunc) (*Store, error) {
+	s := &Store{
+		pending: map[string]*Schedule{},
+		timers:  map[string]*time.Timer{},
+		onEnd:   onEnd,
+		file:    file,
+	}
+
+	var l []*Schedule
+	if err := utils.ReadJSONFile(file, &l); err != nil {
+		return nil, fmt.Errorf("can't read the schedules file '%v': %v", file, err)
+	}
+	for _, i := range l {
+		s.pending[i.ID] = i
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, i := range s.pending {
+		s.schedule(i)
+	}
+	if len(s.pending) != 0 {
+		utils.PrintLog(utils.InfoStr, utils.LogLine{Result: fmt.Sprintf("%v scheduled action(s) restored", len(s.pending)), Message: scheduleStr})
+	}
+
+	return s, nil
+}
+
+// Add schedules a new delayed action, its ID is generated.
+func (s *Store) Add(schedule *Schedule) error {
+	id, err := utils.NewID()
+	if err != nil {
+		return err
+	}
+	schedule.ID = id
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending[id] = schedule
+	if err := s.save(); err != nil {
+		delete(s.pending, id)
+		return err
+	}
+	s.schedule(schedule)
+	return nil
+}
+
+// Cancel removes a scheduled action before it fires, the remaining actions of
+// its chain are never run. The 'finally' actions are run in the background,
+// Cancel doesn't wait for them.
+func (s *Store) Cancel(id, by string) (*Schedule, error) {
+	return s.end(id, utils.CancelledStr, by)
+}
+
+// List returns the scheduled actions, the next to fire first.
+func (s *Store) List() []*Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l := make([]*Schedule, 0, len(s.pending))
+	for _, i := range s.pending {
+		l = append(l, i)
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].FiresAt.Before(l[j].FiresAt) })
+	return l
+}
+
+// Close stops the timers and waits for the scheduled actions being fired or
+// cancelled, the others stay in the file.
+func (s *Store) Close() {
+	s.mu.Lock()
+	for _, i := range s.timers {
+		i.Stop()
+	}
+	s.timers = map[string]*time.Timer{}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Store) end(id, status, by string) (*Schedule, error) {
+	s.mu.Lock()
+	schedule, ok := s.pending[id]
+	if !ok {
+		s.mu.Unlock()
+		return nil, ErrNotFound
+	}
+	delete(s.pending, id)
+	if t := s.timers[id]; t != nil {
+		t.Stop()
+		delete(s.timers, id)
+	}
+	if err := s.save(); err != nil {
+		utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: scheduleStr})
+	}
+	s.mu.Unlock()
+
+	if s.onEnd != nil {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.onEnd(schedule, status, by)
+		}()
+	}
+	return schedule, nil
+}
+
+func (s *Store) schedule(schedule *Schedule) {
+	id := schedule.ID
+	s.timers[id] = time.AfterFunc(time.Until(schedule.FiresAt), func() {
+		_, _ = s.end(id, utils.FiredStr, TimerStr)
+	})
+}
+
+// save writes the scheduled actions in the file.
+func (s *Store) save() error {
+	l := make([]*Schedule, 0, len(s.pending))
+	for _, i := range s.pending {
+		l = append(l, i)
+	}
+	return utils.WriteJSONFile(s.file, l)
+}
@@ -0,0 +1,105 @@
+package schedules
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/falcosecurity/falco-talon/internal/events"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+type end struct {
+	schedule *Schedule
+	status   string
+	by       string
+}
+
+func TestStorePersistsAndCancels(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "schedules", "schedules.json")
+	ends := make(chan end, 10)
+	onEnd := func(s *Schedule, status, by string) { ends <- end{s, status, by} }
+
+	s, err := NewStore(file, onEnd)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	schedule := &Schedule{
+		CreatedAt:   time.Now(),
+		FiresAt:     time.Now().Add(time.Hour),
+		Event:       &events.Event{Output: "shell", TraceID: "trace-id"},
+		Rule:        "rule",
+		Action:      "terminate",
+		ActionIndex: 1,
+	}
+	if err := s.Add(schedule); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if schedule.ID == "" {
+		t.Fatal("expected an ID to be generated")
+	}
+	s.Close()
+
+	// restart
+	s, err = NewStore(file, onEnd)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	defer s.Close()
+	l := s.List()
+	if len(l) != 1 || l[0].ID != schedule.ID || l[0].Event.TraceID != "trace-id" || l[0].ActionIndex != 1 {
+		t.Fatalf("expected the scheduled action to be restored, got %#v", l)
+	}
+
+	if _, err := s.Cancel(schedule.ID, "alice"); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	e := <-ends
+	if e.schedule.ID != schedule.ID || e.status != utils.CancelledStr || e.by != "alice" {
+		t.Fatalf("unexpected end %#v", e)
+	}
+	if _, err := s.Cancel(schedule.ID, "alice"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+
+	// restart
+	s2, err := NewStore(file, onEnd)
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	defer s2.Close()
+	if len(s2.List()) != 0 {
+		t.Fatal("expected no scheduled action after the cancellation")
+	}
+}
+
+func TestStoreFiresTheScheduledActions(t *testing.T) {
+	t.Parallel()
+
+	file := filepath.Join(t.TempDir(), "schedules.json")
+	ends := make(chan end, 10)
+	s, err := NewStore(file, func(s *Schedule, status, by string) { ends <- end{s, status, by} })
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	defer s.Close()
+
+	if err := s.Add(&Schedule{CreatedAt: time.Now(), FiresAt: time.Now().Add(10 * time.Millisecond)}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	select {
+	case e := <-ends:
+		if e.status != utils.FiredStr || e.by != TimerStr {
+			t.Fatalf("unexpected end %#v", e)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the scheduled action to fire")
+	}
+	if len(s.List()) != 0 {
+		t.Fatal("expected no scheduled action after it fired")
+	}
+}
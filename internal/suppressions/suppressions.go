@@ -0,0 +1,379 @@
+package suppressions
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/falcosecurity/falco-talon/internal/escalation"
+	"github.com/falcosecurity/falco-talon/internal/events"
+	k8s "github.com/falcosecurity/falco-talon/internal/kubernetes/client"
+	"github.com/falcosecurity/falco-talon/utils"
+)
+
+const (
+	suppressionStr string = "suppression"
+
+	PodStr      string = "pod"
+	WorkloadStr string = "workload"
+	ImageStr    string = "image"
+	NodeStr     string = "node"
+	FieldStr    string = "field"
+
+	// AnnotationUntil is the annotation of a pod suppressing the responses to
+	// its events until a time, in RFC 3339.
+	AnnotationUntil string = "talon.falco.org/suppress-until"
+	// AnnotationRules is the annotation of a pod limiting the suppression to
+	// some rules, separated by commas.
+	AnnotationRules string = "talon.falco.org/suppress-rules"
+
+	// gcInterval is the interval between two deletions of the expired
+	// suppressions.
+	gcInterval = 1 * time.Minute
+)
+
+var (
+	ErrNotFound = errors.New("suppression not found")
+	Keys        = []string{PodStr, WorkloadStr, ImageStr, NodeStr, FieldStr}
+)
+
+// Suppression disables the responses of a rule, or of all the rules, to the
+// events of a target until it expires.
+type Suppression struct {
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	ID        string    `json:"id"`
+	// Rule is the name of the rule, all the rules if empty.
+	Rule string `json:"rule,omitempty"`
+	// Key is the kind of target: pod (namespace/name), workload
+	// (Kind/namespace/name), image, node or field.
+	Key string `json:"key"`
+	// Field is the output field compared to the value for the key 'field'.
+	Field  string `json:"field,omitempty"`
+	Value  string `json:"value"`
+	Reason string `json:"reason,omitempty"`
+	By     string `json:"by,omitempty"`
+}
+
+type lookup interface {
+	GetPod(pod, namespace string) (*corev1.Pod, error)
+}
+
+// Store keeps the suppressions in a file, to survive the restarts. The
+// expired suppressions are deleted periodically.
+type Store struct {
+	suppressions map[string]*Suppression
+	file         string
+	// annotations enables the suppressions by the annotations of the pods
+	annotations bool
+	done        chan struct{}
+	wg          sync.WaitGroup
+	mu          sync.RWMutex
+}
+
+var (
+	store *Store
+	now   = time.Now
+	// workload and getPod are replaced in the tests
+	workload                                  = escalation.Workload
+	getPod   func(event *events.Event) lookup = func(event *events.Event) lookup {
+		if c := k8s.GetClientFor(event); c != nil {
+			return c
+		}
+		return nil
+	}
+)
+
+// Init loads the suppressions from the file and starts the deletions of the
+// expired ones.
+func Init(file string, annotations bool) error {
+	s, err := NewStore(file, annotations)
+	if err != nil {
+		return err
+	}
+	store = s
+	return nil
+}
+
+// GetStore returns the store initialized by Init, nil if the suppressions
+// are disabled.
+func GetStore() *Store {
+	return store
+}
+
+func NewStore(file string, annotations bool) (*Store, error) {
+	s := &Store{
+		suppressions: map[string]*Suppression{},
+		file:         file,
+		annotations:  annotations,
+		done:         make(chan struct{}),
+	}
+
+	var l []*Suppression
+	if err := utils.ReadJSONFile(file, &l); err != nil {
+		return nil, fmt.Errorf("can't read the suppressions file '%v': %v", file, err)
+	}
+	for _, i := range l {
+		s.suppressions[i.ID] = i
+	}
+	s.GC()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		t := time.NewTicker(gcInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-t.C:
+				s.GC()
+			}
+		}
+	}()
+
+	return s, nil
+}
+
+// Close stops the deletions of the expired suppressions.
+func (s *Store) Close() {
+	close(s.done)
+	s.wg.Wait()
+}
+
+// Add checks and stores a new suppression, its ID is generated.
+func (s *Store) Add(suppression *Suppression) error {
+	if err := suppression.check(); err != nil {
+		return err
+	}
+	id, err := utils.NewID()
+	if err != nil {
+		return err
+	}
+	suppression.ID = id
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.suppressions[id] = suppression
+	if err := s.save(); err != nil {
+		delete(s.suppressions, id)
+		return err
+	}
+	utils.PrintLog(utils.InfoStr, utils.LogLine{Result: fmt.Sprintf("suppression '%v' added until %v", suppression, suppression.ExpiresAt.Format(time.RFC3339)), Rule: suppression.Rule, Message: suppressionStr})
+	return nil
+}
+
+// Delete removes a suppression before it expires.
+func (s *Store) Delete(id string) (*Suppression, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	suppression, ok := s.suppressions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	delete(s.suppressions, id)
+	if err := s.save(); err != nil {
+		s.suppressions[id] = suppression
+		return nil, err
+	}
+	utils.PrintLog(utils.InfoStr, utils.LogLine{Result: fmt.Sprintf("suppression '%v' deleted", suppression), Rule: suppression.Rule, Message: suppressionStr})
+	return suppression, nil
+}
+
+// List returns the suppressions not expired, the next to expire first.
+func (s *Store) List() []*Suppression {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	t := now()
+	l := make([]*Suppression, 0, len(s.suppressions))
+	for _, i := range s.suppressions {
+		if i.ExpiresAt.After(t) {
+			l = append(l, i)
+		}
+	}
+	sort.Slice(l, func(i, j int) bool { return l[i].ExpiresAt.Before(l[j].ExpiresAt) })
+	return l
+}
+
+// GC deletes the expired suppressions.
+func (s *Store) GC() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := now()
+	n := 0
+	for k, v := range s.suppressions {
+		if !v.ExpiresAt.After(t) {
+			delete(s.suppressions, k)
+			n++
+		}
+	}
+	if n == 0 {
+		return
+	}
+	if err := s.save(); err != nil {
+		utils.PrintLog(utils.ErrorStr, utils.LogLine{Error: err.Error(), Message: suppressionStr})
+	}
+}
+
+// Check returns why the responses of the rule to the event are suppressed,
+// empty if they're not.
+func Check(rule string, event *events.Event) string {
+	if store == nil {
+		return ""
+	}
+	return store.Check(rule, event)
+}
+
+// Check returns why the responses of the rule to the event are suppressed,
+// empty if they're not.
+func (s *Store) Check(rule string, event *events.Event) string {
+	s.mu.RLock()
+	var l []*Suppression
+	t := now()
+	for _, i := range s.suppressions {
+		if i.ExpiresAt.After(t) && (i.Rule == "" || i.Rule == rule) {
+			l = append(l, i)
+		}
+	}
+	s.mu.RUnlock()
+
+	// the workload is resolved once and only if it's needed
+	var resolved *string
+	for _, i := range l {
+		var value string
+		switch i.Key {
+		case PodStr:
+			if pod := event.GetPodName(); pod != "" {
+				value = event.GetNamespaceName() + "/" + pod
+			}
+		case WorkloadStr:
+			if resolved == nil {
+				w, err := workload(event)
+				if err != nil {
+					utils.PrintLog(utils.WarningStr, utils.LogLine{Error: fmt.Sprintf("can't resolve the workload of the event, '%v' is used: %v", w, err), Rule: rule, TraceID: event.TraceID, Message: suppressionStr})
+				}
+				resolved = &w
+			}
+			value = *resolved
+		case ImageStr:
+			if i.matchImage(event.GetImage()) {
+				return fmt.Sprintf("suppressed by '%v'", i)
+			}
+			continue
+		case NodeStr:
+			value = event.GetHostname()
+		case FieldStr:
+			if v, ok := event.OutputFields[i.Field]; ok && v != nil {
+				value = fmt.Sprintf("%v", v)
+			}
+		}
+		if value != "" && value == i.Value {
+			return fmt.Sprintf("suppressed by '%v'", i)
+		}
+	}
+
+	if s.annotations {
+		return checkAnnotations(rule, event)
+	}
+	return ""
+}
+
+// checkAnnotations returns why the responses of the rule are suppressed by
+// the annotations of the pod of the event, empty if they're not.
+func checkAnnotations(rule string, event *events.Event) string {
+	pod, namespace := event.GetPodName(), event.GetNamespaceName()
+	if pod == "" {
+		return ""
+	}
+	client := getPod(event)
+	if client == nil {
+		return ""
+	}
+	p, err := client.GetPod(pod, namespace)
+	if err != nil {
+		// the pod may be already deleted, nothing is suppressed
+		return ""
+	}
+	until, ok := p.Annotations[AnnotationUntil]
+	if !ok {
+		return ""
+	}
+	t, err := time.Parse(time.RFC3339, until)
+	if err != nil {
+		utils.PrintLog(utils.WarningStr, utils.LogLine{Error: fmt.Sprintf("wrong value '%v' for the annotation '%v' of the pod '%v/%v'", until, AnnotationUntil, namespace, pod), Rule: rule, TraceID: event.TraceID, Message: suppressionStr})
+		return ""
+	}
+	if !t.After(now()) {
+		return ""
+	}
+	if r := p.Annotations[AnnotationRules]; r != "" {
+		if !slices.ContainsFunc(strings.Split(r, ","), func(s string) bool { return strings.TrimSpace(s) == rule }) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("suppressed by the annotations of the pod '%v/%v' until %v", namespace, pod, until)
+}
+
+func (suppression *Suppression) check() error {
+	if !slices.Contains(Keys, suppression.Key) {
+		return fmt.Errorf("the key must be one of %v", Keys)
+	}
+	if suppression.Value == "" {
+		return errors.New("the value is required")
+	}
+	if suppression.Key == FieldStr && suppression.Field == "" {
+		return errors.New("the field is required for the key 'field'")
+	}
+	if suppression.Key != FieldStr && suppression.Field != "" {
+		return errors.New("the field can be set for the key 'field' only")
+	}
+	if !suppression.ExpiresAt.After(now()) {
+		return errors.New("the expiration must be in the future")
+	}
+	return nil
+}
+
+// matchImage returns true if the image is the value, or if the value is the
+// image without its tag or digest.
+func (suppression *Suppression) matchImage(image string) bool {
+	if image == "" {
+		return false
+	}
+	if image == suppression.Value {
+		return true
+	}
+	repository, _, _ := strings.Cut(image, "@")
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+	return repository == suppression.Value
+}
+
+func (suppression *Suppression) String() string {
+	target := suppression.Key + "=" + suppression.Value
+	if suppression.Key == FieldStr {
+		target = suppression.Field + "=" + suppression.Value
+	}
+	if suppression.Rule != "" {
+		return suppression.Rule + "/" + target
+	}
+	return target
+}
+
+// save writes the suppressions in the file.
+func (s *Store) save() error {
+	l := make([]*Suppression, 0, len(s.suppressions))
+	for _, i := range s.suppressions {
+		l = append(l, i)
+	}
+	return utils.WriteJSONFile(s.file, l)
+}
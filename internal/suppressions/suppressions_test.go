@@ -0,0 +1,110 @@
+package suppressions
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/falcosecurity/falco-talon/internal/events"
+)
+
+type lookupStub map[string]string
+
+func (s lookupStub) GetPod(pod, namespace string) (*corev1.Pod, error) {
+	if pod != "annotated" {
+		return nil, errors.New("not found")
+	}
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: pod, Namespace: namespace, Annotations: s}}, nil
+}
+
+func TestCheckMatchesTheTargets(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return start }
+	previousWorkload, previousGetPod := workload, getPod
+	workload = func(e *events.Event) (string, error) {
+		if e.GetPodName() == "nginx-1" {
+			return "Deployment/default/nginx", nil
+		}
+		return "Pod/" + e.GetNamespaceName() + "/" + e.GetPodName(), nil
+	}
+	getPod = func(*events.Event) lookup {
+		return lookupStub{AnnotationUntil: start.Add(time.Hour).Format(time.RFC3339), AnnotationRules: "rule, other"}
+	}
+	t.Cleanup(func() {
+		now, workload, getPod = time.Now, previousWorkload, previousGetPod
+	})
+
+	file := filepath.Join(t.TempDir(), "suppressions.json")
+	s, err := NewStore(file, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(s.Close)
+
+	for _, i := range []*Suppression{
+		{Rule: "rule", Key: PodStr, Value: "default/pod"},
+		{Key: WorkloadStr, Value: "Deployment/default/nginx"},
+		{Rule: "rule", Key: ImageStr, Value: "docker.io/library/nginx"},
+		{Rule: "rule", Key: NodeStr, Value: "node1", ExpiresAt: start.Add(time.Minute)},
+		{Rule: "rule", Key: FieldStr, Field: "proc.name", Value: "bash"},
+	} {
+		if i.ExpiresAt.IsZero() {
+			i.ExpiresAt = start.Add(time.Hour)
+		}
+		if err := s.Add(i); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := s.Add(&Suppression{Key: FieldStr, Value: "bash", ExpiresAt: start.Add(time.Hour)}); err == nil {
+		t.Fatalf("expected an error for a field without name")
+	}
+
+	fields := func(kv ...string) map[string]any {
+		m := map[string]any{}
+		for i := 0; i < len(kv); i += 2 {
+			m[kv[i]] = kv[i+1]
+		}
+		return m
+	}
+	tests := map[string]struct {
+		rule       string
+		event      *events.Event
+		suppressed bool
+	}{
+		"pod":                       {rule: "rule", event: &events.Event{OutputFields: fields("k8s.ns.name", "default", "k8s.pod.name", "pod")}, suppressed: true},
+		"pod of other rule":         {rule: "other", event: &events.Event{OutputFields: fields("k8s.ns.name", "default", "k8s.pod.name", "pod")}},
+		"workload all rules":        {rule: "other", event: &events.Event{OutputFields: fields("k8s.ns.name", "default", "k8s.pod.name", "nginx-1")}, suppressed: true},
+		"image with tag":            {rule: "rule", event: &events.Event{OutputFields: fields("container.image.repository", "docker.io/library/nginx", "container.image.tag", "1.27")}, suppressed: true},
+		"other image":               {rule: "rule", event: &events.Event{OutputFields: fields("container.image", "docker.io/library/nginx-debug:1.27")}},
+		"node":                      {rule: "rule", event: &events.Event{Hostname: "node1"}, suppressed: true},
+		"field":                     {rule: "rule", event: &events.Event{OutputFields: fields("proc.name", "bash")}, suppressed: true},
+		"annotations":               {rule: "other", event: &events.Event{OutputFields: fields("k8s.ns.name", "prod", "k8s.pod.name", "annotated")}, suppressed: true},
+		"annotations of other rule": {rule: "third", event: &events.Event{OutputFields: fields("k8s.ns.name", "prod", "k8s.pod.name", "annotated")}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if reason := s.Check(tt.rule, tt.event); (reason != "") != tt.suppressed {
+				t.Fatalf("expected suppressed=%v, got '%v'", tt.suppressed, reason)
+			}
+		})
+	}
+
+	// the expired suppressions are ignored then deleted
+	now = func() time.Time { return start.Add(2 * time.Minute) }
+	if reason := s.Check("rule", &events.Event{Hostname: "node1"}); reason != "" {
+		t.Fatalf("expected the expired suppression to be ignored, got '%v'", reason)
+	}
+	s.GC()
+	reloaded, err := NewStore(file, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(reloaded.Close)
+	if l := reloaded.List(); len(l) != 4 {
+		t.Fatalf("expected 4 suppressions left, got %v", len(l))
+	}
+}
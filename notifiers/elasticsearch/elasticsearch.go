@@ -50,33 +50,32 @@ func Register() *Notifier {
 	return new(Notifier)
 }
 
-func (n Notifier) Init(fields map[string]any) error {
-	parameters = new(Parameters)
-	parameters = utils.SetFields(parameters, fields).(*Parameters)
-	if err := checkParameters(parameters); err != nil {
-		return err
+func (n Notifier) Init(fields map[string]any) (func(), error) {
+	p := utils.SetFields(new(Parameters), fields).(*Parameters)
+	if err := checkParameters(p); err != nil {
+		return nil, err
 	}
-	if parameters.CreateIndexTemplate {
-		client := http.NewClient("GET", "", "", parameters.CustomHeaders)
-		if parameters.User != "" && parameters.Password != "" {
-			client.SetBasicAuth(parameters.User, parameters.Password)
+	if p.CreateIndexTemplate {
+		client := http.NewClient("GET", "", "", p.CustomHeaders)
+		if p.User != "" && p.Password != "" {
+			client.SetBasicAuth(p.User, p.Password)
 		}
-		if err := client.Request(parameters.URL+indexTemplate, nil); err != nil {
+		if err := client.Request(p.URL+indexTemplate, nil); err != nil {
 			if err.Error() == "resource not found" {
 				client.SetHTTPMethod("PUT")
-				m := strings.ReplaceAll(mapping, "${SHARDS}", fmt.Sprintf("%v", parameters.NumberOfShards))
-				m = strings.ReplaceAll(m, "${REPLICAS}", fmt.Sprintf("%v", parameters.NumberOfReplicas))
+				m := strings.ReplaceAll(mapping, "${SHARDS}", fmt.Sprintf("%v", p.NumberOfShards))
+				m = strings.ReplaceAll(m, "${REPLICAS}", fmt.Sprintf("%v", p.NumberOfReplicas))
 				j := make(map[string]any)
 				if err := json.Unmarshal([]byte(m), &j); err != nil {
-					return err
+					return nil, err
 				}
-				if err := client.Request(parameters.URL+indexTemplate, j); err != nil {
-					return err
+				if err := client.Request(p.URL+indexTemplate, j); err != nil {
+					return nil, err
 				}
 			}
 		}
 	}
-	return nil
+	return func() { parameters = p }, nil
 }
 
 func (n Notifier) Information() models.Information {
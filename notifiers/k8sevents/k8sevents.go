@@ -52,6 +52,12 @@ Action: {{ .Action }}
 {{- if .Actionner }}
 Actionner: {{ .Actionner }}
 {{- end }}
+{{- if .Mode }}
+Mode: {{ .Mode }}
+{{- end }}
+{{- if .Cluster }}
+Cluster: {{ .Cluster }}
+{{- end }}
 {{- if .Event }}
 Event: {{ .Event }}
 {{- end }}
@@ -79,7 +85,7 @@ func Register() *Notifier {
 	return new(Notifier)
 }
 
-func (n Notifier) Init(_ map[string]any) error { return nil }
+func (n Notifier) Init(_ map[string]any) (func(), error) { return func() {}, nil }
 
 func (n Notifier) Information() models.Information {
 	return models.Information{
@@ -111,7 +117,11 @@ func (n Notifier) Run(log utils.LogLine) error {
 		message = message[:1024]
 	}
 
-	client := kubernetes.GetClient()
+	// the event is created in the cluster of the action
+	client := kubernetes.GetClusterClient(log.Cluster)
+	if client == nil {
+		return fmt.Errorf("no credentials for the cluster '%v'", log.Cluster)
+	}
 
 	namespace := log.Objects["Namespace"]
 	ns, err := client.GetNamespace(namespace)
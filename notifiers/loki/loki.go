@@ -52,13 +52,12 @@ func Register() *Notifier {
 	return new(Notifier)
 }
 
-func (n Notifier) Init(fields map[string]any) error {
-	parameters = new(Parameters)
-	parameters = utils.SetFields(parameters, fields).(*Parameters)
-	if err := checkParameters(parameters); err != nil {
-		return err
+func (n Notifier) Init(fields map[string]any) (func(), error) {
+	p := utils.SetFields(new(Parameters), fields).(*Parameters)
+	if err := checkParameters(p); err != nil {
+		return nil, err
 	}
-	return nil
+	return func() { parameters = p }, nil
 }
 
 func (n Notifier) Information() models.Information {
@@ -124,6 +123,12 @@ func NewPayload(log utils.LogLine) Payload {
 	if log.Actionner != "" {
 		s["actionner"] = log.Actionner
 	}
+	if log.Mode != "" {
+		s["mode"] = log.Mode
+	}
+	if log.Cluster != "" {
+		s["cluster"] = log.Cluster
+	}
 	if log.OutputTarget != "" {
 		s["outputtarget"] = log.OutputTarget
 	}
@@ -13,3 +13,23 @@ func TestExampleUsesURLSetting(t *testing.T) {
 		t.Fatalf("expected loki example to stop using host_port setting, got:\n%s", Example)
 	}
 }
+
+func TestInitAppliesTheSettingsOnlyOnceApplied(t *testing.T) {
+	parameters = &Parameters{URL: "http://loki:3100"}
+	t.Cleanup(func() { parameters = nil })
+
+	if _, err := new(Notifier).Init(map[string]any{"url": ""}); err == nil {
+		t.Fatal("expected an error without url")
+	}
+	apply, err := new(Notifier).Init(map[string]any{"url": "http://other:3100"})
+	if err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if parameters.URL != "http://loki:3100" {
+		t.Fatalf("expected the settings in use to be kept before the apply, got %v", parameters.URL)
+	}
+	apply()
+	if parameters.URL != "http://other:3100" {
+		t.Fatalf("expected the new settings once applied, got %v", parameters.URL)
+	}
+}
@@ -2,7 +2,10 @@ package notifiers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
+	"sync/atomic"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
@@ -26,7 +29,9 @@ import (
 )
 
 type Notifier interface {
-	Init(fields map[string]any) error
+	// Init checks the settings of the notifier, without changing the ones in
+	// use, and returns the function applying them.
+	Init(fields map[string]any) (apply func(), err error)
 	Run(log utils.LogLine) error
 	Information() models.Information
 	Parameters() models.Parameters
@@ -35,12 +40,12 @@ type Notifier interface {
 type Notifiers []Notifier
 
 var defaultNotifiers *Notifiers
-var enabledNotifiers *Notifiers
+var enabledNotifiers atomic.Pointer[Notifiers]
 
 func init() {
 	defaultNotifiers = new(Notifiers)
 	defaultNotifiers = ListDefaultNotifiers()
-	enabledNotifiers = new(Notifiers)
+	enabledNotifiers.Store(new(Notifiers))
 }
 
 func ListDefaultNotifiers() *Notifiers {
@@ -64,7 +69,7 @@ func (notifiers *Notifiers) Add(notifier ...Notifier) {
 }
 
 func GetNotifiers() *Notifiers {
-	return enabledNotifiers
+	return enabledNotifiers.Load()
 }
 
 func (notifiers *Notifiers) FindNotifier(name string) Notifier {
@@ -80,32 +85,64 @@ func (notifiers *Notifiers) FindNotifier(name string) Notifier {
 	return nil
 }
 
-func Init() {
-	config := configuration.GetConfiguration()
+// Registry is the notifiers enabled for a configuration and its rules, they're
+// in use once published.
+type Registry struct {
+	notifiers *Notifiers
+	// settings apply the settings of the notifiers
+	settings []func()
+}
+
+// Init initializes the notifiers used by the current configuration and rules.
+// A notifier failing to init is skipped, the errors are returned together.
+func Init() error {
+	r, err := NewRegistry(configuration.GetConfiguration(), rules.GetRules())
+	r.Publish()
+	return err
+}
 
+// NewRegistry initializes the notifiers used by the configuration and the
+// rules, without publishing them. The settings of the notifiers are applied
+// only by the publication. A notifier failing to init is skipped, the errors
+// are returned together with the registry.
+func NewRegistry(config *configuration.Configuration, ruleList *[]*rules.Rule) (*Registry, error) {
 	specifiedNotifiers := map[string]bool{}
 
 	for _, i := range config.ListDefaultNotifiers() {
 		specifiedNotifiers[i] = true
 	}
-	rules := rules.GetRules()
-	for _, i := range *rules {
+	for _, i := range *ruleList {
 		for _, j := range i.ListNotifiers() {
 			specifiedNotifiers[j] = true
 		}
 	}
 
+	var errs []error
+	r := &Registry{notifiers: new(Notifiers)}
 	for i := range specifiedNotifiers {
 		for _, j := range *defaultNotifiers {
 			if strings.ToLower(i) == j.Information().Name {
-				if err := j.Init(config.Notifiers[i]); err != nil {
+				apply, err := j.Init(config.Notifiers[i])
+				if err != nil {
 					utils.PrintLog(utils.ErrorStr, utils.LogLine{Message: "init", Error: err.Error(), Category: j.Information().Name, Status: utils.FailureStr})
+					errs = append(errs, fmt.Errorf("%v: %v", j.Information().Name, err))
 					continue
 				}
-				enabledNotifiers.Add(j)
+				r.notifiers.Add(j)
+				r.settings = append(r.settings, apply)
 			}
 		}
 	}
+	return r, errors.Join(errs...)
+}
+
+// Publish applies the settings of the notifiers of the registry and makes them
+// the enabled ones.
+func (r *Registry) Publish() {
+	for _, i := range r.settings {
+		i()
+	}
+	enabledNotifiers.Store(r.notifiers)
 }
 
 func Notify(actx context.Context, rule *rules.Rule, action *rules.Action, event *events.Event, log utils.LogLine) {
@@ -28,6 +28,7 @@ const (
 	Red            string = "#e20b0b"
 	Green          string = "#23ba47"
 	Grey           string = "#a4a8b1"
+	Orange         string = "#f5a623"
 	threeBackticks        = "```"
 	ignoredStr     string = "ignored"
 )
@@ -71,13 +72,12 @@ func Register() *Notifier {
 	return new(Notifier)
 }
 
-func (n Notifier) Init(fields map[string]any) error {
-	parameters = new(Parameters)
-	parameters = utils.SetFields(parameters, fields).(*Parameters)
-	if err := checkParameters(parameters); err != nil {
-		return err
+func (n Notifier) Init(fields map[string]any) (func(), error) {
+	p := utils.SetFields(new(Parameters), fields).(*Parameters)
+	if err := checkParameters(p); err != nil {
+		return nil, err
 	}
-	return nil
+	return func() { parameters = p }, nil
 }
 
 func (n Notifier) Information() models.Information {
@@ -133,8 +133,10 @@ func newPayload(log utils.LogLine) Payload {
 		color = Red
 	case utils.SuccessStr:
 		color = Green
-	case ignoredStr:
+	case ignoredStr, utils.PlannedStr, utils.ObservedStr:
 		color = Grey
+	case utils.VetoedStr, utils.ThrottledStr:
+		color = Orange
 	}
 	attachment.Color = color
 
@@ -180,6 +182,18 @@ func newPayload(log utils.LogLine) Payload {
 		field.Value = "`" + log.Status + "`"
 		field.Short = true
 		fields = append(fields, field)
+		if log.Mode != "" {
+			field.Title = "Mode"
+			field.Value = "`" + log.Mode + "`"
+			field.Short = true
+			fields = append(fields, field)
+		}
+		if log.Cluster != "" {
+			field.Title = "Cluster"
+			field.Value = "`" + log.Cluster + "`"
+			field.Short = true
+			fields = append(fields, field)
+		}
 		if len(log.Objects) > 0 {
 			for i, j := range log.Objects {
 				field.Title = i
@@ -7,6 +7,12 @@ Action: {{ .Action }}
 {{- if .Actionner }}
 Actionner: {{ .Actionner }}
 {{- end }}
+{{- if .Mode }}
+Mode: {{ .Mode }}
+{{- end }}
+{{- if .Cluster }}
+Cluster: {{ .Cluster }}
+{{- end }}
 {{- if .Rule }}
 Rule: {{ .Rule }}
 {{- end }}
@@ -39,6 +45,8 @@ var htmlTmpl = `
 {{ if eq $prio utils.SuccessStr }}{{ $color = "#23ba47" }}{{ end }}
 {{ if eq $prio utils.FailureStr }}{{ $color = "#e20b0b" }}{{ end }}
 {{ if eq $prio "ignored" }}{{ $color = "#a4a8b1" }}{{ end }}
+{{ if eq $prio "vetoed" }}{{ $color = "#f5a623" }}{{ end }}
+{{ if eq $prio "throttled" }}{{ $color = "#f5a623" }}{{ end }}
 
 <meta http-equiv="Content-Type" content="text/html; charset=us-ascii">
 <style type="text/css">
@@ -75,6 +83,18 @@ var htmlTmpl = `
             <td style="background-color:#d1d6da">{{ .Actionner }}</td>
         </tr>
         {{ end }}
+        {{ if .Mode }}
+        <tr>
+            <td style="background-color:#858585"><span style="font-size:14px;color:#fff;"><strong>Mode</strong></span></td>
+            <td style="background-color:#d1d6da">{{ .Mode }}</td>
+        </tr>
+        {{ end }}
+        {{ if .Cluster }}
+        <tr>
+            <td style="background-color:#858585"><span style="font-size:14px;color:#fff;"><strong>Cluster</strong></span></td>
+            <td style="background-color:#d1d6da">{{ .Cluster }}</td>
+        </tr>
+        {{ end }}
         {{ if .Rule }}
         <tr>
             <td style="background-color:#858585"><span style="font-size:14px;color:#fff;"><strong>Rule</strong></span></td>
@@ -10,6 +10,7 @@ import (
 	awssdk "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 
+	"github.com/falcosecurity/falco-talon/configuration"
 	aws "github.com/falcosecurity/falco-talon/internal/aws/client"
 	"github.com/falcosecurity/falco-talon/internal/models"
 	"github.com/falcosecurity/falco-talon/internal/rules"
@@ -66,7 +67,9 @@ func Register() *Output {
 	return new(Output)
 }
 
-func (o Output) Init() error { return aws.Init() }
+func (o Output) Init(config *configuration.Configuration) error {
+	return aws.Init(config.AwsConfig)
+}
 
 func (o Output) Information() models.Information {
 	return models.Information{
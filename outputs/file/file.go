@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/falcosecurity/falco-talon/configuration"
 	"github.com/falcosecurity/falco-talon/internal/models"
 	"github.com/falcosecurity/falco-talon/internal/rules"
 	"github.com/falcosecurity/falco-talon/outputs/helpers"
@@ -38,7 +39,7 @@ func Register() *Output {
 	return new(Output)
 }
 
-func (o Output) Init() error { return nil }
+func (o Output) Init(_ *configuration.Configuration) error { return nil }
 
 func (o Output) Information() models.Information {
 	return models.Information{
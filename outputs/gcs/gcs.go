@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/falcosecurity/falco-talon/configuration"
 	"github.com/falcosecurity/falco-talon/internal/gcp/client"
 	"github.com/falcosecurity/falco-talon/internal/models"
 	"github.com/falcosecurity/falco-talon/internal/rules"
@@ -46,8 +47,8 @@ func Register() *Output {
 	return new(Output)
 }
 
-func (o Output) Init() error {
-	return client.Init()
+func (o Output) Init(config *configuration.Configuration) error {
+	return client.Init(config.GcpConfig)
 }
 
 func (o Output) Information() models.Information {
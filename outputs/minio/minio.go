@@ -9,6 +9,7 @@ import (
 
 	miniosdk "github.com/minio/minio-go/v7"
 
+	"github.com/falcosecurity/falco-talon/configuration"
 	minio "github.com/falcosecurity/falco-talon/internal/minio/client"
 	"github.com/falcosecurity/falco-talon/internal/models"
 	"github.com/falcosecurity/falco-talon/internal/rules"
@@ -49,7 +50,9 @@ func Register() *Output {
 	return new(Output)
 }
 
-func (o Output) Init() error { return minio.Init() }
+func (o Output) Init(config *configuration.Configuration) error {
+	return minio.Init(config.MinioConfig)
+}
 
 func (o Output) Information() models.Information {
 	return models.Information{
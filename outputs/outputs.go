@@ -1,6 +1,9 @@
 package outputs
 
 import (
+	"sync/atomic"
+
+	"github.com/falcosecurity/falco-talon/configuration"
 	"github.com/falcosecurity/falco-talon/internal/rules"
 	awss3 "github.com/falcosecurity/falco-talon/outputs/aws/s3"
 	"github.com/falcosecurity/falco-talon/outputs/file"
@@ -12,7 +15,7 @@ import (
 )
 
 type Output interface {
-	Init() error
+	Init(config *configuration.Configuration) error
 	Run(output *rules.Output, data *models.Data) (utils.LogLine, error)
 	CheckParameters(action *rules.Output) error
 	Checks(output *rules.Output) error
@@ -23,12 +26,12 @@ type Output interface {
 type Outputs []Output
 
 var defaultOutputs *Outputs
-var enabledOutputs *Outputs
+var enabledOutputs atomic.Pointer[Outputs]
 
 func init() {
 	defaultOutputs = new(Outputs)
 	defaultOutputs = ListDefaultOutputs()
-	enabledOutputs = new(Outputs)
+	enabledOutputs.Store(new(Outputs))
 }
 
 func ListDefaultOutputs() *Outputs {
@@ -51,7 +54,7 @@ func (outputs *Outputs) Add(output ...Output) {
 }
 
 func GetOutputs() *Outputs {
-	return enabledOutputs
+	return enabledOutputs.Load()
 }
 
 func (outputs *Outputs) FindOutput(fullname string) Output {
@@ -70,15 +73,31 @@ func (outputs *Outputs) FindOutput(fullname string) Output {
 	return nil
 }
 
+// Registry is the outputs enabled for a configuration and its rules, they're
+// in use once published.
+type Registry struct {
+	outputs *Outputs
+}
+
+// Init initializes the outputs used by the current configuration and rules.
 func Init() error {
-	rules := rules.GetRules()
+	r, err := NewRegistry(configuration.GetConfiguration(), rules.GetRules())
+	if err != nil {
+		return err
+	}
+	r.Publish()
+	return nil
+}
 
+// NewRegistry initializes the outputs used by the rules, with the
+// configuration, without publishing them.
+func NewRegistry(config *configuration.Configuration, ruleList *[]*rules.Rule) (*Registry, error) {
 	categories := map[string]bool{}
 	enabledCategories := map[string]bool{}
 
 	// list actionner categories to init
-	for _, i := range *rules {
-		for _, j := range i.Actions {
+	for _, i := range *ruleList {
+		for _, j := range i.ListAllActions() {
 			if j.GetOutput() != nil {
 				if o := ListDefaultOutputs().FindOutput(j.GetOutput().Target); o != nil {
 					categories[o.Information().Category] = true
@@ -90,22 +109,27 @@ func Init() error {
 	for category := range categories {
 		for _, output := range *defaultOutputs {
 			if category == output.Information().Category {
-				if err := output.Init(); err != nil {
+				if err := output.Init(config); err != nil {
 					utils.PrintLog(utils.ErrorStr, utils.LogLine{Message: "init", Error: err.Error(), Category: category, Status: utils.FailureStr})
-					return err
+					return nil, err
 				}
 				enabledCategories[category] = true
 			}
 		}
 	}
 
+	enabled := new(Outputs)
 	for i := range enabledCategories {
 		for _, j := range *defaultOutputs {
 			if i == j.Information().Category {
-				enabledOutputs.Add(j)
+				enabled.Add(j)
 			}
 		}
 	}
+	return &Registry{outputs: enabled}, nil
+}
 
-	return nil
+// Publish makes the outputs of the registry the enabled ones.
+func (r *Registry) Publish() {
+	enabledOutputs.Store(r.outputs)
 }
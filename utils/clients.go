@@ -0,0 +1,54 @@
+package utils
+
+import "sync"
+
+// Clients keeps the clients built for the configurations of a service. The
+// client of the current configuration is the one in use, the client of a new
+// configuration is built without changing it, and it's used only once its
+// configuration is the current one, e.g. once a reload is published.
+type Clients[C comparable, T any] struct {
+	clients map[C]*T
+	mu      sync.Mutex
+	// initMu serializes the inits, without blocking the clients in use
+	initMu sync.Mutex
+}
+
+// Init builds the client of the configuration if it doesn't exist yet. The
+// clients of the other configurations than this one and the current one are
+// forgotten.
+func (c *Clients[C, T]) Init(config, current C, build func(C) (*T, error)) error {
+	c.initMu.Lock()
+	defer c.initMu.Unlock()
+
+	c.mu.Lock()
+	for i := range c.clients {
+		if i != config && i != current {
+			delete(c.clients, i)
+		}
+	}
+	exists := c.clients[config] != nil
+	c.mu.Unlock()
+	if exists {
+		return nil
+	}
+
+	client, err := build(config)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.clients == nil {
+		c.clients = map[C]*T{}
+	}
+	c.clients[config] = client
+	return nil
+}
+
+// Get returns the client of the current configuration, nil if it's not built.
+func (c *Clients[C, T]) Get(current C) *T {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.clients[current]
+}
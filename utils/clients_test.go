@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestClientsSwitchWithTheCurrentConfiguration(t *testing.T) {
+	var c Clients[string, string]
+	build := func(config string) (*string, error) {
+		if config == "wrong" {
+			return nil, errors.New("wrong configuration")
+		}
+		s := "client of " + config
+		return &s, nil
+	}
+
+	if err := c.Init("old", "old", build); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if err := c.Init("new", "old", build); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	if got := c.Get("old"); got == nil || *got != "client of old" {
+		t.Fatalf("expected the client of the current configuration to be kept, got %v", got)
+	}
+	if got := c.Get("new"); got == nil || *got != "client of new" {
+		t.Fatalf("expected the client of the new configuration once current, got %v", got)
+	}
+
+	if err := c.Init("wrong", "new", build); err == nil {
+		t.Fatal("expected an error for a wrong configuration")
+	}
+	if c.Get("old") != nil || c.Get("new") == nil {
+		t.Fatal("expected only the clients of the current and the new configurations to be kept")
+	}
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// ReadJSONFile decodes the JSON file into v, a missing or empty file leaves v
+// unchanged.
+func ReadJSONFile(file string, v any) error {
+	b, err := os.ReadFile(file) // #nosec G304 -- the file path comes from the operator configuration
+	if os.IsNotExist(err) || (err == nil && len(b) == 0) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// WriteJSONFile writes v as JSON in a temporary file renamed after, to never
+// leave a truncated file. The directory of the file is created if needed.
+func WriteJSONFile(file string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+// NewID returns a random ID, of 32 hexadecimal characters.
+func NewID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteAndReadJSONFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "store", "items.json")
+
+	var l []string
+	if err := ReadJSONFile(file, &l); err != nil || l != nil {
+		t.Fatalf("expected a missing file to be ignored, got %v (%v)", l, err)
+	}
+	if err := WriteJSONFile(file, []string{"a", "b"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := os.Stat(file + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("expected the temporary file to be renamed, got %v", err)
+	}
+	if err := ReadJSONFile(file, &l); err != nil || len(l) != 2 || l[1] != "b" {
+		t.Fatalf("expected the items written, got %v (%v)", l, err)
+	}
+
+	if err := os.WriteFile(file, []byte("{"), 0o600); err != nil {
+		t.Fatalf("write a truncated file: %v", err)
+	}
+	if err := ReadJSONFile(file, &l); err == nil {
+		t.Fatal("expected an error for a truncated file")
+	}
+}
+
+func TestNewID(t *testing.T) {
+	a, err := NewID()
+	if err != nil {
+		t.Fatalf("new id: %v", err)
+	}
+	b, _ := NewID()
+	if len(a) != 32 || a == b {
+		t.Fatalf("expected two random IDs of 32 characters, got %q and %q", a, b)
+	}
+}
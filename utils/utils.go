@@ -3,6 +3,7 @@ package utils
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"reflect"
@@ -41,6 +42,17 @@ const (
 	InProgressStr string = "in_progress"
 	SuccessStr    string = "success"
 	FailureStr    string = "failure"
+	PendingStr    string = "pending"
+	ApprovedStr   string = "approved"
+	DeniedStr     string = "denied"
+	VetoedStr     string = "vetoed"
+	ThrottledStr  string = "throttled"
+	SkippedStr    string = "skipped"
+	ScheduledStr  string = "scheduled"
+	FiredStr      string = "fired"
+	CancelledStr  string = "cancelled"
+	PlannedStr    string = "planned"
+	ObservedStr   string = "observed"
 
 	ansiChars string = "[\u001B\u009B][[\\]()#;?]*(?:(?:(?:[a-zA-Z\\d]*(?:;[a-zA-Z\\d]*)*)?\u0007)|(?:(?:\\d{1,4}(?:;\\d{0,4})*)?[\\dA-PRZcf-ntqry=><~]))"
 
@@ -51,31 +63,37 @@ const (
 )
 
 type LogLine struct {
-	Time         string            `json:"time,omitempty"`
-	Objects      map[string]string `json:"objects,omitempty"`
-	TraceID      string            `json:"trace_id,omitempty"`
-	Rule         string            `json:"rule,omitempty"`
-	Event        string            `json:"event,omitempty"`
-	Message      string            `json:"message,omitempty"`
-	Priority     string            `json:"priority,omitempty"`
-	Source       string            `json:"source,omitempty"`
-	Result       string            `json:"result,omitempty"`
-	Notifier     string            `json:"notifier,omitempty"`
-	Context      string            `json:"context,omitempty"`
-	Output       string            `json:"output,omitempty"`
-	Category     string            `json:"category,omitempty"`
-	OutputName   string            `json:"output_name,omitempty"`
-	OutputTarget string            `json:"output_target,omitempty"`
-	Actionner    string            `json:"actionner,omitempty"`
-	Action       string            `json:"action,omitempty"`
-	Error        string            `json:"error,omitempty"`
-	Status       string            `json:"status,omitempty"`
-	Stage        string            `json:"stage,omitempty"`
+	Time          string            `json:"time,omitempty"`
+	Objects       map[string]string `json:"objects,omitempty"`
+	TraceID       string            `json:"trace_id,omitempty"`
+	Rule          string            `json:"rule,omitempty"`
+	Event         string            `json:"event,omitempty"`
+	Message       string            `json:"message,omitempty"`
+	Priority      string            `json:"priority,omitempty"`
+	Source        string            `json:"source,omitempty"`
+	Result        string            `json:"result,omitempty"`
+	Notifier      string            `json:"notifier,omitempty"`
+	Context       string            `json:"context,omitempty"`
+	Output        string            `json:"output,omitempty"`
+	Category      string            `json:"category,omitempty"`
+	OutputName    string            `json:"output_name,omitempty"`
+	OutputTarget  string            `json:"output_target,omitempty"`
+	Actionner     string            `json:"actionner,omitempty"`
+	Action        string            `json:"action,omitempty"`
+	Error         string            `json:"error,omitempty"`
+	Status        string            `json:"status,omitempty"`
+	Mode          string            `json:"mode,omitempty"`
+	Cluster       string            `json:"cluster,omitempty"`
+	Stage         string            `json:"stage,omitempty"`
+	Location      string            `json:"location,omitempty"`
+	RulesVersion  string            `json:"rules_version,omitempty"`
+	RulesChecksum string            `json:"rules_checksum,omitempty"`
 }
 
 var validate *validator.Validate
 var localIP *string
 var logFormat *string
+var logOutput io.Writer = os.Stdout
 
 func init() {
 	logFormat = new(string)
@@ -89,12 +107,17 @@ func SetLogFormat(format string) {
 	}
 }
 
+// SetLogOutput changes the destination of the logs, stdout by default.
+func SetLogOutput(w io.Writer) {
+	logOutput = w
+}
+
 func PrintLog(level string, line LogLine) {
 	var output zerolog.ConsoleWriter
 
 	var log zerolog.Logger
 	if *logFormat == textStr || *logFormat == colorStr {
-		output = zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+		output = zerolog.ConsoleWriter{Out: logOutput, TimeFormat: time.RFC3339}
 		if *logFormat != colorStr {
 			output.NoColor = true
 		}
@@ -103,7 +126,7 @@ func PrintLog(level string, line LogLine) {
 		}
 		log = zerolog.New(output).With().Timestamp().Logger()
 	} else {
-		log = zerolog.New(os.Stdout).With().Timestamp().Logger()
+		log = zerolog.New(logOutput).With().Timestamp().Logger()
 	}
 
 	var l *zerolog.Event
@@ -156,12 +179,27 @@ func PrintLog(level string, line LogLine) {
 	if line.Status != "" {
 		l.Str("status", line.Status)
 	}
+	if line.Mode != "" {
+		l.Str("mode", line.Mode)
+	}
+	if line.Cluster != "" {
+		l.Str("cluster", line.Cluster)
+	}
 	if line.Result != "" {
 		l.Str("result", line.Result)
 	}
 	if line.TraceID != "" {
 		l.Str("trace_id", line.TraceID)
 	}
+	if line.Location != "" {
+		l.Str("location", line.Location)
+	}
+	if line.RulesVersion != "" {
+		l.Str("rules_version", line.RulesVersion)
+	}
+	if line.RulesChecksum != "" {
+		l.Str("rules_checksum", line.RulesChecksum)
+	}
 	if len(line.Objects) > 0 {
 		for i, j := range line.Objects {
 			l.Str(strings.ToLower(i), j)